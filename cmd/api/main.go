@@ -1,24 +1,83 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/logging"
 	"github.com/SkNuwanTissera/gymshark/internal/packer"
 	"github.com/SkNuwanTissera/gymshark/internal/server"
 	"golang.org/x/exp/slog"
 )
 
-const restAPIPort = "8080"
-
 func main() {
-	bootstrap()
-}
+	cfg, printConfig, err := config.Load(os.Args[0], os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(2)
+	}
+
+	if printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
 
-func bootstrap() {
-	newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
-	newPackerSrvc := packer.NewPacketsService()
+	logLevel := new(slog.LevelVar)
+	level, _ := cfg.Level()
+	logLevel.Set(level)
+	slog.SetDefault(slog.New(logging.NewContextHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))))
 
-	newServer := server.NewServer(newSizerSrvc, newPackerSrvc)
-	err := newServer.Serve(restAPIPort)
+	err = bootstrap(cfg, logLevel)
 	if err != nil {
 		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func bootstrap(cfg config.Config, logLevel *slog.LevelVar) error {
+	catalogs, err := newCatalogService(cfg.CatalogFile)
+	if err != nil {
+		return err
+	}
+	newSizerSrvc, err := catalogs.GetCatalog(packer.DefaultCatalogID)
+	if err != nil {
+		return err
+	}
+	newPackerSrvc := packer.NewPacketsService(catalogs, packer.NewProfileService())
+
+	newServer := server.NewServer(cfg, newSizerSrvc, newPackerSrvc)
+	newServer.LogLevel = logLevel
+	newServer.LoadConfig = func() (config.Config, error) {
+		cfg, _, err := config.Load(os.Args[0], os.Args[1:], os.LookupEnv)
+		return cfg, err
+	}
+	return newServer.Serve()
+}
+
+// newCatalogService seeds the catalogs from the file, falling back to the
+// built-in sizes only when no file is given.
+func newCatalogService(catalogFile string) (*packer.CatalogService, error) {
+	if catalogFile == "" {
+		return packer.NewCatalogService(packer.NewSizerService(packer.SortedSizes)), nil
+	}
+
+	seed, err := packer.LoadSeed(catalogFile)
+	if err != nil {
+		return nil, err
 	}
+	slog.Info("seeded catalogs from file",
+		slog.String("file", catalogFile),
+		slog.Int("catalogs", len(seed)),
+	)
+
+	return packer.NewSeededCatalogService(context.Background(), seed)
 }
@@ -10,10 +10,12 @@ require (
 	golang.org/x/time v0.5.0
 )
 
-require golang.org/x/exp v0.0.0-20231214170342-aacd6d4b4611
+require (
+	golang.org/x/exp v0.0.0-20231214170342-aacd6d4b4611
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
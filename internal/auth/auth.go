@@ -0,0 +1,139 @@
+// Package auth holds the credentials and permissions of the API callers.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"golang.org/x/exp/slices"
+)
+
+// Scopes a caller may be granted. Admin grants every other scope.
+const (
+	ScopeSizesRead        = "sizes:read"
+	ScopeSizesWrite       = "sizes:write"
+	ScopePacketsCalculate = "packets:calculate"
+	ScopeAdmin            = "admin"
+)
+
+// Scopes lists every known scope.
+var Scopes = []string{ScopeSizesRead, ScopeSizesWrite, ScopePacketsCalculate, ScopeAdmin}
+
+// hashRX matches hex encoded SHA-256 digests.
+var hashRX = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// APIKey is a stored API key. Only the SHA-256 hash of the key is kept.
+type APIKey struct {
+	ID     string   `yaml:"id"`
+	Hash   string   `yaml:"hash"`
+	Scopes []string `yaml:"scopes"`
+}
+
+// HashKey returns the hex encoded SHA-256 hash of the key as stored in APIKey.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidateKeys returns every problem of the keys joined.
+func ValidateKeys(keys []APIKey) error {
+	var errs []error
+	ids := make(map[string]bool)
+	hashes := make(map[string]bool)
+	for i, key := range keys {
+		prefix := fmt.Sprintf("auth.keys[%d]", i)
+		switch {
+		case key.ID == "":
+			errs = append(errs, fmt.Errorf("%s: id must be provided", prefix))
+		case ids[key.ID]:
+			errs = append(errs, fmt.Errorf("%s: id %s is duplicated", prefix, key.ID))
+		}
+		switch {
+		case !hashRX.MatchString(key.Hash):
+			errs = append(errs, fmt.Errorf("%s: hash must be a hex encoded sha256 digest", prefix))
+		case hashes[key.Hash]:
+			errs = append(errs, fmt.Errorf("%s: hash is duplicated", prefix))
+		}
+		if len(key.Scopes) == 0 {
+			errs = append(errs, fmt.Errorf("%s: scopes must hold at least one scope", prefix))
+		}
+		for _, scope := range key.Scopes {
+			if !slices.Contains(Scopes, scope) {
+				errs = append(errs, fmt.Errorf("%s: unknown scope %q", prefix, scope))
+			}
+		}
+		ids[key.ID], hashes[key.Hash] = true, true
+	}
+
+	return errors.Join(errs...)
+}
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// ID identifies the caller, such as the ID of its API key.
+	ID string `json:"id"`
+	// Method is how the caller authenticated.
+	Method string   `json:"method"`
+	Scopes []string `json:"scopes"`
+}
+
+// Authentication methods of a principal.
+const (
+	MethodAPIKey = "api_key"
+)
+
+// Subject returns the ID of the principal qualified by its method, such as
+// api_key:erp, as the subjects of policy bindings name it.
+func (p Principal) Subject() string {
+	return p.Method + ":" + p.ID
+}
+
+// HasScope reports whether the principal is granted the scope.
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, ScopeAdmin) || slices.Contains(p.Scopes, scope)
+}
+
+// KeyStore looks API keys up by their hash.
+type KeyStore struct {
+	byHash map[string]APIKey
+}
+
+// NewKeyStore is a constructor of the KeyStore holding the keys.
+func NewKeyStore(keys []APIKey) *KeyStore {
+	store := &KeyStore{byHash: make(map[string]APIKey, len(keys))}
+	for _, key := range keys {
+		store.byHash[key.Hash] = key
+	}
+	return store
+}
+
+// Authenticate returns the principal of the presented key, if it is known.
+func (store *KeyStore) Authenticate(key string) (Principal, bool) {
+	stored, found := store.byHash[HashKey(key)]
+	if !found {
+		return Principal{}, false
+	}
+
+	return Principal{
+		ID:     stored.ID,
+		Method: MethodAPIKey,
+		Scopes: slices.Clone(stored.Scopes),
+	}, true
+}
+
+type contextKey struct{}
+
+// WithPrincipal returns a copy of the context carrying the principal.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// PrincipalFrom returns the principal carried by the context, if any.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyStore_Authenticate(t *testing.T) {
+	store := NewKeyStore([]APIKey{
+		{ID: "reader", Hash: HashKey("read-secret"), Scopes: []string{ScopeSizesRead}},
+		{ID: "ops", Hash: HashKey("ops-secret"), Scopes: []string{ScopeAdmin}},
+	})
+
+	reader, ok := store.Authenticate("read-secret")
+	require.True(t, ok)
+	require.Equal(t, "reader", reader.ID)
+	require.Equal(t, MethodAPIKey, reader.Method)
+	require.True(t, reader.HasScope(ScopeSizesRead))
+	require.False(t, reader.HasScope(ScopeSizesWrite))
+
+	ops, ok := store.Authenticate("ops-secret")
+	require.True(t, ok)
+	require.True(t, ops.HasScope(ScopePacketsCalculate), "admin grants every scope")
+
+	_, ok = store.Authenticate("guess")
+	require.False(t, ok)
+
+	ctx := WithPrincipal(context.Background(), reader)
+	got, ok := PrincipalFrom(ctx)
+	require.True(t, ok)
+	require.Equal(t, reader, got)
+	_, ok = PrincipalFrom(context.Background())
+	require.False(t, ok)
+}
+
+func TestValidateKeys(t *testing.T) {
+	valid := APIKey{ID: "reader", Hash: HashKey("secret"), Scopes: []string{ScopeSizesRead}}
+	require.NoError(t, ValidateKeys([]APIKey{valid}))
+
+	err := ValidateKeys([]APIKey{
+		valid,
+		{ID: "reader", Hash: HashKey("secret"), Scopes: []string{"sizes:delete"}},
+		{Hash: "plain-text-secret"},
+	})
+	require.Error(t, err)
+	for _, want := range []string{
+		"auth.keys[1]: id reader is duplicated",
+		"auth.keys[1]: hash is duplicated",
+		`auth.keys[1]: unknown scope "sizes:delete"`,
+		"auth.keys[2]: id must be provided",
+		"auth.keys[2]: hash must be a hex encoded sha256 digest",
+		"auth.keys[2]: scopes must hold at least one scope",
+	} {
+		require.ErrorContains(t, err, want)
+	}
+}
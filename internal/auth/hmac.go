@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// MethodHMAC is the authentication method of signed request principals.
+const MethodHMAC = "hmac"
+
+// minSecretBytes is the shortest signing secret accepted.
+const minSecretBytes = 32
+
+// replayPruneInterval is how often the replay cache drops the signatures whose
+// timestamps left the window.
+const replayPruneInterval = time.Minute
+
+// ERR consts ...
+const (
+	ErrorSignatureClient    = "signing client is not known"
+	ErrorSignatureTimestamp = "signature timestamp must be unix seconds"
+	ErrorSignatureReplay    = "signature timestamp is outside the replay window"
+	ErrorSignatureMismatch  = "signature does not match the request"
+	ErrorSignatureReused    = "signature was already used"
+)
+
+// HMACClient is a machine client signing its requests with a shared secret.
+type HMACClient struct {
+	ID     string   `yaml:"id"`
+	Secret string   `yaml:"secret"`
+	Scopes []string `yaml:"scopes"`
+}
+
+// ValidateHMACClients returns every problem of the clients joined.
+func ValidateHMACClients(clients []HMACClient) error {
+	var errs []error
+	ids := make(map[string]bool)
+	for i, client := range clients {
+		prefix := fmt.Sprintf("auth.hmac.clients[%d]", i)
+		switch {
+		case client.ID == "":
+			errs = append(errs, fmt.Errorf("%s: id must be provided", prefix))
+		case ids[client.ID]:
+			errs = append(errs, fmt.Errorf("%s: id %s is duplicated", prefix, client.ID))
+		}
+		if len(client.Secret) < minSecretBytes {
+			errs = append(errs, fmt.Errorf("%s: secret must be at least %d bytes long", prefix, minSecretBytes))
+		}
+		if len(client.Scopes) == 0 {
+			errs = append(errs, fmt.Errorf("%s: scopes must hold at least one scope", prefix))
+		}
+		for _, scope := range client.Scopes {
+			if !slices.Contains(Scopes, scope) {
+				errs = append(errs, fmt.Errorf("%s: unknown scope %q", prefix, scope))
+			}
+		}
+		ids[client.ID] = true
+	}
+
+	return errors.Join(errs...)
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of a request: its
+// method, path with the query, unix timestamp and the SHA-256 digest of its
+// body, one per line.
+func Sign(secret, method, path, timestamp string, body []byte) string {
+	digest := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + path + "\n" + timestamp + "\n" + hex.EncodeToString(digest[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ReplayCache remembers the signatures accepted within the replay window so
+// that a captured request cannot be sent again. It outlives the verifiers of
+// reloaded configurations.
+type ReplayCache struct {
+	mu     sync.Mutex
+	seen   map[string]time.Time
+	pruned time.Time
+}
+
+// NewReplayCache is a constructor of the ReplayCache.
+func NewReplayCache() *ReplayCache {
+	return &ReplayCache{seen: make(map[string]time.Time)}
+}
+
+// remember records the key until the time and reports whether it was not
+// already recorded.
+func (c *ReplayCache) remember(key string, until, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.pruned) >= replayPruneInterval {
+		for seenKey, seenUntil := range c.seen {
+			if now.After(seenUntil) {
+				delete(c.seen, seenKey)
+			}
+		}
+		c.pruned = now
+	}
+
+	if seenUntil, found := c.seen[key]; found && !now.After(seenUntil) {
+		return false
+	}
+	c.seen[key] = until
+	return true
+}
+
+// SignatureVerifier verifies signed requests against the secrets of the
+// clients.
+type SignatureVerifier struct {
+	clients map[string]HMACClient
+	window  time.Duration
+	seen    *ReplayCache
+	now     func() time.Time
+}
+
+// NewSignatureVerifier is a constructor of the SignatureVerifier accepting
+// timestamps up to the window away from the current time, each signature
+// once as recorded in the replay cache.
+func NewSignatureVerifier(clients []HMACClient, window time.Duration, seen *ReplayCache) *SignatureVerifier {
+	v := &SignatureVerifier{
+		clients: make(map[string]HMACClient, len(clients)),
+		window:  window,
+		seen:    seen,
+		now:     time.Now,
+	}
+	for _, client := range clients {
+		v.clients[client.ID] = client
+	}
+	return v
+}
+
+// Verify returns the principal of the client when the signature matches the
+// request, its timestamp falls within the replay window and it was not
+// accepted before.
+func (v *SignatureVerifier) Verify(clientID, timestamp, signature, method, path string, body []byte) (Principal, error) {
+	client, found := v.clients[clientID]
+	if !found {
+		return Principal{}, errors.New(ErrorSignatureClient)
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return Principal{}, errors.New(ErrorSignatureTimestamp)
+	}
+	now, signedAt := v.now(), time.Unix(seconds, 0)
+	skew := now.Sub(signedAt)
+	if skew > v.window || skew < -v.window {
+		return Principal{}, errors.New(ErrorSignatureReplay)
+	}
+
+	want := Sign(client.Secret, method, path, timestamp, body)
+	if !hmac.Equal([]byte(want), []byte(signature)) {
+		return Principal{}, errors.New(ErrorSignatureMismatch)
+	}
+	if !v.seen.remember(clientID+"\n"+timestamp+"\n"+signature, signedAt.Add(v.window), now) {
+		return Principal{}, errors.New(ErrorSignatureReused)
+	}
+
+	return Principal{
+		ID:     client.ID,
+		Method: MethodHMAC,
+		Scopes: slices.Clone(client.Scopes),
+	}, nil
+}
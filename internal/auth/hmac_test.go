@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureVerifier_Verify(t *testing.T) {
+	const secret = "0123456789abcdef0123456789abcdef"
+	now := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	verifier := NewSignatureVerifier([]HMACClient{{ID: "erp", Secret: secret, Scopes: []string{ScopePacketsCalculate}}}, 5*time.Minute, NewReplayCache())
+	verifier.now = func() time.Time { return now }
+
+	body := []byte(`{"items": 250}`)
+	timestamp := func(at time.Time) string { return strconv.FormatInt(at.Unix(), 10) }
+	signature := Sign(secret, "POST", "/api/v1/packets?explain=true", timestamp(now), body)
+
+	testCases := []struct {
+		name      string
+		client    string
+		timestamp string
+		signature string
+		method    string
+		path      string
+		body      []byte
+		wantErr   string
+	}{
+		{name: "valid", client: "erp", timestamp: timestamp(now), signature: signature, method: "POST", path: "/api/v1/packets?explain=true", body: body},
+		{name: "reused", client: "erp", timestamp: timestamp(now), signature: signature, method: "POST", path: "/api/v1/packets?explain=true", body: body, wantErr: ErrorSignatureReused},
+		{name: "valid within the window", client: "erp", timestamp: timestamp(now.Add(-4 * time.Minute)), signature: Sign(secret, "POST", "/api/v1/packets", timestamp(now.Add(-4*time.Minute)), body), method: "POST", path: "/api/v1/packets", body: body},
+		{name: "unknown client", client: "crm", timestamp: timestamp(now), signature: signature, method: "POST", path: "/api/v1/packets?explain=true", body: body, wantErr: ErrorSignatureClient},
+		{name: "malformed timestamp", client: "erp", timestamp: now.Format(time.RFC3339), signature: signature, method: "POST", path: "/api/v1/packets?explain=true", body: body, wantErr: ErrorSignatureTimestamp},
+		{name: "replayed", client: "erp", timestamp: timestamp(now.Add(-6 * time.Minute)), signature: Sign(secret, "POST", "/api/v1/packets", timestamp(now.Add(-6*time.Minute)), body), method: "POST", path: "/api/v1/packets", body: body, wantErr: ErrorSignatureReplay},
+		{name: "from the future", client: "erp", timestamp: timestamp(now.Add(6 * time.Minute)), signature: Sign(secret, "POST", "/api/v1/packets", timestamp(now.Add(6*time.Minute)), body), method: "POST", path: "/api/v1/packets", body: body, wantErr: ErrorSignatureReplay},
+		{name: "other method", client: "erp", timestamp: timestamp(now), signature: signature, method: "PUT", path: "/api/v1/packets?explain=true", body: body, wantErr: ErrorSignatureMismatch},
+		{name: "other path", client: "erp", timestamp: timestamp(now), signature: signature, method: "POST", path: "/api/v1/packets", body: body, wantErr: ErrorSignatureMismatch},
+		{name: "other body", client: "erp", timestamp: timestamp(now), signature: signature, method: "POST", path: "/api/v1/packets?explain=true", body: []byte(`{"items": 251}`), wantErr: ErrorSignatureMismatch},
+		{name: "other timestamp", client: "erp", timestamp: timestamp(now.Add(time.Second)), signature: signature, method: "POST", path: "/api/v1/packets?explain=true", body: body, wantErr: ErrorSignatureMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			principal, err := verifier.Verify(tc.client, tc.timestamp, tc.signature, tc.method, tc.path, tc.body)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, Principal{ID: "erp", Method: MethodHMAC, Scopes: []string{ScopePacketsCalculate}}, principal)
+		})
+	}
+}
+
+func TestValidateHMACClients(t *testing.T) {
+	err := ValidateHMACClients([]HMACClient{
+		{ID: "erp", Secret: "0123456789abcdef0123456789abcdef", Scopes: []string{ScopePacketsCalculate}},
+		{ID: "erp", Secret: "short", Scopes: []string{"packets:write"}},
+		{Secret: "0123456789abcdef0123456789abcdef"},
+	})
+	require.EqualError(t, err, `auth.hmac.clients[1]: id erp is duplicated
+auth.hmac.clients[1]: secret must be at least 32 bytes long
+auth.hmac.clients[1]: unknown scope "packets:write"
+auth.hmac.clients[2]: id must be provided
+auth.hmac.clients[2]: scopes must hold at least one scope`)
+}
+
+func TestReplayCache_Remember(t *testing.T) {
+	cache := NewReplayCache()
+	now := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	require.True(t, cache.remember("erp", now.Add(time.Minute), now))
+	require.False(t, cache.remember("erp", now.Add(time.Minute), now.Add(30*time.Second)))
+	require.True(t, cache.remember("crm", now.Add(time.Minute), now))
+
+	later := now.Add(2 * time.Minute)
+	require.True(t, cache.remember("erp", later.Add(time.Minute), later), "expired signatures are forgotten")
+	require.Len(t, cache.seen, 1, "expired signatures are pruned")
+}
@@ -0,0 +1,415 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// MethodJWT is the authentication method of bearer token principals.
+const MethodJWT = "jwt"
+
+// minRSABits is the smallest RSA modulus accepted in a JWKS.
+const minRSABits = 2048
+
+// jwksFetchTimeout bounds the download of a JWKS from a URL.
+const jwksFetchTimeout = 10 * time.Second
+
+// maxJWKSBytes caps the size of a JWKS.
+const maxJWKSBytes = 1 << 20
+
+// ERR consts ...
+const (
+	ErrorTokenMalformed   = "token is malformed"
+	ErrorTokenAlgorithm   = "token algorithm is not supported"
+	ErrorTokenKeyNotFound = "token signing key is not in the key set"
+	ErrorTokenSignature   = "token signature is invalid"
+	ErrorTokenExpired     = "token is expired"
+	ErrorTokenNotYetValid = "token is not valid yet"
+	ErrorTokenIssuer      = "token issuer is not accepted"
+	ErrorTokenAudience    = "token audience is not accepted"
+	ErrorTokenSubject     = "token has no subject"
+)
+
+// algorithms maps the supported JWS algorithms to their hash and key type.
+var algorithms = map[string]struct {
+	hash  crypto.Hash
+	kty   string
+	curve elliptic.Curve
+}{
+	"RS256": {hash: crypto.SHA256, kty: "RSA"},
+	"RS384": {hash: crypto.SHA384, kty: "RSA"},
+	"RS512": {hash: crypto.SHA512, kty: "RSA"},
+	"ES256": {hash: crypto.SHA256, kty: "EC", curve: elliptic.P256()},
+	"ES384": {hash: crypto.SHA384, kty: "EC", curve: elliptic.P384()},
+	"ES512": {hash: crypto.SHA512, kty: "EC", curve: elliptic.P521()},
+}
+
+// jwk is a single key of a JWKS.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// verificationKey is a parsed public key of a JWKS.
+type verificationKey struct {
+	kid string
+	alg string
+	key crypto.PublicKey
+}
+
+// KeySet holds the public keys tokens are verified with.
+type KeySet struct {
+	keys []verificationKey
+}
+
+// ParseJWKS parses a JSON Web Key Set. Encryption keys and key types other
+// than RSA and EC are skipped; a set without any usable key is an error.
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+
+	set := &KeySet{}
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch k.Kty {
+		case "RSA":
+			key, err = k.rsaKey()
+		case "EC":
+			key, err = k.ecKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jwks: keys[%d]: %w", i, err)
+		}
+		set.keys = append(set.keys, verificationKey{kid: k.Kid, alg: k.Alg, key: key})
+	}
+	if len(set.keys) == 0 {
+		return nil, errors.New("jwks: no RSA or EC signing keys")
+	}
+
+	return set, nil
+}
+
+func (k jwk) rsaKey() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("n: %w", err)
+	}
+	e, err := decodeBigInt(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("e: %w", err)
+	}
+	if n.BitLen() < minRSABits {
+		return nil, fmt.Errorf("rsa modulus must be at least %d bits", minRSABits)
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("rsa exponent is out of range")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k jwk) ecKey() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("x: %w", err)
+	}
+	y, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("y: %w", err)
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on the curve")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) == 0 {
+		return nil, errors.New("must be a base64url encoded integer")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+// LoadJWKS reads a JWKS from a file path or an http(s) URL.
+func LoadJWKS(ctx context.Context, source string) (*KeySet, error) {
+	if !strings.HasPrefix(source, "https://") && !strings.HasPrefix(source, "http://") {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		return ParseJWKS(data)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks: %s responded %s", source, res.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxJWKSBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJWKS(data)
+}
+
+// find returns the key that may verify a token signed with the algorithm and
+// key ID. Tokens without a key ID may only be verified by a set of one key.
+func (set *KeySet) find(alg, kid string) (crypto.PublicKey, bool) {
+	if kid == "" {
+		if len(set.keys) != 1 {
+			return nil, false
+		}
+		return set.keys[0].key, set.keys[0].alg == "" || set.keys[0].alg == alg
+	}
+	for _, k := range set.keys {
+		if k.kid == kid && (k.alg == "" || k.alg == alg) {
+			return k.key, true
+		}
+	}
+	return nil, false
+}
+
+// claims are the registered and scope claims of a token.
+type claims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+	// Scope is the space separated scope claim of OAuth 2.0 access tokens.
+	Scope string `json:"scope"`
+	// Scp is the list form of the scope claim used by some issuers.
+	Scp []string `json:"scp"`
+}
+
+// audience accepts both the string and the list form of the aud claim.
+type audience []string
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (a *audience) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		var single string
+		if err := json.Unmarshal(data, &single); err != nil {
+			return err
+		}
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// scopes returns the values of both forms of the scope claim.
+func (c claims) scopes() []string {
+	return append(strings.Fields(c.Scope), c.Scp...)
+}
+
+// Verifier validates bearer tokens and maps their scopes to the API scopes.
+type Verifier struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+	leeway   time.Duration
+	// scopeMap maps token scopes to API scopes; without it token scopes that
+	// are API scopes are taken as they are.
+	scopeMap map[string][]string
+	now      func() time.Time
+}
+
+// NewVerifier is a constructor of the Verifier accepting tokens of the issuer
+// for the audience, signed by a key of the set. The leeway absorbs clock skew.
+func NewVerifier(keys *KeySet, issuer, audience string, leeway time.Duration, scopeMap map[string][]string) *Verifier {
+	return &Verifier{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		leeway:   leeway,
+		scopeMap: scopeMap,
+		now:      time.Now,
+	}
+}
+
+// Verify checks the signature and claims of the compact serialized token and
+// returns its principal.
+func (v *Verifier) Verify(token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, errors.New(ErrorTokenMalformed)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Principal{}, errors.New(ErrorTokenMalformed)
+	}
+	alg, supported := algorithms[header.Alg]
+	if !supported {
+		return Principal{}, errors.New(ErrorTokenAlgorithm)
+	}
+	key, found := v.keys.find(header.Alg, header.Kid)
+	if !found {
+		return Principal{}, errors.New(ErrorTokenKeyNotFound)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, errors.New(ErrorTokenMalformed)
+	}
+
+	h := alg.hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	digest := h.Sum(nil)
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if alg.kty != "RSA" || rsa.VerifyPKCS1v15(pub, alg.hash, digest, signature) != nil {
+			return Principal{}, errors.New(ErrorTokenSignature)
+		}
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if alg.kty != "EC" || pub.Curve != alg.curve || len(signature) != 2*size {
+			return Principal{}, errors.New(ErrorTokenSignature)
+		}
+		r, s := new(big.Int).SetBytes(signature[:size]), new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return Principal{}, errors.New(ErrorTokenSignature)
+		}
+	default:
+		return Principal{}, errors.New(ErrorTokenSignature)
+	}
+
+	var tokenClaims claims
+	if err := decodeSegment(parts[1], &tokenClaims); err != nil {
+		return Principal{}, errors.New(ErrorTokenMalformed)
+	}
+	if err := v.validate(tokenClaims); err != nil {
+		return Principal{}, err
+	}
+
+	return Principal{
+		ID:     tokenClaims.Subject,
+		Method: MethodJWT,
+		Scopes: v.mapScopes(tokenClaims.scopes()),
+	}, nil
+}
+
+// validate checks the time, issuer, audience and subject claims.
+func (v *Verifier) validate(c claims) error {
+	now := v.now()
+	switch {
+	case c.ExpiresAt == nil || now.After(unixTime(*c.ExpiresAt).Add(v.leeway)):
+		return errors.New(ErrorTokenExpired)
+	case c.NotBefore != nil && now.Add(v.leeway).Before(unixTime(*c.NotBefore)):
+		return errors.New(ErrorTokenNotYetValid)
+	case c.Issuer != v.issuer:
+		return errors.New(ErrorTokenIssuer)
+	case !slices.Contains(c.Audience, v.audience):
+		return errors.New(ErrorTokenAudience)
+	case c.Subject == "":
+		return errors.New(ErrorTokenSubject)
+	}
+	return nil
+}
+
+// mapScopes translates the token scopes to the API scopes they grant.
+func (v *Verifier) mapScopes(tokenScopes []string) []string {
+	var granted []string
+	for _, scope := range tokenScopes {
+		mapped, found := v.scopeMap[scope]
+		if !found && len(v.scopeMap) == 0 && slices.Contains(Scopes, scope) {
+			mapped = []string{scope}
+		}
+		for _, m := range mapped {
+			if !slices.Contains(granted, m) {
+				granted = append(granted, m)
+			}
+		}
+	}
+	return granted
+}
+
+func decodeSegment(segment string, dst any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dst)
+}
+
+// maxUnixSeconds bounds the NumericDate claims, far beyond any real time but
+// within what a time.Time holds.
+const maxUnixSeconds = 1 << 62
+
+// unixTime returns the time of a NumericDate claim, clamped to
+// ±maxUnixSeconds.
+func unixTime(seconds float64) time.Time {
+	seconds = math.Max(-maxUnixSeconds, math.Min(seconds, maxUnixSeconds))
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*float64(time.Second)))
+}
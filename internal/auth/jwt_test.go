@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testIssuer signs tokens with an RSA and an EC key published in its JWKS.
+type testIssuer struct {
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+	jwks   []byte
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "alg": "RS256", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+	}})
+	require.NoError(t, err)
+
+	return &testIssuer{rsaKey: rsaKey, ecKey: ecKey, jwks: jwks}
+}
+
+// sign returns a compact token of the claims signed with the algorithm.
+func (i *testIssuer) sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(signingInput))
+	var signature []byte
+	switch alg {
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, i.rsaKey, crypto.SHA256, digest.Sum(nil))
+		require.NoError(t, err)
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, i.ecKey, digest.Sum(nil))
+		require.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	issuer := newTestIssuer(t)
+	keys, err := ParseJWKS(issuer.jwks)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	verifier := NewVerifier(keys, "https://id.example", "packer", time.Minute, nil)
+	verifier.now = func() time.Time { return now }
+
+	claims := func(overrides map[string]any) map[string]any {
+		c := map[string]any{
+			"iss":   "https://id.example",
+			"sub":   "user-42",
+			"aud":   []string{"packer", "other"},
+			"exp":   now.Add(time.Hour).Unix(),
+			"scope": "sizes:read packets:calculate openid",
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = v
+		}
+		return c
+	}
+
+	testCases := []struct {
+		name       string
+		token      string
+		wantErr    string
+		wantScopes []string
+	}{
+		{
+			name:       "RS256",
+			token:      issuer.sign(t, "RS256", "rsa-1", claims(nil)),
+			wantScopes: []string{ScopeSizesRead, ScopePacketsCalculate},
+		},
+		{
+			name:       "ES256 with string audience and scp",
+			token:      issuer.sign(t, "ES256", "ec-1", claims(map[string]any{"aud": "packer", "scope": nil, "scp": []string{"sizes:write"}})),
+			wantScopes: []string{ScopeSizesWrite},
+		},
+		{
+			name:  "expired within leeway",
+			token: issuer.sign(t, "RS256", "rsa-1", claims(map[string]any{"exp": now.Add(-30 * time.Second).Unix()})),
+			wantScopes: []string{
+				ScopeSizesRead, ScopePacketsCalculate,
+			},
+		},
+		{
+			name:       "expiry past 2262",
+			token:      issuer.sign(t, "RS256", "rsa-1", claims(map[string]any{"exp": int64(10_000_000_000_000)})),
+			wantScopes: []string{ScopeSizesRead, ScopePacketsCalculate},
+		},
+		{
+			name:       "expiry past the range of int64",
+			token:      issuer.sign(t, "RS256", "rsa-1", claims(map[string]any{"exp": 1e300})),
+			wantScopes: []string{ScopeSizesRead, ScopePacketsCalculate},
+		},
+		{
+			name:    "expired",
+			token:   issuer.sign(t, "RS256", "rsa-1", claims(map[string]any{"exp": now.Add(-time.Hour).Unix()})),
+			wantErr: ErrorTokenExpired,
+		},
+		{
+			name:    "no expiry",
+			token:   issuer.sign(t, "RS256", "rsa-1", claims(map[string]any{"exp": nil})),
+			wantErr: ErrorTokenExpired,
+		},
+		{
+			name:    "not yet valid",
+			token:   issuer.sign(t, "RS256", "rsa-1", claims(map[string]any{"nbf": now.Add(time.Hour).Unix()})),
+			wantErr: ErrorTokenNotYetValid,
+		},
+		{
+			name:    "wrong issuer",
+			token:   issuer.sign(t, "RS256", "rsa-1", claims(map[string]any{"iss": "https://evil.example"})),
+			wantErr: ErrorTokenIssuer,
+		},
+		{
+			name:    "wrong audience",
+			token:   issuer.sign(t, "RS256", "rsa-1", claims(map[string]any{"aud": "billing"})),
+			wantErr: ErrorTokenAudience,
+		},
+		{
+			name:    "no subject",
+			token:   issuer.sign(t, "RS256", "rsa-1", claims(map[string]any{"sub": nil})),
+			wantErr: ErrorTokenSubject,
+		},
+		{
+			name:    "key of another algorithm",
+			token:   issuer.sign(t, "ES256", "rsa-1", claims(nil)),
+			wantErr: ErrorTokenKeyNotFound,
+		},
+		{
+			name:    "unknown key",
+			token:   issuer.sign(t, "RS256", "rsa-2", claims(nil)),
+			wantErr: ErrorTokenKeyNotFound,
+		},
+		{
+			name:    "no key id with many keys",
+			token:   issuer.sign(t, "RS256", "", claims(nil)),
+			wantErr: ErrorTokenKeyNotFound,
+		},
+		{
+			name:    "alg none",
+			token:   issuer.sign(t, "none", "rsa-1", claims(nil)),
+			wantErr: ErrorTokenAlgorithm,
+		},
+		{
+			name:    "tampered payload",
+			token:   issuer.sign(t, "RS256", "rsa-1", claims(nil))[:40] + "x" + issuer.sign(t, "RS256", "rsa-1", claims(nil))[41:],
+			wantErr: ErrorTokenMalformed,
+		},
+		{
+			name:    "not a token",
+			token:   "opaque",
+			wantErr: ErrorTokenMalformed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			principal, err := verifier.Verify(tc.token)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				if tc.name != "tampered payload" {
+					require.EqualError(t, err, tc.wantErr)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "user-42", principal.ID)
+			require.Equal(t, MethodJWT, principal.Method)
+			require.Equal(t, tc.wantScopes, principal.Scopes)
+		})
+	}
+
+	t.Run("forged signature", func(t *testing.T) {
+		forger := newTestIssuer(t)
+		_, err := verifier.Verify(forger.sign(t, "RS256", "rsa-1", claims(nil)))
+		require.EqualError(t, err, ErrorTokenSignature)
+	})
+
+	t.Run("scope map", func(t *testing.T) {
+		mapped := NewVerifier(keys, "https://id.example", "packer", 0, map[string][]string{
+			"catalog.manage": {ScopeSizesRead, ScopeSizesWrite},
+		})
+		mapped.now = verifier.now
+		principal, err := mapped.Verify(issuer.sign(t, "RS256", "rsa-1", claims(map[string]any{"scope": "catalog.manage sizes:read"})))
+		require.NoError(t, err)
+		require.Equal(t, []string{ScopeSizesRead, ScopeSizesWrite}, principal.Scopes)
+	})
+}
+
+func TestLoadJWKS(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, issuer.jwks, 0o600))
+	keys, err := LoadJWKS(context.Background(), path)
+	require.NoError(t, err)
+	require.Len(t, keys.keys, 2, "the symmetric key is skipped")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/jwks.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(issuer.jwks)
+	}))
+	defer srv.Close()
+
+	keys, err = LoadJWKS(context.Background(), srv.URL+"/.well-known/jwks.json")
+	require.NoError(t, err)
+	require.Len(t, keys.keys, 2)
+
+	_, err = LoadJWKS(context.Background(), srv.URL+"/missing")
+	require.ErrorContains(t, err, "404")
+
+	_, err = ParseJWKS([]byte(`{"keys": [{"kty": "RSA", "n": "AQAB", "e": "AQAB"}]}`))
+	require.ErrorContains(t, err, "rsa modulus must be at least 2048 bits")
+	_, err = ParseJWKS([]byte(`{"keys": []}`))
+	require.Error(t, err)
+}
+
+func TestUnixTime(t *testing.T) {
+	require.Equal(t, time.Unix(1_700_000_000, 500_000_000), unixTime(1_700_000_000.5))
+	require.Equal(t, time.Unix(10_000_000_000_000, 0), unixTime(10_000_000_000_000))
+	require.True(t, unixTime(1e300).After(time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)))
+	require.True(t, unixTime(-1e300).Before(time.Unix(0, 0)))
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// subjectMethods are the authentication methods that qualify the subjects of
+// a binding.
+var subjectMethods = []string{MethodAPIKey, MethodJWT, MethodHMAC}
+
+// AllCatalogs stands for every catalog in the catalogs of a binding and of a
+// grant.
+const AllCatalogs = "*"
+
+// Role is a named set of permissions. The permissions are the API scopes;
+// admin grants every other one.
+type Role struct {
+	Permissions []string `yaml:"permissions" json:"permissions"`
+}
+
+// Binding grants the permissions of a role to subjects on some catalogs.
+type Binding struct {
+	Role string `yaml:"role" json:"role"`
+	// Subjects are principal IDs qualified by their authentication method:
+	// api_key:<key ID>, jwt:<token subject> or hmac:<client ID>. The same ID
+	// of different methods names different callers.
+	Subjects []string `yaml:"subjects" json:"subjects"`
+	// Catalogs limits the binding to the catalogs; it applies to every
+	// catalog when empty or when it holds "*".
+	Catalogs []string `yaml:"catalogs,omitempty" json:"catalogs,omitempty"`
+}
+
+// Policy is the role-based access control of the catalogs. When a policy is
+// in effect a principal may act on a catalog only if its scopes and one of
+// its bindings both allow it. Principals with the admin scope are not bound
+// by the policy.
+type Policy struct {
+	Roles    map[string]Role `yaml:"roles" json:"roles"`
+	Bindings []Binding       `yaml:"bindings" json:"bindings"`
+}
+
+// Grant is the set of permissions a principal holds on a catalog.
+type Grant struct {
+	// Catalog is a catalog ID, or "*" for every catalog.
+	Catalog     string   `json:"catalog"`
+	Permissions []string `json:"permissions"`
+}
+
+// LoadPolicy reads and validates the policy of a YAML or JSON file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy Policy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &policy, nil
+}
+
+// Validate returns every problem of the policy joined.
+func (p *Policy) Validate() error {
+	var errs []error
+	for name, role := range p.Roles {
+		prefix := "roles." + name
+		if len(role.Permissions) == 0 {
+			errs = append(errs, fmt.Errorf("%s: permissions must hold at least one permission", prefix))
+		}
+		for _, permission := range role.Permissions {
+			if !slices.Contains(Scopes, permission) {
+				errs = append(errs, fmt.Errorf("%s: unknown permission %q", prefix, permission))
+			}
+		}
+	}
+	for i, binding := range p.Bindings {
+		prefix := fmt.Sprintf("bindings[%d]", i)
+		if _, found := p.Roles[binding.Role]; !found {
+			errs = append(errs, fmt.Errorf("%s: unknown role %q", prefix, binding.Role))
+		}
+		if len(binding.Subjects) == 0 {
+			errs = append(errs, fmt.Errorf("%s: subjects must hold at least one subject", prefix))
+		}
+		for _, subject := range binding.Subjects {
+			method, id, _ := strings.Cut(subject, ":")
+			if !slices.Contains(subjectMethods, method) || id == "" {
+				errs = append(errs, fmt.Errorf("%s: subject %q must be api_key:<id>, jwt:<id> or hmac:<id>", prefix, subject))
+			}
+		}
+		for _, catalog := range binding.Catalogs {
+			if catalog != AllCatalogs && !validator.Matches(catalog, validator.IDRX) {
+				errs = append(errs, fmt.Errorf("%s: catalog %q must be 1-64 letters, digits, dashes or underscores", prefix, catalog))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Allowed reports whether the principal may use the permission on the
+// catalog. A nil policy leaves the decision to the scopes of the principal.
+func (p *Policy) Allowed(principal Principal, permission, catalog string) bool {
+	if !principal.HasScope(permission) {
+		return false
+	}
+	if p == nil || principal.HasScope(ScopeAdmin) {
+		return true
+	}
+
+	for _, binding := range p.Bindings {
+		if !slices.Contains(binding.Subjects, principal.Subject()) || !binding.covers(catalog) {
+			continue
+		}
+		role := p.Roles[binding.Role]
+		if slices.Contains(role.Permissions, ScopeAdmin) || slices.Contains(role.Permissions, permission) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Grants returns the permissions the principal holds, by catalog, sorted by
+// catalog with "*" first.
+func (p *Policy) Grants(principal Principal) []Grant {
+	if p == nil || principal.HasScope(ScopeAdmin) {
+		return []Grant{{Catalog: AllCatalogs, Permissions: grantedScopes(principal, Scopes)}}
+	}
+
+	byCatalog := make(map[string][]string)
+	for _, binding := range p.Bindings {
+		if !slices.Contains(binding.Subjects, principal.Subject()) {
+			continue
+		}
+		permissions := p.Roles[binding.Role].Permissions
+		if slices.Contains(permissions, ScopeAdmin) {
+			permissions = Scopes
+		}
+		catalogs := binding.Catalogs
+		if binding.covers(AllCatalogs) {
+			catalogs = []string{AllCatalogs}
+		}
+		for _, catalog := range catalogs {
+			byCatalog[catalog] = append(byCatalog[catalog], permissions...)
+		}
+	}
+
+	grants := make([]Grant, 0, len(byCatalog))
+	for catalog, permissions := range byCatalog {
+		// Permissions on every catalog hold on the named ones too.
+		permissions = append(permissions, byCatalog[AllCatalogs]...)
+		if granted := grantedScopes(principal, permissions); len(granted) > 0 {
+			grants = append(grants, Grant{Catalog: catalog, Permissions: granted})
+		}
+	}
+	sort.Slice(grants, func(i, j int) bool {
+		if grants[i].Catalog == AllCatalogs || grants[j].Catalog == AllCatalogs {
+			return grants[i].Catalog == AllCatalogs
+		}
+		return grants[i].Catalog < grants[j].Catalog
+	})
+
+	return grants
+}
+
+// covers reports whether the binding applies to the catalog.
+func (b Binding) covers(catalog string) bool {
+	return len(b.Catalogs) == 0 || slices.Contains(b.Catalogs, AllCatalogs) || slices.Contains(b.Catalogs, catalog)
+}
+
+// grantedScopes returns the permissions the principal is also granted by its
+// scopes, in the order of Scopes.
+func grantedScopes(principal Principal, permissions []string) []string {
+	var granted []string
+	for _, scope := range Scopes {
+		if slices.Contains(permissions, scope) && principal.HasScope(scope) {
+			granted = append(granted, scope)
+		}
+	}
+	return granted
+}
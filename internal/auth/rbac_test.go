@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+roles:
+  merchandiser:
+    permissions: [sizes:read, sizes:write]
+  warehouse:
+    permissions: [packets:calculate]
+  viewer:
+    permissions: [sizes:read]
+bindings:
+  - role: merchandiser
+    subjects: ["api_key:jane"]
+    catalogs: [eu, uk]
+  - role: viewer
+    subjects: ["api_key:jane", "jwt:jane"]
+  - role: warehouse
+    subjects: ["hmac:picker"]
+    catalogs: ["*"]
+`
+
+func TestPolicy_Allowed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0o600))
+	policy, err := LoadPolicy(path)
+	require.NoError(t, err)
+
+	jane := Principal{ID: "jane", Method: MethodAPIKey, Scopes: []string{ScopeSizesRead, ScopeSizesWrite, ScopePacketsCalculate}}
+	picker := Principal{ID: "picker", Method: MethodHMAC, Scopes: []string{ScopePacketsCalculate}}
+	ops := Principal{ID: "ops", Method: MethodAPIKey, Scopes: []string{ScopeAdmin}}
+
+	testCases := []struct {
+		name       string
+		policy     *Policy
+		principal  Principal
+		permission string
+		catalog    string
+		want       bool
+	}{
+		{name: "write on a bound catalog", policy: policy, principal: jane, permission: ScopeSizesWrite, catalog: "eu", want: true},
+		{name: "write on another catalog", policy: policy, principal: jane, permission: ScopeSizesWrite, catalog: "default", want: false},
+		{name: "read on every catalog", policy: policy, principal: jane, permission: ScopeSizesRead, catalog: "default", want: true},
+		{name: "scope without a role", policy: policy, principal: jane, permission: ScopePacketsCalculate, catalog: "eu", want: false},
+		{name: "role without the scope", policy: policy, principal: picker, permission: ScopeSizesRead, catalog: "eu", want: false},
+		{name: "wildcard catalog", policy: policy, principal: picker, permission: ScopePacketsCalculate, catalog: "us", want: true},
+		{name: "same ID of another method", policy: policy, principal: Principal{ID: "picker", Method: MethodJWT, Scopes: []string{ScopePacketsCalculate}}, permission: ScopePacketsCalculate, catalog: "us", want: false},
+		{name: "unbound subject", policy: policy, principal: Principal{ID: "bob", Method: MethodAPIKey, Scopes: []string{ScopeSizesRead}}, permission: ScopeSizesRead, catalog: "eu", want: false},
+		{name: "admin bypasses the policy", policy: policy, principal: ops, permission: ScopeSizesWrite, catalog: "default", want: true},
+		{name: "no policy", principal: picker, permission: ScopePacketsCalculate, catalog: "eu", want: true},
+		{name: "no policy without the scope", principal: picker, permission: ScopeSizesWrite, catalog: "eu", want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.policy.Allowed(tc.principal, tc.permission, tc.catalog))
+		})
+	}
+}
+
+func TestPolicy_Grants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0o600))
+	policy, err := LoadPolicy(path)
+	require.NoError(t, err)
+
+	jane := Principal{ID: "jane", Method: MethodAPIKey, Scopes: []string{ScopeSizesRead, ScopeSizesWrite}}
+	require.Equal(t, []Grant{
+		{Catalog: AllCatalogs, Permissions: []string{ScopeSizesRead}},
+		{Catalog: "eu", Permissions: []string{ScopeSizesRead, ScopeSizesWrite}},
+		{Catalog: "uk", Permissions: []string{ScopeSizesRead, ScopeSizesWrite}},
+	}, policy.Grants(jane))
+
+	readOnly := Principal{ID: "jane", Method: MethodAPIKey, Scopes: []string{ScopeSizesRead}}
+	require.Equal(t, []Grant{
+		{Catalog: AllCatalogs, Permissions: []string{ScopeSizesRead}},
+		{Catalog: "eu", Permissions: []string{ScopeSizesRead}},
+		{Catalog: "uk", Permissions: []string{ScopeSizesRead}},
+	}, policy.Grants(readOnly))
+
+	require.Empty(t, policy.Grants(Principal{ID: "bob", Method: MethodAPIKey, Scopes: []string{ScopeSizesRead}}))
+
+	var none *Policy
+	require.Equal(t, []Grant{{Catalog: AllCatalogs, Permissions: Scopes}}, none.Grants(Principal{ID: "ops", Method: MethodAPIKey, Scopes: []string{ScopeAdmin}}))
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte(`
+roles:
+  editor:
+    permissions: [sizes:delete]
+bindings:
+  - role: author
+    catalogs: ["eu west"]
+  - role: editor
+    subjects: [jane, "ldap:jane", "jwt:"]
+`), 0o600))
+	unknown := filepath.Join(dir, "unknown.yaml")
+	require.NoError(t, os.WriteFile(unknown, []byte("rules: []\n"), 0o600))
+
+	_, err := LoadPolicy(invalid)
+	require.ErrorContains(t, err, `roles.editor: unknown permission "sizes:delete"`)
+	require.ErrorContains(t, err, `bindings[0]: unknown role "author"`)
+	require.ErrorContains(t, err, "bindings[0]: subjects must hold at least one subject")
+	require.ErrorContains(t, err, `bindings[1]: subject "jane" must be api_key:<id>, jwt:<id> or hmac:<id>`)
+	require.ErrorContains(t, err, `bindings[1]: subject "ldap:jane" must be`)
+	require.ErrorContains(t, err, `bindings[1]: subject "jwt:" must be`)
+	require.ErrorContains(t, err, `bindings[0]: catalog "eu west" must be 1-64 letters, digits, dashes or underscores`)
+
+	_, err = LoadPolicy(unknown)
+	require.ErrorContains(t, err, "field rules not found")
+}
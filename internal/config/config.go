@@ -0,0 +1,693 @@
+// Package config loads the runtime configuration of the API. Values are taken
+// from the built-in defaults, then the config file, then the environment and
+// finally the command line flags, each overriding the previous one.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the runtime configuration of the API.
+type Config struct {
+	// Port is the TCP port the REST API listens on.
+	Port int `yaml:"port"`
+	// CatalogFile is the YAML or JSON file seeding the catalogs; the built-in
+	// sizes are used when it is empty.
+	CatalogFile string `yaml:"catalog_file"`
+	// LogLevel is one of debug, info, warn or error.
+	LogLevel string  `yaml:"log_level"`
+	HTTP     HTTP    `yaml:"http"`
+	Limiter  Limiter `yaml:"limiter"`
+	CORS     CORS    `yaml:"cors"`
+	Auth     Auth    `yaml:"auth"`
+}
+
+// HTTP holds the timeouts of the HTTP server.
+type HTTP struct {
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// Limiter holds the per client rate limits of the API. Clients are told
+// apart by their API key when they present a known one and by their IP
+// address otherwise.
+type Limiter struct {
+	Enabled bool `yaml:"enabled"`
+	// Rate is the limit of every client on every route.
+	Rate `yaml:",inline"`
+	// IdleTimeout is how long a client may make no request before its
+	// limiters are dropped.
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+	// TrustedProxies are the IP addresses or CIDR ranges of the proxies, such
+	// as the load balancer, whose X-Forwarded-For header names the client.
+	TrustedProxies []string `yaml:"trusted_proxies,omitempty"`
+	// Keys overrides the limit of the API keys by key ID.
+	Keys map[string]Rate `yaml:"keys,omitempty"`
+	// Routes overrides the limit of the matching requests for every client;
+	// the first matching route wins over the limit of the key.
+	Routes []RouteLimit `yaml:"routes,omitempty"`
+	// Backend is where the limits are counted.
+	Backend LimiterBackend `yaml:"backend"`
+}
+
+// Rate limiter backends.
+const (
+	// BackendMemory counts the limits in memory, apart on every replica.
+	BackendMemory = "memory"
+	// BackendRESP counts the limits on a Redis protocol compatible server
+	// shared by the replicas, and in memory while it cannot be reached.
+	BackendRESP = "resp"
+)
+
+// LimiterBackend holds where the rate limits are counted.
+type LimiterBackend struct {
+	// Kind is memory or resp.
+	Kind string `yaml:"kind"`
+	// Address is the host:port of the resp server.
+	Address string `yaml:"address,omitempty"`
+	// Password authenticates to the resp server. It is taken from the
+	// environment or the flags only, so that -print-config never shows it.
+	Password string `yaml:"-"`
+	// Timeout bounds every call to the resp server.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Rate is a token bucket refilled with RPS tokens a second that holds up to
+// Burst tokens; every request takes one.
+type Rate struct {
+	RPS   float64 `yaml:"rps"`
+	Burst int     `yaml:"burst"`
+}
+
+// RouteLimit is the limit of the requests to a route.
+type RouteLimit struct {
+	// Method limits the route to an HTTP method; it matches any when empty.
+	Method string `yaml:"method,omitempty"`
+	// Path is a request path, or a path prefix when it ends with "*".
+	Path string `yaml:"path"`
+	Rate `yaml:",inline"`
+}
+
+// Match reports whether the request method and path are of the route.
+func (l RouteLimit) Match(method, path string) bool {
+	if l.Method != "" && !strings.EqualFold(l.Method, method) {
+		return false
+	}
+	if prefix, found := strings.CutSuffix(l.Path, "*"); found {
+		return strings.HasPrefix(path, prefix)
+	}
+	return path == l.Path
+}
+
+// CORS holds the cross-origin policy of the API.
+type CORS struct {
+	// AllowedOrigins lists the origins allowed to call the API; "*" allows
+	// any, and a "*" in an origin stands for any subdomain, such as in
+	// https://*.example.com.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	// AllowedMethods are the methods cross-origin requests may use.
+	AllowedMethods []string `yaml:"allowed_methods"`
+	// AllowedHeaders are the request headers cross-origin requests may send;
+	// "*" allows any.
+	AllowedHeaders []string `yaml:"allowed_headers"`
+	// AllowCredentials lets cross-origin requests carry cookies and
+	// authorization headers. It cannot be combined with any origin.
+	AllowCredentials bool `yaml:"allow_credentials"`
+	// MaxAge is how long browsers may cache the answer to a preflight
+	// request; they do not cache it when it is 0.
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+// AnyOrigin reports whether every origin is allowed.
+func (c CORS) AnyOrigin() bool {
+	return slices.Contains(c.AllowedOrigins, "*")
+}
+
+// AllowsOrigin reports whether the origin may call the API.
+func (c CORS) AllowsOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		prefix, suffix, wildcard := strings.Cut(allowed, "*")
+		switch {
+		case !wildcard:
+			if origin == allowed {
+				return true
+			}
+		case len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix):
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsHeaders reports whether the request headers may be sent.
+func (c CORS) AllowsHeaders(headers []string) bool {
+	if slices.Contains(c.AllowedHeaders, "*") {
+		return true
+	}
+	for _, header := range headers {
+		if !slices.ContainsFunc(c.AllowedHeaders, func(allowed string) bool { return strings.EqualFold(allowed, header) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// Auth holds the authentication and authorization of the API callers.
+type Auth struct {
+	// Enabled requires callers to authenticate on every route but the public ones.
+	Enabled bool `yaml:"enabled"`
+	// KeysFile is a YAML or JSON file with more keys under a "keys" list.
+	KeysFile string        `yaml:"keys_file"`
+	Keys     []auth.APIKey `yaml:"keys,omitempty"`
+	// FileKeys are the keys read from KeysFile by Load.
+	FileKeys []auth.APIKey `yaml:"-"`
+	JWT      JWT           `yaml:"jwt"`
+	// PolicyFile is a YAML or JSON file with the roles and bindings limiting
+	// callers to some catalogs; without it the scopes alone decide.
+	PolicyFile string `yaml:"policy_file"`
+	// Policy is the policy read from PolicyFile by Load.
+	Policy *auth.Policy `yaml:"-"`
+	HMAC   HMAC         `yaml:"hmac"`
+}
+
+// HMAC holds the signed request authentication of machine clients.
+type HMAC struct {
+	// ClientsFile is a YAML or JSON file with the clients and their secrets
+	// under a "clients" list. The secrets are kept out of the config file so
+	// that -print-config never shows them.
+	ClientsFile string `yaml:"clients_file"`
+	// Window is how far the timestamp of a signed request may be from the
+	// current time before it is rejected as a replay.
+	Window time.Duration `yaml:"window"`
+	// Clients are the clients read from ClientsFile by Load.
+	Clients []auth.HMACClient `yaml:"-"`
+}
+
+// JWT holds the bearer token authentication of the API.
+type JWT struct {
+	// JWKS is the file path or http(s) URL of the key set verifying tokens;
+	// bearer tokens are accepted only when it is set. It is read at start-up
+	// and on reloads.
+	JWKS     string `yaml:"jwks"`
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	// Leeway absorbs the clock skew between the issuer and the API.
+	Leeway time.Duration `yaml:"leeway"`
+	// Scopes maps token scopes to API scopes. Without it the token scopes
+	// that are API scopes are granted as they are.
+	Scopes map[string][]string `yaml:"scopes,omitempty"`
+}
+
+// APIKeys returns the keys of the configuration and of the keys file.
+func (a Auth) APIKeys() []auth.APIKey {
+	return append(slices.Clone(a.Keys), a.FileKeys...)
+}
+
+// Default returns the built-in configuration.
+func Default() Config {
+	return Config{
+		Port:     8080,
+		LogLevel: "info",
+		HTTP: HTTP{
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     time.Minute,
+			ShutdownTimeout: 5 * time.Second,
+		},
+		Limiter: Limiter{
+			Enabled: true,
+			Rate: Rate{
+				RPS:   10,
+				Burst: 10,
+			},
+			IdleTimeout: 3 * time.Minute,
+			Backend: LimiterBackend{
+				Kind:    BackendMemory,
+				Timeout: 100 * time.Millisecond,
+			},
+		},
+		CORS: CORS{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+			AllowedHeaders: []string{"Content-Type", "Authorization", "X-API-Key", "X-Client-ID", "X-Signature-Timestamp", "X-Signature", "X-Request-ID"},
+			MaxAge:         10 * time.Minute,
+		},
+		Auth: Auth{
+			JWT: JWT{
+				Leeway: time.Minute,
+			},
+			HMAC: HMAC{
+				Window: 5 * time.Minute,
+			},
+		},
+	}
+}
+
+// Validate returns every invalid value of the configuration joined.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, message string) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, message))
+		}
+	}
+
+	check(c.Port > 0 && c.Port <= 65535, "port", "must be between 1 and 65535")
+	_, err := c.Level()
+	check(err == nil, "log_level", "must be one of debug, info, warn or error")
+	check(c.HTTP.ReadTimeout > 0, "http.read_timeout", "must be more than 0")
+	check(c.HTTP.WriteTimeout > 0, "http.write_timeout", "must be more than 0")
+	check(c.HTTP.IdleTimeout > 0, "http.idle_timeout", "must be more than 0")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdown_timeout", "must be more than 0")
+	if c.Limiter.Enabled {
+		checkRate := func(key string, rate Rate) {
+			check(rate.RPS > 0, key+"rps", "must be more than 0")
+			check(rate.Burst > 0, key+"burst", "must be more than 0")
+		}
+		checkRate("limiter.", c.Limiter.Rate)
+		check(c.Limiter.IdleTimeout > 0, "limiter.idle_timeout", "must be more than 0")
+		for _, proxy := range c.Limiter.TrustedProxies {
+			_, err := ParsePrefix(proxy)
+			check(err == nil, "limiter.trusted_proxies", fmt.Sprintf("%q must be an IP address or CIDR range", proxy))
+		}
+		keyIDs := make(map[string]bool)
+		for _, key := range c.Auth.APIKeys() {
+			keyIDs[key.ID] = true
+		}
+		for id, rate := range c.Limiter.Keys {
+			prefix := "limiter.keys." + id
+			check(keyIDs[id], prefix, "must be the ID of an API key")
+			checkRate(prefix+".", rate)
+		}
+		for i, route := range c.Limiter.Routes {
+			prefix := fmt.Sprintf("limiter.routes[%d]", i)
+			check(strings.HasPrefix(route.Path, "/"), prefix+".path", "must start with /")
+			checkRate(prefix+".", route.Rate)
+		}
+		backend := c.Limiter.Backend
+		check(backend.Kind == BackendMemory || backend.Kind == BackendRESP, "limiter.backend.kind", "must be memory or resp")
+		if backend.Kind == BackendRESP {
+			_, _, err := net.SplitHostPort(backend.Address)
+			check(err == nil, "limiter.backend.address", "must be a host:port address")
+		}
+		check(backend.Timeout > 0, "limiter.backend.timeout", "must be more than 0")
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		check(origin == "*" || strings.Contains(origin, "://"), "cors.allowed_origins", fmt.Sprintf("%q must be * or a scheme://host origin", origin))
+		check(strings.Count(origin, "*") <= 1, "cors.allowed_origins", fmt.Sprintf("%q must hold one * at most", origin))
+	}
+	check(!c.CORS.AllowCredentials || !c.CORS.AnyOrigin(), "cors.allow_credentials", "must not be combined with any origin")
+	check(len(c.CORS.AllowedMethods) > 0, "cors.allowed_methods", "must hold at least one method")
+	check(c.CORS.MaxAge >= 0, "cors.max_age", "must not be negative")
+	if err := auth.ValidateKeys(c.Auth.APIKeys()); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Auth.JWT.JWKS != "" {
+		check(c.Auth.JWT.Issuer != "", "auth.jwt.issuer", "must be provided with a jwks")
+		check(c.Auth.JWT.Audience != "", "auth.jwt.audience", "must be provided with a jwks")
+	}
+	check(c.Auth.JWT.Leeway >= 0, "auth.jwt.leeway", "must not be negative")
+	check(c.Auth.HMAC.Window > 0, "auth.hmac.window", "must be more than 0")
+	if err := auth.ValidateHMACClients(c.Auth.HMAC.Clients); err != nil {
+		errs = append(errs, err)
+	}
+	for tokenScope, scopes := range c.Auth.JWT.Scopes {
+		for _, scope := range scopes {
+			check(slices.Contains(auth.Scopes, scope), "auth.jwt.scopes."+tokenScope, fmt.Sprintf("unknown scope %q", scope))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ParsePrefix parses an IP address or CIDR range; an address is the range of
+// itself alone.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Level returns the slog level of the LogLevel.
+func (c Config) Level() (slog.Level, error) {
+	var level slog.Level
+	switch strings.ToLower(c.LogLevel) {
+	case "debug":
+		level = slog.LevelDebug
+	case "info":
+		level = slog.LevelInfo
+	case "warn":
+		level = slog.LevelWarn
+	case "error":
+		level = slog.LevelError
+	default:
+		return level, fmt.Errorf("unknown log level %q", c.LogLevel)
+	}
+	return level, nil
+}
+
+// Print writes the configuration as YAML.
+func (c Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// setting is a configuration value settable from the environment and flags.
+type setting struct {
+	flag   string
+	env    string
+	usage  string
+	isBool bool
+	set    func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{flag: "port", env: "PORT", usage: "TCP port of the REST API", set: func(c *Config, v string) error {
+		return parseInt(v, &c.Port)
+	}},
+	{flag: "catalog-file", env: "CATALOG_FILE", usage: "YAML or JSON file with the initial catalogs", set: func(c *Config, v string) error {
+		c.CatalogFile = v
+		return nil
+	}},
+	{flag: "log-level", env: "LOG_LEVEL", usage: "log level: debug, info, warn or error", set: func(c *Config, v string) error {
+		c.LogLevel = v
+		return nil
+	}},
+	{flag: "read-timeout", env: "READ_TIMEOUT", usage: "HTTP read timeout", set: func(c *Config, v string) error {
+		return parseDuration(v, &c.HTTP.ReadTimeout)
+	}},
+	{flag: "write-timeout", env: "WRITE_TIMEOUT", usage: "HTTP write timeout", set: func(c *Config, v string) error {
+		return parseDuration(v, &c.HTTP.WriteTimeout)
+	}},
+	{flag: "idle-timeout", env: "IDLE_TIMEOUT", usage: "HTTP idle timeout", set: func(c *Config, v string) error {
+		return parseDuration(v, &c.HTTP.IdleTimeout)
+	}},
+	{flag: "shutdown-timeout", env: "SHUTDOWN_TIMEOUT", usage: "graceful shutdown timeout", set: func(c *Config, v string) error {
+		return parseDuration(v, &c.HTTP.ShutdownTimeout)
+	}},
+	{flag: "limiter-enabled", env: "LIMITER_ENABLED", usage: "enable the per client rate limit", isBool: true, set: func(c *Config, v string) error {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.New("must be a boolean value")
+		}
+		c.Limiter.Enabled = enabled
+		return nil
+	}},
+	{flag: "limiter-rps", env: "LIMITER_RPS", usage: "requests per second allowed per client", set: func(c *Config, v string) error {
+		rps, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		c.Limiter.RPS = rps
+		return nil
+	}},
+	{flag: "limiter-burst", env: "LIMITER_BURST", usage: "requests allowed per client in a burst", set: func(c *Config, v string) error {
+		return parseInt(v, &c.Limiter.Burst)
+	}},
+	{flag: "limiter-idle-timeout", env: "LIMITER_IDLE_TIMEOUT", usage: "idle time after which a client's rate limiters are dropped", set: func(c *Config, v string) error {
+		return parseDuration(v, &c.Limiter.IdleTimeout)
+	}},
+	{flag: "limiter-trusted-proxies", env: "LIMITER_TRUSTED_PROXIES", usage: "comma separated IPs or CIDRs of the proxies trusted with X-Forwarded-For", set: func(c *Config, v string) error {
+		c.Limiter.TrustedProxies = splitList(v)
+		return nil
+	}},
+	{flag: "limiter-backend", env: "LIMITER_BACKEND", usage: "where rate limits are counted: memory or resp", set: func(c *Config, v string) error {
+		c.Limiter.Backend.Kind = v
+		return nil
+	}},
+	{flag: "limiter-backend-address", env: "LIMITER_BACKEND_ADDRESS", usage: "host:port of the Redis protocol compatible server sharing the rate limits", set: func(c *Config, v string) error {
+		c.Limiter.Backend.Address = v
+		return nil
+	}},
+	{flag: "limiter-backend-password", env: "LIMITER_BACKEND_PASSWORD", usage: "password of the rate limit server", set: func(c *Config, v string) error {
+		c.Limiter.Backend.Password = v
+		return nil
+	}},
+	{flag: "limiter-backend-timeout", env: "LIMITER_BACKEND_TIMEOUT", usage: "timeout of the calls to the rate limit server", set: func(c *Config, v string) error {
+		return parseDuration(v, &c.Limiter.Backend.Timeout)
+	}},
+	{flag: "cors-allowed-origins", env: "CORS_ALLOWED_ORIGINS", usage: "comma separated origins allowed to call the API", set: func(c *Config, v string) error {
+		c.CORS.AllowedOrigins = splitList(v)
+		return nil
+	}},
+	{flag: "cors-allowed-methods", env: "CORS_ALLOWED_METHODS", usage: "comma separated methods allowed in cross-origin requests", set: func(c *Config, v string) error {
+		c.CORS.AllowedMethods = splitList(strings.ToUpper(v))
+		return nil
+	}},
+	{flag: "cors-allowed-headers", env: "CORS_ALLOWED_HEADERS", usage: "comma separated headers allowed in cross-origin requests", set: func(c *Config, v string) error {
+		c.CORS.AllowedHeaders = splitList(v)
+		return nil
+	}},
+	{flag: "cors-allow-credentials", env: "CORS_ALLOW_CREDENTIALS", usage: "let cross-origin requests carry credentials", isBool: true, set: func(c *Config, v string) error {
+		allow, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.New("must be a boolean value")
+		}
+		c.CORS.AllowCredentials = allow
+		return nil
+	}},
+	{flag: "cors-max-age", env: "CORS_MAX_AGE", usage: "how long browsers may cache preflight answers", set: func(c *Config, v string) error {
+		return parseDuration(v, &c.CORS.MaxAge)
+	}},
+	{flag: "auth-enabled", env: "AUTH_ENABLED", usage: "require API keys on every non public route", isBool: true, set: func(c *Config, v string) error {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.New("must be a boolean value")
+		}
+		c.Auth.Enabled = enabled
+		return nil
+	}},
+	{flag: "auth-keys-file", env: "AUTH_KEYS_FILE", usage: "YAML or JSON file with the hashed API keys", set: func(c *Config, v string) error {
+		c.Auth.KeysFile = v
+		return nil
+	}},
+	{flag: "auth-policy-file", env: "AUTH_POLICY_FILE", usage: "YAML or JSON file with the RBAC roles and bindings", set: func(c *Config, v string) error {
+		c.Auth.PolicyFile = v
+		return nil
+	}},
+	{flag: "hmac-clients-file", env: "HMAC_CLIENTS_FILE", usage: "YAML or JSON file with the secrets of the request signing clients", set: func(c *Config, v string) error {
+		c.Auth.HMAC.ClientsFile = v
+		return nil
+	}},
+	{flag: "hmac-window", env: "HMAC_WINDOW", usage: "replay window of signed request timestamps", set: func(c *Config, v string) error {
+		return parseDuration(v, &c.Auth.HMAC.Window)
+	}},
+	{flag: "jwt-jwks", env: "JWT_JWKS", usage: "file path or URL of the JWKS verifying bearer tokens", set: func(c *Config, v string) error {
+		c.Auth.JWT.JWKS = v
+		return nil
+	}},
+	{flag: "jwt-issuer", env: "JWT_ISSUER", usage: "accepted issuer of bearer tokens", set: func(c *Config, v string) error {
+		c.Auth.JWT.Issuer = v
+		return nil
+	}},
+	{flag: "jwt-audience", env: "JWT_AUDIENCE", usage: "accepted audience of bearer tokens", set: func(c *Config, v string) error {
+		c.Auth.JWT.Audience = v
+		return nil
+	}},
+	{flag: "jwt-leeway", env: "JWT_LEEWAY", usage: "clock skew allowed on bearer token times", set: func(c *Config, v string) error {
+		return parseDuration(v, &c.Auth.JWT.Leeway)
+	}},
+}
+
+// configFileEnv names the environment variable with the config file, used
+// when the -config flag is not given.
+const configFileEnv = "CONFIG_FILE"
+
+// Load builds the configuration from the defaults, the config file, the
+// environment and the command line arguments, in that order of precedence,
+// and validates it. It also reports whether -print-config was given.
+func Load(name string, args []string, lookupEnv func(string) (string, bool)) (Config, bool, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	configFile, _ := lookupEnv(configFileEnv)
+	fs.StringVar(&configFile, "config", configFile, "YAML or JSON config file (env "+configFileEnv+")")
+	printConfig := fs.Bool("print-config", false, "print the effective configuration and exit")
+
+	type flagValue struct {
+		setting setting
+		value   string
+	}
+	var flagged []flagValue
+	for _, s := range settings {
+		s := s
+		usage := fmt.Sprintf("%s (env %s)", s.usage, s.env)
+		record := func(value string) error {
+			flagged = append(flagged, flagValue{setting: s, value: value})
+			return nil
+		}
+		if s.isBool {
+			fs.BoolFunc(s.flag, usage, record)
+		} else {
+			fs.Func(s.flag, usage, record)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, false, err
+	}
+
+	cfg := Default()
+	if configFile != "" {
+		data, err := os.ReadFile(configFile)
+		if err != nil {
+			return Config{}, false, err
+		}
+		if err := decodeFile(data, &cfg); err != nil {
+			return Config{}, false, fmt.Errorf("%s: %w", configFile, err)
+		}
+	}
+
+	for _, s := range settings {
+		value, found := lookupEnv(s.env)
+		if !found {
+			continue
+		}
+		if err := s.set(&cfg, value); err != nil {
+			return Config{}, false, fmt.Errorf("env %s: %w", s.env, err)
+		}
+	}
+
+	for _, f := range flagged {
+		if err := f.setting.set(&cfg, f.value); err != nil {
+			return Config{}, false, fmt.Errorf("flag -%s: %w", f.setting.flag, err)
+		}
+	}
+
+	if cfg.Auth.KeysFile != "" {
+		keys, err := loadKeys(cfg.Auth.KeysFile)
+		if err != nil {
+			return Config{}, false, err
+		}
+		cfg.Auth.FileKeys = keys
+	}
+	if cfg.Auth.HMAC.ClientsFile != "" {
+		clients, err := loadHMACClients(cfg.Auth.HMAC.ClientsFile)
+		if err != nil {
+			return Config{}, false, err
+		}
+		cfg.Auth.HMAC.Clients = clients
+	}
+	if cfg.Auth.PolicyFile != "" {
+		policy, err := auth.LoadPolicy(cfg.Auth.PolicyFile)
+		if err != nil {
+			return Config{}, false, err
+		}
+		cfg.Auth.Policy = policy
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, false, err
+	}
+
+	return cfg, *printConfig, nil
+}
+
+// loadKeys reads the API keys of a keys file.
+func loadKeys(path string) ([]auth.APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Keys []auth.APIKey `yaml:"keys"`
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return file.Keys, nil
+}
+
+// loadHMACClients reads the request signing clients of a clients file.
+func loadHMACClients(path string) ([]auth.HMACClient, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Clients []auth.HMACClient `yaml:"clients"`
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return file.Clients, nil
+}
+
+// decodeFile overrides the configuration with the values of a YAML or JSON
+// file, rejecting unknown keys.
+func decodeFile(data []byte, cfg *Config) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	err := dec.Decode(cfg)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	return err
+}
+
+// splitList returns the non-empty items of a comma separated list.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseInt(value string, dst *int) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return errors.New("must be an integer")
+	}
+	*dst = n
+	return nil
+}
+
+func parseDuration(value string, dst *time.Duration) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return errors.New("must be a duration such as 10s")
+	}
+	*dst = d
+	return nil
+}
@@ -0,0 +1,260 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func lookupEnv(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, found := env[key]
+		return value, found
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("port: 9000\nhttp:\n  read_timeout: 3s\nlimiter:\n  rps: 5\n  burst: 5\n"), 0o600))
+	unknown := filepath.Join(dir, "unknown.yaml")
+	require.NoError(t, os.WriteFile(unknown, []byte("prot: 9000\n"), 0o600))
+
+	testCases := []struct {
+		name      string
+		args      []string
+		env       map[string]string
+		want      func(c *Config)
+		wantPrint bool
+		wantErr   string
+	}{
+		{
+			name: "defaults",
+			want: func(c *Config) {},
+		},
+		{
+			name: "file overrides defaults",
+			args: []string{"-config", file},
+			want: func(c *Config) {
+				c.Port = 9000
+				c.HTTP.ReadTimeout = 3 * time.Second
+				c.Limiter.RPS, c.Limiter.Burst = 5, 5
+			},
+		},
+		{
+			name: "env overrides file",
+			env:  map[string]string{"CONFIG_FILE": file, "PORT": "9100", "LIMITER_ENABLED": "false"},
+			want: func(c *Config) {
+				c.Port = 9100
+				c.HTTP.ReadTimeout = 3 * time.Second
+				c.Limiter.RPS, c.Limiter.Burst = 5, 5
+				c.Limiter.Enabled = false
+			},
+		},
+		{
+			name: "flags override env",
+			args: []string{"-config", file, "-port", "9200", "-limiter-enabled", "-print-config"},
+			env:  map[string]string{"PORT": "9100", "LIMITER_ENABLED": "false", "CATALOG_FILE": "catalogs.yaml"},
+			want: func(c *Config) {
+				c.Port = 9200
+				c.CatalogFile = "catalogs.yaml"
+				c.HTTP.ReadTimeout = 3 * time.Second
+				c.Limiter.RPS, c.Limiter.Burst = 5, 5
+			},
+			wantPrint: true,
+		},
+		{
+			name:    "invalid env",
+			env:     map[string]string{"WRITE_TIMEOUT": "soon"},
+			wantErr: "env WRITE_TIMEOUT: must be a duration such as 10s",
+		},
+		{
+			name:    "invalid flag",
+			args:    []string{"-limiter-burst", "many"},
+			wantErr: "flag -limiter-burst: must be an integer",
+		},
+		{
+			name:    "unknown file key",
+			args:    []string{"-config", unknown},
+			wantErr: "field prot not found",
+		},
+		{
+			name:    "failed validation",
+			args:    []string{"-port", "70000", "-limiter-rps", "0"},
+			wantErr: "port: must be between 1 and 65535\nlimiter.rps: must be more than 0",
+		},
+		{
+			name: "limiter from env",
+			env:  map[string]string{"LIMITER_TRUSTED_PROXIES": "10.0.0.0/8, 192.0.2.1", "LIMITER_IDLE_TIMEOUT": "10m"},
+			want: func(c *Config) {
+				c.Limiter.TrustedProxies = []string{"10.0.0.0/8", "192.0.2.1"}
+				c.Limiter.IdleTimeout = 10 * time.Minute
+			},
+		},
+		{
+			name:    "invalid limiter",
+			env:     map[string]string{"LIMITER_TRUSTED_PROXIES": "lb.internal", "LIMITER_IDLE_TIMEOUT": "0s"},
+			wantErr: "limiter.idle_timeout: must be more than 0\nlimiter.trusted_proxies: \"lb.internal\" must be an IP address or CIDR range",
+		},
+		{
+			name: "shared limiter backend",
+			env:  map[string]string{"LIMITER_BACKEND": "resp", "LIMITER_BACKEND_ADDRESS": "redis:6379", "LIMITER_BACKEND_PASSWORD": "s3cret"},
+			want: func(c *Config) {
+				c.Limiter.Backend.Kind = BackendRESP
+				c.Limiter.Backend.Address = "redis:6379"
+				c.Limiter.Backend.Password = "s3cret"
+			},
+		},
+		{
+			name:    "invalid limiter backend",
+			args:    []string{"-limiter-backend", "resp", "-limiter-backend-timeout", "0s"},
+			wantErr: "limiter.backend.address: must be a host:port address\nlimiter.backend.timeout: must be more than 0",
+		},
+		{
+			name: "cors from flags",
+			args: []string{"-cors-allowed-origins", "https://shop.example, https://*.shop.example", "-cors-allowed-methods", "get,put", "-cors-allow-credentials", "-cors-max-age", "1h"},
+			want: func(c *Config) {
+				c.CORS.AllowedOrigins = []string{"https://shop.example", "https://*.shop.example"}
+				c.CORS.AllowedMethods = []string{"GET", "PUT"}
+				c.CORS.AllowCredentials = true
+				c.CORS.MaxAge = time.Hour
+			},
+		},
+		{
+			name:    "invalid cors",
+			env:     map[string]string{"CORS_ALLOW_CREDENTIALS": "true", "CORS_ALLOWED_METHODS": ""},
+			wantErr: "cors.allow_credentials: must not be combined with any origin\ncors.allowed_methods: must hold at least one method",
+		},
+		{
+			name:    "jwks without issuer and audience",
+			env:     map[string]string{"JWT_JWKS": "jwks.json", "JWT_LEEWAY": "-1s"},
+			wantErr: "auth.jwt.issuer: must be provided with a jwks\nauth.jwt.audience: must be provided with a jwks\nauth.jwt.leeway: must not be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, printConfig, err := Load("api", tc.args, lookupEnv(tc.env))
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			want := Default()
+			tc.want(&want)
+			require.Equal(t, want, cfg)
+			require.Equal(t, tc.wantPrint, printConfig)
+		})
+	}
+}
+
+func TestLoad_KeysFile(t *testing.T) {
+	dir := t.TempDir()
+	keysFile := filepath.Join(dir, "keys.yaml")
+	require.NoError(t, os.WriteFile(keysFile, []byte("keys:\n  - id: ops\n    hash: "+auth.HashKey("secret")+"\n    scopes: [admin]\n"), 0o600))
+	badFile := filepath.Join(dir, "bad.yaml")
+	require.NoError(t, os.WriteFile(badFile, []byte("keys:\n  - id: ops\n    hash: secret\n    scopes: [admin]\n"), 0o600))
+
+	cfg, _, err := Load("api", []string{"-auth-enabled", "-auth-keys-file", keysFile}, lookupEnv(nil))
+	require.NoError(t, err)
+	require.True(t, cfg.Auth.Enabled)
+	require.Equal(t, []auth.APIKey{{ID: "ops", Hash: auth.HashKey("secret"), Scopes: []string{auth.ScopeAdmin}}}, cfg.Auth.APIKeys())
+
+	_, _, err = Load("api", nil, lookupEnv(map[string]string{"AUTH_KEYS_FILE": badFile}))
+	require.ErrorContains(t, err, "auth.keys[0]: hash must be a hex encoded sha256 digest")
+}
+
+func TestLoad_HMACClientsFile(t *testing.T) {
+	clientsFile := filepath.Join(t.TempDir(), "clients.yaml")
+	require.NoError(t, os.WriteFile(clientsFile, []byte("clients:\n  - id: erp\n    secret: 0123456789abcdef0123456789abcdef\n    scopes: [packets:calculate]\n"), 0o600))
+
+	cfg, _, err := Load("api", []string{"-hmac-clients-file", clientsFile, "-hmac-window", "1m"}, lookupEnv(nil))
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, cfg.Auth.HMAC.Window)
+	require.Equal(t, []auth.HMACClient{{ID: "erp", Secret: "0123456789abcdef0123456789abcdef", Scopes: []string{auth.ScopePacketsCalculate}}}, cfg.Auth.HMAC.Clients)
+
+	var buf bytes.Buffer
+	require.NoError(t, cfg.Print(&buf))
+	require.NotContains(t, buf.String(), "0123456789abcdef", "secrets are never printed")
+
+	_, _, err = Load("api", nil, lookupEnv(map[string]string{"HMAC_WINDOW": "0s"}))
+	require.ErrorContains(t, err, "auth.hmac.window: must be more than 0")
+}
+
+func TestLoad_PolicyFile(t *testing.T) {
+	dir := t.TempDir()
+	policyFile := filepath.Join(dir, "policy.yaml")
+	require.NoError(t, os.WriteFile(policyFile, []byte("roles:\n  warehouse:\n    permissions: [packets:calculate]\nbindings:\n  - role: warehouse\n    subjects: [\"api_key:picker\"]\n"), 0o600))
+	badFile := filepath.Join(dir, "bad.yaml")
+	require.NoError(t, os.WriteFile(badFile, []byte("bindings:\n  - role: warehouse\n    subjects: [\"api_key:picker\"]\n"), 0o600))
+
+	cfg, _, err := Load("api", []string{"-auth-policy-file", policyFile}, lookupEnv(nil))
+	require.NoError(t, err)
+	require.Equal(t, &auth.Policy{
+		Roles:    map[string]auth.Role{"warehouse": {Permissions: []string{auth.ScopePacketsCalculate}}},
+		Bindings: []auth.Binding{{Role: "warehouse", Subjects: []string{"api_key:picker"}}},
+	}, cfg.Auth.Policy)
+
+	_, _, err = Load("api", nil, lookupEnv(map[string]string{"AUTH_POLICY_FILE": badFile}))
+	require.ErrorContains(t, err, `bindings[0]: unknown role "warehouse"`)
+}
+
+func TestLoad_LimiterFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+limiter:
+  rps: 5
+  keys:
+    erp: {rps: 50, burst: 100}
+  routes:
+    - method: POST
+      path: /api/v1/packets*
+      rps: 1
+      burst: 0
+`), 0o600))
+
+	_, _, err := Load("api", []string{"-config", file}, lookupEnv(nil))
+	require.ErrorContains(t, err, "limiter.keys.erp: must be the ID of an API key")
+	require.ErrorContains(t, err, "limiter.routes[0].burst: must be more than 0")
+}
+
+func TestRouteLimit_Match(t *testing.T) {
+	route := RouteLimit{Method: "POST", Path: "/api/v1/packets*"}
+	require.True(t, route.Match("post", "/api/v1/packets"))
+	require.True(t, route.Match("POST", "/api/v1/packets/plan"))
+	require.False(t, route.Match("GET", "/api/v1/packets"))
+	require.False(t, route.Match("POST", "/api/v1/sizes"))
+
+	exact := RouteLimit{Path: "/api/v1/sizes"}
+	require.True(t, exact.Match("PUT", "/api/v1/sizes"))
+	require.False(t, exact.Match("PUT", "/api/v1/sizes/export"))
+}
+
+func TestCORS_AllowsOrigin(t *testing.T) {
+	cors := CORS{AllowedOrigins: []string{"https://shop.example", "https://*.gymshark.example", "http://localhost:*"}}
+
+	require.True(t, cors.AllowsOrigin("https://shop.example"))
+	require.False(t, cors.AllowsOrigin("http://shop.example"))
+	require.True(t, cors.AllowsOrigin("https://uk.gymshark.example"))
+	require.False(t, cors.AllowsOrigin("https://.gymshark.example"))
+	require.False(t, cors.AllowsOrigin("https://gymshark.example"))
+	require.False(t, cors.AllowsOrigin("https://gymshark.example.evil"))
+	require.True(t, cors.AllowsOrigin("http://localhost:3000"))
+	require.False(t, cors.AnyOrigin())
+}
+
+func TestConfig_Print(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Default().Print(&buf))
+	require.Contains(t, buf.String(), "read_timeout: 10s\n")
+
+	cfg := Default()
+	require.NoError(t, decodeFile(buf.Bytes(), &cfg))
+	require.Equal(t, Default(), cfg)
+}
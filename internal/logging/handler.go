@@ -0,0 +1,82 @@
+// Package logging holds the slog handler of the API, which records the
+// request scoped values carried by the context of every entry.
+package logging
+
+import (
+	"context"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"golang.org/x/exp/slog"
+)
+
+// Request holds the request scoped values of the entries logged while serving
+// a request.
+type Request struct {
+	ID     string
+	Method string
+	// Route is the pattern of the matched route, set once the request is
+	// routed.
+	Route  string
+	Client string
+}
+
+type contextKey struct{}
+
+// WithRequest returns a copy of the context carrying the request. The request
+// is shared, so that the route may be filled in once it is matched.
+func WithRequest(ctx context.Context, req *Request) context.Context {
+	return context.WithValue(ctx, contextKey{}, req)
+}
+
+// RequestFrom returns the request carried by the context, if any.
+func RequestFrom(ctx context.Context) (*Request, bool) {
+	req, ok := ctx.Value(contextKey{}).(*Request)
+	return req, ok
+}
+
+// ContextHandler adds the request and the actor of the context to the entries
+// it handles before passing them on.
+type ContextHandler struct {
+	next slog.Handler
+}
+
+// NewContextHandler is a constructor of the ContextHandler wrapping the handler.
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{next: next}
+}
+
+// Enabled implements the slog.Handler interface.
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements the slog.Handler interface.
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if req, ok := RequestFrom(ctx); ok {
+		r.AddAttrs(
+			slog.String("request_id", req.ID),
+			slog.String("method", req.Method),
+		)
+		if req.Route != "" {
+			r.AddAttrs(slog.String("route", req.Route))
+		}
+		r.AddAttrs(slog.String("client", req.Client))
+	}
+	if principal, ok := auth.PrincipalFrom(ctx); ok {
+		r.AddAttrs(
+			slog.String("actor", principal.ID),
+			slog.String("auth_method", principal.Method),
+		)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements the slog.Handler interface.
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup implements the slog.Handler interface.
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestContextHandler_Handle(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewTextHandler(&buf, nil))).With("component", "test")
+
+	logger.InfoContext(context.Background(), "anonymous")
+	require.NotContains(t, buf.String(), "actor=")
+
+	buf.Reset()
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{ID: "jane", Method: auth.MethodJWT})
+	logger.InfoContext(ctx, "authenticated")
+	require.Contains(t, buf.String(), "component=test actor=jane auth_method=jwt")
+}
+
+func TestContextHandler_Handle_Request(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewTextHandler(&buf, nil)))
+
+	req := &Request{ID: "req-1", Method: "POST", Client: "203.0.113.7"}
+	ctx := WithRequest(context.Background(), req)
+	logger.ErrorContext(ctx, "unrouted")
+	require.Contains(t, buf.String(), "request_id=req-1 method=POST client=203.0.113.7")
+	require.NotContains(t, buf.String(), "route=")
+
+	buf.Reset()
+	req.Route = "/api/v1/sizes/:size"
+	ctx = auth.WithPrincipal(ctx, auth.Principal{ID: "jane", Method: auth.MethodAPIKey})
+	logger.ErrorContext(ctx, "routed")
+	require.Contains(t, buf.String(), "request_id=req-1 method=POST route=/api/v1/sizes/:size client=203.0.113.7 actor=jane")
+}
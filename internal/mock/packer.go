@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	packer "github.com/SkNuwanTissera/gymshark/internal/packer"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -107,6 +108,102 @@ func (mr *MockSizerMockRecorder) PutSizes(ctx, sizesToPut interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutSizes", reflect.TypeOf((*MockSizer)(nil).PutSizes), ctx, sizesToPut)
 }
 
+// MockProfiler is a mock of Profiler interface.
+type MockProfiler struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfilerMockRecorder
+}
+
+// MockProfilerMockRecorder is the mock recorder for MockProfiler.
+type MockProfilerMockRecorder struct {
+	mock *MockProfiler
+}
+
+// NewMockProfiler creates a new mock instance.
+func NewMockProfiler(ctrl *gomock.Controller) *MockProfiler {
+	mock := &MockProfiler{ctrl: ctrl}
+	mock.recorder = &MockProfilerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfiler) EXPECT() *MockProfilerMockRecorder {
+	return m.recorder
+}
+
+// CreateProfile mocks base method.
+func (m *MockProfiler) CreateProfile(ctx context.Context, profile packer.Profile) (packer.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProfile", ctx, profile)
+	ret0, _ := ret[0].(packer.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProfile indicates an expected call of CreateProfile.
+func (mr *MockProfilerMockRecorder) CreateProfile(ctx, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProfile", reflect.TypeOf((*MockProfiler)(nil).CreateProfile), ctx, profile)
+}
+
+// DeleteProfile mocks base method.
+func (m *MockProfiler) DeleteProfile(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProfile", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProfile indicates an expected call of DeleteProfile.
+func (mr *MockProfilerMockRecorder) DeleteProfile(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProfile", reflect.TypeOf((*MockProfiler)(nil).DeleteProfile), ctx, id)
+}
+
+// GetProfile mocks base method.
+func (m *MockProfiler) GetProfile(id string) (packer.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", id)
+	ret0, _ := ret[0].(packer.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockProfilerMockRecorder) GetProfile(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockProfiler)(nil).GetProfile), id)
+}
+
+// ListProfiles mocks base method.
+func (m *MockProfiler) ListProfiles() []packer.Profile {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProfiles")
+	ret0, _ := ret[0].([]packer.Profile)
+	return ret0
+}
+
+// ListProfiles indicates an expected call of ListProfiles.
+func (mr *MockProfilerMockRecorder) ListProfiles() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProfiles", reflect.TypeOf((*MockProfiler)(nil).ListProfiles))
+}
+
+// UpdateProfile mocks base method.
+func (m *MockProfiler) UpdateProfile(ctx context.Context, profile packer.Profile) (packer.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, profile)
+	ret0, _ := ret[0].(packer.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockProfilerMockRecorder) UpdateProfile(ctx, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockProfiler)(nil).UpdateProfile), ctx, profile)
+}
+
 // MockPacker is a mock of Packer interface.
 type MockPacker struct {
 	ctrl     *gomock.Controller
@@ -130,17 +227,177 @@ func (m *MockPacker) EXPECT() *MockPackerMockRecorder {
 	return m.recorder
 }
 
+// CreateQuote mocks base method.
+func (m *MockPacker) CreateQuote(ctx context.Context, itemsToPack int, opts ...packer.PacketsOption) (packer.Quote, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, itemsToPack}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateQuote", varargs...)
+	ret0, _ := ret[0].(packer.Quote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateQuote indicates an expected call of CreateQuote.
+func (mr *MockPackerMockRecorder) CreateQuote(ctx, itemsToPack interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, itemsToPack}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuote", reflect.TypeOf((*MockPacker)(nil).CreateQuote), varargs...)
+}
+
+// ExplainPackets mocks base method.
+func (m *MockPacker) ExplainPackets(ctx context.Context, itemsToPack int, opts ...packer.PacketsOption) (packer.Explanation, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, itemsToPack}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExplainPackets", varargs...)
+	ret0, _ := ret[0].(packer.Explanation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExplainPackets indicates an expected call of ExplainPackets.
+func (mr *MockPackerMockRecorder) ExplainPackets(ctx, itemsToPack interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, itemsToPack}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExplainPackets", reflect.TypeOf((*MockPacker)(nil).ExplainPackets), varargs...)
+}
+
+// GetBackorderPackets mocks base method.
+func (m *MockPacker) GetBackorderPackets(ctx context.Context, itemsToPack int, opts ...packer.PacketsOption) (packer.BackorderPlan, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, itemsToPack}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetBackorderPackets", varargs...)
+	ret0, _ := ret[0].(packer.BackorderPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBackorderPackets indicates an expected call of GetBackorderPackets.
+func (mr *MockPackerMockRecorder) GetBackorderPackets(ctx, itemsToPack interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, itemsToPack}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBackorderPackets", reflect.TypeOf((*MockPacker)(nil).GetBackorderPackets), varargs...)
+}
+
+// GetBundlePackets mocks base method.
+func (m *MockPacker) GetBundlePackets(ctx context.Context, demand map[string]int) (packer.BundlePlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBundlePackets", ctx, demand)
+	ret0, _ := ret[0].(packer.BundlePlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBundlePackets indicates an expected call of GetBundlePackets.
+func (mr *MockPackerMockRecorder) GetBundlePackets(ctx, demand interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBundlePackets", reflect.TypeOf((*MockPacker)(nil).GetBundlePackets), ctx, demand)
+}
+
+// GetContainerPlan mocks base method.
+func (m *MockPacker) GetContainerPlan(ctx context.Context, itemsToPack int, spec packer.HierarchySpec, opts ...packer.PacketsOption) (packer.ContainerPlan, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, itemsToPack, spec}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetContainerPlan", varargs...)
+	ret0, _ := ret[0].(packer.ContainerPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetContainerPlan indicates an expected call of GetContainerPlan.
+func (mr *MockPackerMockRecorder) GetContainerPlan(ctx, itemsToPack, spec interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, itemsToPack, spec}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerPlan", reflect.TypeOf((*MockPacker)(nil).GetContainerPlan), varargs...)
+}
+
+// GetFulfilmentPlan mocks base method.
+func (m *MockPacker) GetFulfilmentPlan(ctx context.Context, itemsToPack int, warehouses []packer.Warehouse, opts ...packer.PacketsOption) (packer.FulfilmentPlan, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, itemsToPack, warehouses}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetFulfilmentPlan", varargs...)
+	ret0, _ := ret[0].(packer.FulfilmentPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFulfilmentPlan indicates an expected call of GetFulfilmentPlan.
+func (mr *MockPackerMockRecorder) GetFulfilmentPlan(ctx, itemsToPack, warehouses interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, itemsToPack, warehouses}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFulfilmentPlan", reflect.TypeOf((*MockPacker)(nil).GetFulfilmentPlan), varargs...)
+}
+
+// GetOrderPackets mocks base method.
+func (m *MockPacker) GetOrderPackets(ctx context.Context, lines []packer.OrderLine, opts ...packer.PacketsOption) (packer.OrderPlan, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, lines}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetOrderPackets", varargs...)
+	ret0, _ := ret[0].(packer.OrderPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderPackets indicates an expected call of GetOrderPackets.
+func (mr *MockPackerMockRecorder) GetOrderPackets(ctx, lines interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, lines}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderPackets", reflect.TypeOf((*MockPacker)(nil).GetOrderPackets), varargs...)
+}
+
 // GetPackets mocks base method.
-func (m *MockPacker) GetPackets(ctx context.Context, itemsToPack int) (map[int]int, error) {
+func (m *MockPacker) GetPackets(ctx context.Context, itemsToPack int, opts ...packer.PacketsOption) (map[int]int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPackets", ctx, itemsToPack)
+	varargs := []interface{}{ctx, itemsToPack}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetPackets", varargs...)
 	ret0, _ := ret[0].(map[int]int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPackets indicates an expected call of GetPackets.
-func (mr *MockPackerMockRecorder) GetPackets(ctx, itemsToPack interface{}) *gomock.Call {
+func (mr *MockPackerMockRecorder) GetPackets(ctx, itemsToPack interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, itemsToPack}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPackets", reflect.TypeOf((*MockPacker)(nil).GetPackets), varargs...)
+}
+
+// GetUnitPackets mocks base method.
+func (m *MockPacker) GetUnitPackets(ctx context.Context, quantity packer.Quantity, opts ...packer.PacketsOption) (packer.UnitPackets, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, quantity}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetUnitPackets", varargs...)
+	ret0, _ := ret[0].(packer.UnitPackets)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnitPackets indicates an expected call of GetUnitPackets.
+func (mr *MockPackerMockRecorder) GetUnitPackets(ctx, quantity interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPackets", reflect.TypeOf((*MockPacker)(nil).GetPackets), ctx, itemsToPack)
+	varargs := append([]interface{}{ctx, quantity}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnitPackets", reflect.TypeOf((*MockPacker)(nil).GetUnitPackets), varargs...)
 }
@@ -0,0 +1,75 @@
+package packer
+
+import (
+	"context"
+
+	"golang.org/x/exp/slices"
+)
+
+// Shipment is a part of an order shipped at once. Items are the ordered items
+// the shipment covers, Total the items in its packs.
+type Shipment struct {
+	Items   int         `json:"items"`
+	Total   int         `json:"total"`
+	Packets map[int]int `json:"packets"`
+}
+
+// BackorderPlan splits an order into what ships now from stock and a
+// backorder for the remainder.
+type BackorderPlan struct {
+	CatalogID string   `json:"catalog"`
+	Items     int      `json:"items"`
+	ShipNow   Shipment `json:"ship_now"`
+	Backorder Shipment `json:"backorder"`
+}
+
+// GetBackorderPackets packs the items from the available stock of the catalog.
+// When the stock cannot cover as many items as the unconstrained packing, as
+// many items as possible ship now without exceeding the order and the
+// remainder is packed optimally as a backorder regardless of stock.
+func (packets PacketsService) GetBackorderPackets(ctx context.Context, itemsToPack int, opts ...PacketsOption) (BackorderPlan, error) {
+	ideal, err := packets.pack(ctx, itemsToPack, opts)
+	if err != nil {
+		return BackorderPlan{}, err
+	}
+
+	available := packets.Stock.Available(ideal.catalogID)
+	plan := BackorderPlan{
+		CatalogID: ideal.catalogID,
+		Items:     itemsToPack,
+		ShipNow:   Shipment{Packets: map[int]int{}},
+		Backorder: Shipment{Packets: map[int]int{}},
+	}
+
+	covered := func(total int) int {
+		if total > itemsToPack {
+			return itemsToPack
+		}
+		return total
+	}
+
+	// The stock often cannot cover the order, so the packings from stock are
+	// probes.
+	full, err := packets.pack(ctx, itemsToPack, append(slices.Clone(opts), withStock(available), asProbe()))
+	if err == nil && covered(full.best.Total) >= covered(ideal.best.Total) {
+		plan.ShipNow = Shipment{Items: covered(full.best.Total), Total: full.best.Total, Packets: full.best.Packs}
+		return plan, nil
+	}
+
+	now, err := packets.pack(ctx, itemsToPack, append(slices.Clone(opts), withStock(available), withRounding(RoundingDown), asProbe()))
+	if err == nil {
+		plan.ShipNow = Shipment{Items: now.best.Total, Total: now.best.Total, Packets: now.best.Packs}
+	}
+
+	remainder := itemsToPack - plan.ShipNow.Items
+	if remainder == 0 {
+		return plan, nil
+	}
+	later, err := packets.pack(ctx, remainder, opts)
+	if err != nil {
+		return BackorderPlan{}, err
+	}
+	plan.Backorder = Shipment{Items: remainder, Total: later.best.Total, Packets: later.best.Packs}
+
+	return plan, nil
+}
@@ -0,0 +1,112 @@
+package packer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketsService_GetBackorderPackets(t *testing.T) {
+	testCases := []struct {
+		name     string
+		stock    map[int]int
+		items    int
+		wantPlan BackorderPlan
+	}{
+		{
+			name:  "OK stock covers the optimal packing",
+			stock: map[int]int{250: 10, 500: 10},
+			items: 501,
+			wantPlan: BackorderPlan{
+				CatalogID: DefaultCatalogID,
+				Items:     501,
+				ShipNow:   Shipment{Items: 501, Total: 750, Packets: map[int]int{250: 1, 500: 1}},
+				Backorder: Shipment{Packets: map[int]int{}},
+			},
+		},
+		{
+			name:  "OK stock covers the order with other packs",
+			stock: map[int]int{1000: 1},
+			items: 750,
+			wantPlan: BackorderPlan{
+				CatalogID: DefaultCatalogID,
+				Items:     750,
+				ShipNow:   Shipment{Items: 750, Total: 1000, Packets: map[int]int{1000: 1}},
+				Backorder: Shipment{Packets: map[int]int{}},
+			},
+		},
+		{
+			name:  "OK split into ship now and backorder",
+			stock: map[int]int{250: 1},
+			items: 501,
+			wantPlan: BackorderPlan{
+				CatalogID: DefaultCatalogID,
+				Items:     501,
+				ShipNow:   Shipment{Items: 250, Total: 250, Packets: map[int]int{250: 1}},
+				Backorder: Shipment{Items: 251, Total: 500, Packets: map[int]int{500: 1}},
+			},
+		},
+		{
+			name:  "OK large order within stock",
+			stock: map[int]int{5000: 1000},
+			items: 2_000_000,
+			wantPlan: BackorderPlan{
+				CatalogID: DefaultCatalogID,
+				Items:     2_000_000,
+				ShipNow:   Shipment{Items: 2_000_000, Total: 2_000_000, Packets: map[int]int{5000: 400}},
+				Backorder: Shipment{Packets: map[int]int{}},
+			},
+		},
+		{
+			name:  "OK large order over stock",
+			stock: map[int]int{5000: 300},
+			items: 2_000_000,
+			wantPlan: BackorderPlan{
+				CatalogID: DefaultCatalogID,
+				Items:     2_000_000,
+				ShipNow:   Shipment{Items: 1_500_000, Total: 1_500_000, Packets: map[int]int{5000: 300}},
+				Backorder: Shipment{Items: 500_000, Total: 500_000, Packets: map[int]int{5000: 100}},
+			},
+		},
+		{
+			name:  "OK nothing in stock",
+			stock: map[int]int{},
+			items: 251,
+			wantPlan: BackorderPlan{
+				CatalogID: DefaultCatalogID,
+				Items:     251,
+				ShipNow:   Shipment{Packets: map[int]int{}},
+				Backorder: Shipment{Items: 251, Total: 500, Packets: map[int]int{500: 1}},
+			},
+		},
+	}
+
+	for index := range testCases {
+		tc := testCases[index]
+		t.Run(tc.name, func(t *testing.T) {
+			packer := newPacker()
+			require.NoError(t, packer.Stock.SetStock(context.Background(), DefaultCatalogID, tc.stock))
+
+			plan, err := packer.GetBackorderPackets(context.Background(), tc.items)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPlan, plan)
+		})
+	}
+}
+
+func TestPacketsService_GetBackorderPacketsHeldStock(t *testing.T) {
+	ctx := context.Background()
+	packer := newPacker()
+	require.NoError(t, packer.Stock.SetStock(ctx, DefaultCatalogID, map[int]int{500: 1}))
+	_, err := packer.Stock.Reserve(ctx, DefaultCatalogID, map[int]int{500: 1}, DefaultReservationTTL)
+	require.NoError(t, err)
+
+	plan, err := packer.GetBackorderPackets(ctx, 500)
+	require.NoError(t, err)
+	require.Equal(t, 0, plan.ShipNow.Items)
+	require.Equal(t, map[int]int{500: 1}, plan.Backorder.Packets)
+
+	_, err = packer.GetBackorderPackets(ctx, 500, WithCatalog("unknown"))
+	require.EqualError(t, err, ErrorCatalogNotFound)
+}
@@ -0,0 +1,359 @@
+package packer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
+)
+
+// maxBundleCombinations caps the number of bundle combinations the solver tries.
+const maxBundleCombinations = 100_000
+
+// maxBundleWork caps the solver table cells filled over all the residuals of
+// a search, as a single combination may cost as much as a large order.
+const maxBundleWork = 100_000_000
+
+// ERR consts ...
+const (
+	ErrorEmptyBundles        = "bundles must be more than 0 in quantity"
+	ErrorDuplicatedBundles   = "bundle ids must be unique"
+	ErrorEmptyBundleContents = "bundle contents must hold at least one sku with a positive quantity"
+	ErrorDuplicatedSKUPacks  = "single sku packs must not repeat the same sku and size"
+	ErrorEmptyDemand         = "demand must hold at least one sku with a positive quantity"
+	ErrorUncoverableDemand   = "demand cannot be covered with the bundles catalog"
+	ErrorTooManyCombinations = "demand needs too many bundle combinations to be solved"
+	ErrorTooMuchBundleWork   = "demand is too large to be covered with the bundles catalog"
+)
+
+// BundlePack is a catalog entry holding one or more SKUs. A pack with a single
+// SKU is a plain pack of that SKU.
+type BundlePack struct {
+	ID       string         `json:"id"`
+	Contents map[string]int `json:"contents"`
+}
+
+// SKUCoverage shows how the demand of a single SKU is covered.
+type SKUCoverage struct {
+	SKU       string `json:"sku"`
+	Demand    int    `json:"demand"`
+	Shipped   int    `json:"shipped"`
+	Overshoot int    `json:"overshoot"`
+}
+
+// BundlePlan is the packing of a multi-SKU demand.
+type BundlePlan struct {
+	Packs          map[string]int `json:"packs"`
+	Coverage       []SKUCoverage  `json:"coverage"`
+	TotalPacks     int            `json:"total_packs"`
+	TotalOvershoot int            `json:"total_overshoot"`
+}
+
+// BundleService holds the catalog of bundle and single SKU packs.
+type BundleService struct {
+	mu    sync.RWMutex
+	packs []BundlePack
+}
+
+// NewBundleService is a constructor of the BundleService.
+func NewBundleService() *BundleService {
+	return &BundleService{}
+}
+
+// ListBundles returns the packs sorted by ID.
+func (bundles *BundleService) ListBundles() []BundlePack {
+	bundles.mu.RLock()
+	defer bundles.mu.RUnlock()
+
+	return slices.Clone(bundles.packs)
+}
+
+// PutBundles replaces the catalog of bundle and single SKU packs.
+func (bundles *BundleService) PutBundles(ctx context.Context, packs []BundlePack) ([]BundlePack, error) {
+	if len(packs) == 0 {
+		return []BundlePack{}, errors.New(ErrorEmptyBundles)
+	}
+
+	ids := make(map[string]bool)
+	singles := make(map[string]bool)
+	for _, pack := range packs {
+		if ids[pack.ID] {
+			slog.ErrorContext(ctx,
+				ErrorDuplicatedBundles,
+				slog.Any("incoming_bundle", pack.ID),
+			)
+			return []BundlePack{}, errors.New(ErrorDuplicatedBundles)
+		}
+		ids[pack.ID] = true
+
+		if len(pack.Contents) == 0 {
+			return []BundlePack{}, errors.New(ErrorEmptyBundleContents)
+		}
+		for _, quantity := range pack.Contents {
+			if quantity <= 0 {
+				return []BundlePack{}, errors.New(ErrorEmptyBundleContents)
+			}
+		}
+
+		if len(pack.Contents) == 1 {
+			for sku, quantity := range pack.Contents {
+				key := fmt.Sprintf("%s/%d", sku, quantity)
+				if singles[key] {
+					return []BundlePack{}, errors.New(ErrorDuplicatedSKUPacks)
+				}
+				singles[key] = true
+			}
+		}
+	}
+
+	packs = slices.Clone(packs)
+	slices.SortFunc(packs, func(a, b BundlePack) int {
+		switch {
+		case a.ID < b.ID:
+			return -1
+		case a.ID > b.ID:
+			return 1
+		}
+		return 0
+	})
+
+	bundles.mu.Lock()
+	defer bundles.mu.Unlock()
+	bundles.packs = packs
+
+	return slices.Clone(packs), nil
+}
+
+// GetBundlePackets covers the demand per SKU with bundle and single SKU packs,
+// minimising the overshoot summed over the SKUs first and the number of packs second.
+func (packets PacketsService) GetBundlePackets(ctx context.Context, demand map[string]int) (BundlePlan, error) {
+	demanded := false
+	for _, quantity := range demand {
+		if quantity < 0 {
+			return BundlePlan{}, errors.New(ErrorEmptyDemand)
+		}
+		if quantity > MaxItems {
+			return BundlePlan{}, errors.New(ErrorTooManyItems)
+		}
+		if quantity > 0 {
+			demanded = true
+		}
+	}
+	if !demanded {
+		slog.ErrorContext(ctx, ErrorEmptyDemand)
+		return BundlePlan{}, errors.New(ErrorEmptyDemand)
+	}
+
+	plan, err := solveBundles(ctx, packets.Bundles.ListBundles(), demand)
+	if err != nil {
+		slog.ErrorContext(ctx,
+			err.Error(),
+			slog.Any("demand", demand),
+		)
+		return BundlePlan{}, err
+	}
+
+	return plan, nil
+}
+
+// bundleSearch holds the state of the search over bundle combinations.
+type bundleSearch struct {
+	demand  map[string]int
+	bundles []BundlePack
+	bounds  []int
+	singles map[string][]int
+	ids     map[string]map[int]string
+	cache   map[string]map[int]*solution
+
+	counts    []int
+	tries     int
+	work      int
+	best      []int
+	bestSolve map[string]*solution
+	bestScore [2]int
+}
+
+// solveBundles enumerates the bundle counts and covers the residual demand of
+// every SKU with its single SKU packs using the regular solver. It gives up
+// when the context is done.
+func solveBundles(ctx context.Context, packs []BundlePack, demand map[string]int) (BundlePlan, error) {
+	search := &bundleSearch{
+		demand:    demand,
+		singles:   make(map[string][]int),
+		ids:       make(map[string]map[int]string),
+		cache:     make(map[string]map[int]*solution),
+		bestScore: [2]int{-1, -1},
+	}
+	for _, pack := range packs {
+		if len(pack.Contents) == 1 {
+			for sku, quantity := range pack.Contents {
+				search.singles[sku] = append(search.singles[sku], quantity)
+				if search.ids[sku] == nil {
+					search.ids[sku] = make(map[int]string)
+				}
+				search.ids[sku][quantity] = pack.ID
+			}
+			continue
+		}
+
+		bound := 0
+		for sku, quantity := range pack.Contents {
+			if needed := (demand[sku] + quantity - 1) / quantity; needed > bound {
+				bound = needed
+			}
+		}
+		if bound > 0 {
+			search.bundles = append(search.bundles, pack)
+			search.bounds = append(search.bounds, bound)
+		}
+	}
+	search.counts = make([]int, len(search.bundles))
+
+	if err := search.run(ctx, 0); err != nil {
+		return BundlePlan{}, err
+	}
+	if search.best == nil {
+		return BundlePlan{}, errors.New(ErrorUncoverableDemand)
+	}
+
+	return search.plan(), nil
+}
+
+// run tries every count of the bundle at index i and the bundles after it.
+func (search *bundleSearch) run(ctx context.Context, i int) error {
+	if i == len(search.bundles) {
+		search.tries++
+		if search.tries > maxBundleCombinations {
+			return errors.New(ErrorTooManyCombinations)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return search.evaluate()
+	}
+	for count := 0; count <= search.bounds[i]; count++ {
+		search.counts[i] = count
+		if err := search.run(ctx, i+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evaluate scores the current bundle counts and keeps them if they are the best so far.
+func (search *bundleSearch) evaluate() error {
+	shipped := make(map[string]int)
+	packs := 0
+	for i, count := range search.counts {
+		packs += count
+		for sku, quantity := range search.bundles[i].Contents {
+			shipped[sku] += count * quantity
+		}
+	}
+
+	solved := make(map[string]*solution)
+	for sku, quantity := range search.demand {
+		residual := quantity - shipped[sku]
+		if residual <= 0 {
+			continue
+		}
+		single, err := search.single(sku, residual)
+		if err != nil {
+			return err
+		}
+		if single == nil {
+			return nil
+		}
+		solved[sku] = single
+		shipped[sku] += single.Total
+		packs += single.Count
+	}
+
+	overshoot := 0
+	for sku, quantity := range shipped {
+		overshoot += quantity - search.demand[sku]
+	}
+
+	if search.best == nil || overshoot < search.bestScore[0] ||
+		(overshoot == search.bestScore[0] && packs < search.bestScore[1]) {
+		search.best = slices.Clone(search.counts)
+		search.bestSolve = solved
+		search.bestScore = [2]int{overshoot, packs}
+	}
+	return nil
+}
+
+// single returns the cached single SKU packing for the residual or nil if the
+// SKU has no single packs. It fails once the search has filled more solver
+// cells than maxBundleWork.
+func (search *bundleSearch) single(sku string, residual int) (*solution, error) {
+	if cached, ok := search.cache[sku][residual]; ok {
+		return cached, nil
+	}
+	if search.cache[sku] == nil {
+		search.cache[sku] = make(map[int]*solution)
+	}
+
+	var result *solution
+	if sizes := search.singles[sku]; len(sizes) > 0 {
+		if s, err := newSolver(sizes, residual, Constraints{}, RoundingUp); err == nil {
+			search.work += len(s.sizes) * len(s.packs)
+			if best, err := s.best(RoundingUp); err == nil {
+				result = &best
+			}
+		}
+	}
+	search.cache[sku][residual] = result
+	if search.work > maxBundleWork {
+		return nil, errors.New(ErrorTooMuchBundleWork)
+	}
+	return result, nil
+}
+
+// plan builds the BundlePlan of the best combination.
+func (search *bundleSearch) plan() BundlePlan {
+	plan := BundlePlan{
+		Packs:          make(map[string]int),
+		TotalPacks:     search.bestScore[1],
+		TotalOvershoot: search.bestScore[0],
+	}
+
+	shipped := make(map[string]int)
+	for i, count := range search.best {
+		if count == 0 {
+			continue
+		}
+		plan.Packs[search.bundles[i].ID] += count
+		for sku, quantity := range search.bundles[i].Contents {
+			shipped[sku] += count * quantity
+		}
+	}
+	for sku, single := range search.bestSolve {
+		for size, count := range single.Packs {
+			plan.Packs[search.ids[sku][size]] += count
+		}
+		shipped[sku] += single.Total
+	}
+
+	skus := maps.Keys(shipped)
+	for sku := range search.demand {
+		if _, ok := shipped[sku]; !ok {
+			skus = append(skus, sku)
+		}
+	}
+	slices.Sort(skus)
+	for _, sku := range skus {
+		plan.Coverage = append(plan.Coverage, SKUCoverage{
+			SKU:       sku,
+			Demand:    search.demand[sku],
+			Shipped:   shipped[sku],
+			Overshoot: shipped[sku] - search.demand[sku],
+		})
+	}
+
+	return plan
+}
@@ -0,0 +1,160 @@
+package packer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleService_PutBundles(t *testing.T) {
+	testCases := []struct {
+		name    string
+		bundles []BundlePack
+		wantErr string
+	}{
+		{
+			name: "OK",
+			bundles: []BundlePack{
+				{ID: "shirt-50", Contents: map[string]int{"shirt": 50}},
+				{ID: "kit", Contents: map[string]int{"shirt": 100, "shorts": 100}},
+			},
+		},
+		{
+			name:    "ERR empty",
+			bundles: []BundlePack{},
+			wantErr: ErrorEmptyBundles,
+		},
+		{
+			name: "ERR duplicated ids",
+			bundles: []BundlePack{
+				{ID: "kit", Contents: map[string]int{"shirt": 1}},
+				{ID: "kit", Contents: map[string]int{"shorts": 1}},
+			},
+			wantErr: ErrorDuplicatedBundles,
+		},
+		{
+			name: "ERR empty contents",
+			bundles: []BundlePack{
+				{ID: "kit", Contents: map[string]int{"shirt": 0}},
+			},
+			wantErr: ErrorEmptyBundleContents,
+		},
+		{
+			name: "ERR duplicated single sku packs",
+			bundles: []BundlePack{
+				{ID: "a", Contents: map[string]int{"shirt": 50}},
+				{ID: "b", Contents: map[string]int{"shirt": 50}},
+			},
+			wantErr: ErrorDuplicatedSKUPacks,
+		},
+	}
+
+	for index := range testCases {
+		tc := testCases[index]
+		t.Run(tc.name, func(t *testing.T) {
+			bundles := NewBundleService()
+			list, err := bundles.PutBundles(context.Background(), tc.bundles)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "kit", list[0].ID)
+			require.Equal(t, list, bundles.ListBundles())
+		})
+	}
+}
+
+func TestPacketsService_GetBundlePackets(t *testing.T) {
+	packer := newPacker()
+	_, err := packer.Bundles.PutBundles(context.Background(), []BundlePack{
+		{ID: "kit", Contents: map[string]int{"shirt": 100, "shorts": 100}},
+		{ID: "shirt-50", Contents: map[string]int{"shirt": 50}},
+		{ID: "shorts-250", Contents: map[string]int{"shorts": 250}},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		demand   map[string]int
+		wantPlan BundlePlan
+		wantErr  string
+	}{
+		{
+			name:   "OK exact with bundle and single",
+			demand: map[string]int{"shirt": 150, "shorts": 100},
+			wantPlan: BundlePlan{
+				Packs: map[string]int{"kit": 1, "shirt-50": 1},
+				Coverage: []SKUCoverage{
+					{SKU: "shirt", Demand: 150, Shipped: 150},
+					{SKU: "shorts", Demand: 100, Shipped: 100},
+				},
+				TotalPacks: 2,
+			},
+		},
+		{
+			name:   "OK least overshoot over skus",
+			demand: map[string]int{"shirt": 100, "shorts": 300},
+			wantPlan: BundlePlan{
+				Packs: map[string]int{"kit": 1, "shorts-250": 1},
+				Coverage: []SKUCoverage{
+					{SKU: "shirt", Demand: 100, Shipped: 100},
+					{SKU: "shorts", Demand: 300, Shipped: 350, Overshoot: 50},
+				},
+				TotalPacks:     2,
+				TotalOvershoot: 50,
+			},
+		},
+		{
+			name:    "ERR uncoverable sku",
+			demand:  map[string]int{"hat": 1},
+			wantErr: ErrorUncoverableDemand,
+		},
+		{
+			name:    "ERR empty demand",
+			demand:  map[string]int{"shirt": 0},
+			wantErr: ErrorEmptyDemand,
+		},
+		{
+			name:    "ERR too many items",
+			demand:  map[string]int{"shirt": MaxItems + 1},
+			wantErr: ErrorTooManyItems,
+		},
+	}
+
+	for index := range testCases {
+		tc := testCases[index]
+		t.Run(tc.name, func(t *testing.T) {
+			plan, err := packer.GetBundlePackets(context.Background(), tc.demand)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPlan, plan)
+		})
+	}
+}
+
+func TestPacketsService_GetBundlePackets_LargeDemand(t *testing.T) {
+	packer := newPacker()
+	_, err := packer.Bundles.PutBundles(context.Background(), []BundlePack{
+		{ID: "kit", Contents: map[string]int{"a": 1, "b": 1}},
+		{ID: "a-7", Contents: map[string]int{"a": 7}},
+		{ID: "a-400000", Contents: map[string]int{"a": 400_000}},
+		{ID: "b-1", Contents: map[string]int{"b": 1}},
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = packer.GetBundlePackets(context.Background(), map[string]int{"a": 500_000, "b": 1})
+	require.EqualError(t, err, ErrorTooMuchBundleWork)
+	require.Less(t, time.Since(start), 10*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = packer.GetBundlePackets(ctx, map[string]int{"a": 500_000, "b": 1})
+	require.ErrorIs(t, err, context.Canceled)
+}
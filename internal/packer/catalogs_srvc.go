@@ -0,0 +1,81 @@
+package packer
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
+)
+
+// DefaultCatalogID is the ID of the catalog used when a request names none.
+const DefaultCatalogID = "default"
+
+// ERR consts ...
+const (
+	ErrorCatalogNotFound = "catalog does not exist"
+	ErrorCatalogExists   = "catalog already exists"
+)
+
+// CatalogService keeps the pack size catalogs by ID.
+type CatalogService struct {
+	mu       sync.RWMutex
+	catalogs map[string]*SizerService
+}
+
+// NewCatalogService is a constructor of the CatalogService with the default catalog.
+func NewCatalogService(defaultCatalog *SizerService) *CatalogService {
+	return &CatalogService{
+		catalogs: map[string]*SizerService{
+			DefaultCatalogID: defaultCatalog,
+		},
+	}
+}
+
+// GetCatalog returns the catalog by ID; an empty ID means the default catalog.
+func (catalogs *CatalogService) GetCatalog(id string) (*SizerService, error) {
+	if id == "" {
+		id = DefaultCatalogID
+	}
+
+	catalogs.mu.RLock()
+	defer catalogs.mu.RUnlock()
+
+	catalog, exists := catalogs.catalogs[id]
+	if !exists {
+		return nil, errors.New(ErrorCatalogNotFound)
+	}
+
+	return catalog, nil
+}
+
+// ListCatalogs returns the sorted IDs of all catalogs.
+func (catalogs *CatalogService) ListCatalogs() []string {
+	catalogs.mu.RLock()
+	defer catalogs.mu.RUnlock()
+
+	ids := maps.Keys(catalogs.catalogs)
+	slices.Sort(ids)
+
+	return ids
+}
+
+// AddCatalog registers a new catalog under the ID.
+func (catalogs *CatalogService) AddCatalog(ctx context.Context, id string, catalog *SizerService) error {
+	catalogs.mu.Lock()
+	defer catalogs.mu.Unlock()
+
+	if _, exists := catalogs.catalogs[id]; exists {
+		slog.ErrorContext(ctx,
+			ErrorCatalogExists,
+			slog.Any("incoming_catalog", id),
+		)
+		return errors.New(ErrorCatalogExists)
+	}
+
+	catalogs.catalogs[id] = catalog
+
+	return nil
+}
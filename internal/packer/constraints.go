@@ -0,0 +1,175 @@
+package packer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Constraint names reported by InfeasibleError.
+const (
+	ConstraintMaxTotalPacks = "max_total_packs"
+	ConstraintMaxPerSize    = "max_per_size"
+	ConstraintMinPerSize    = "min_per_size"
+	ConstraintExcludedSizes = "excluded_sizes"
+
+	ConstraintRounding            = "rounding"
+	ConstraintMaxOvershootPercent = "max_overshoot_percent"
+)
+
+// ERR consts ...
+const (
+	ErrorNegativeConstraint = "constraints must not be negative"
+)
+
+// Constraints holds optional per request packing constraints. Zero values mean
+// the constraint is not applied.
+type Constraints struct {
+	MaxTotalPacks int         `json:"max_total_packs"`
+	MaxPerSize    map[int]int `json:"max_per_size"`
+	MinPerSize    map[int]int `json:"min_per_size"`
+	ExcludedSizes []int       `json:"excluded_sizes"`
+}
+
+// InfeasibleError is returned when the solver cannot honour the constraints.
+type InfeasibleError struct {
+	Constraint string
+	Reason     string
+}
+
+// Error implements the error interface.
+func (e *InfeasibleError) Error() string {
+	return fmt.Sprintf("constraint %s cannot be met: %s", e.Constraint, e.Reason)
+}
+
+// newInfeasibleError is a shortcut for building InfeasibleError with a formatted reason.
+func newInfeasibleError(constraint, format string, args ...any) *InfeasibleError {
+	return &InfeasibleError{
+		Constraint: constraint,
+		Reason:     fmt.Sprintf(format, args...),
+	}
+}
+
+// validate checks the constraints are not negative.
+func (c Constraints) validate() error {
+	if c.MaxTotalPacks < 0 {
+		return errors.New(ErrorNegativeConstraint)
+	}
+	for _, quantity := range c.MaxPerSize {
+		if quantity < 0 {
+			return errors.New(ErrorNegativeConstraint)
+		}
+	}
+	for _, quantity := range c.MinPerSize {
+		if quantity < 0 {
+			return errors.New(ErrorNegativeConstraint)
+		}
+	}
+	return nil
+}
+
+// isExcluded reports whether the size is in ExcludedSizes.
+func (c Constraints) isExcluded(size int) bool {
+	for _, excluded := range c.ExcludedSizes {
+		if excluded == size {
+			return true
+		}
+	}
+	return false
+}
+
+// maxFor returns the upper bound for the size and whether one is set.
+func (c Constraints) maxFor(size int) (int, bool) {
+	quantity, ok := c.MaxPerSize[size]
+	return quantity, ok
+}
+
+// withinStock caps the packs of every catalog size at its stock.
+func (c Constraints) withinStock(catalog []int, stock map[int]int) Constraints {
+	maxPerSize := make(map[int]int, len(catalog))
+	for _, size := range catalog {
+		limit := stock[size]
+		if quantity, ok := c.maxFor(size); ok && quantity < limit {
+			limit = quantity
+		}
+		maxPerSize[size] = limit
+	}
+	c.MaxPerSize = maxPerSize
+	return c
+}
+
+// PacketsOption configures a single GetPackets call.
+type PacketsOption func(*packetsOptions)
+
+// packetsOptions holds the settings collected from PacketsOption.
+type packetsOptions struct {
+	constraints Constraints
+	profileID   string
+	catalogID   string
+	stock       map[int]int
+	rounding    string
+	asOf        time.Time
+	probe       bool
+}
+
+// WithConstraints applies packing constraints to the request.
+func WithConstraints(constraints Constraints) PacketsOption {
+	return func(o *packetsOptions) {
+		o.constraints = constraints
+	}
+}
+
+// WithProfile applies the rules of the customer profile to the request.
+func WithProfile(profileID string) PacketsOption {
+	return func(o *packetsOptions) {
+		o.profileID = profileID
+	}
+}
+
+// WithCatalog packs the items with the sizes of the catalog instead of the default one.
+func WithCatalog(catalogID string) PacketsOption {
+	return func(o *packetsOptions) {
+		o.catalogID = catalogID
+	}
+}
+
+// WithAsOf packs the items with the catalog sizes as of the time instead of the current ones.
+func WithAsOf(at time.Time) PacketsOption {
+	return func(o *packetsOptions) {
+		o.asOf = at
+	}
+}
+
+// withStock limits the packs to the stock.
+func withStock(stock map[int]int) PacketsOption {
+	return func(o *packetsOptions) {
+		o.stock = stock
+	}
+}
+
+// withRounding overrides the rounding policy of the profile.
+func withRounding(rounding string) PacketsOption {
+	return func(o *packetsOptions) {
+		o.rounding = rounding
+	}
+}
+
+// asProbe marks the packing as one of several tried, whose failures are
+// expected and so logged at debug level only.
+func asProbe() PacketsOption {
+	return func(o *packetsOptions) {
+		o.probe = true
+	}
+}
+
+// newPacketsOptions collects the options.
+func newPacketsOptions(opts []PacketsOption) packetsOptions {
+	o := packetsOptions{catalogID: DefaultCatalogID}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.catalogID == "" {
+		o.catalogID = DefaultCatalogID
+	}
+	return o
+}
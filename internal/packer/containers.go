@@ -0,0 +1,250 @@
+package packer
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/slices"
+)
+
+// ERR consts ...
+const (
+	ErrorEmptyCapacity    = "container capacity must limit packs, volume or weight"
+	ErrorNegativeCapacity = "container capacity must not be negative"
+	ErrorNoPacketsToPlan  = "packets must contain at least one pack"
+	ErrorTooManyCartons   = "plan must not need more than 10000 cartons"
+)
+
+// MaxCartons caps the cartons of a container plan, as every carton is listed.
+const MaxCartons = 10_000
+
+// Capacity describes how much a container can hold. A zero value of any field
+// means that dimension is not limited, but at least one of them must be set.
+type Capacity struct {
+	MaxUnits  int     `json:"max_units"`
+	MaxVolume float64 `json:"max_volume"`
+	MaxWeight float64 `json:"max_weight"`
+}
+
+// ContainerSpec describes a carton or a pallet. Units are packs for cartons and
+// cartons for pallets. Volume and Weight are the container's own outer volume
+// and tare weight; when Volume is zero the volume of the contents is used.
+type ContainerSpec struct {
+	Capacity Capacity `json:"capacity"`
+	Volume   float64  `json:"volume"`
+	Weight   float64  `json:"weight"`
+}
+
+// PackSpec holds the physical attributes of a single pack of a given size.
+type PackSpec struct {
+	Volume float64 `json:"volume"`
+	Weight float64 `json:"weight"`
+}
+
+// HierarchySpec holds everything needed to nest packs into cartons into pallets.
+type HierarchySpec struct {
+	Carton ContainerSpec    `json:"carton"`
+	Pallet ContainerSpec    `json:"pallet"`
+	Packs  map[int]PackSpec `json:"packs"`
+}
+
+// Carton is a filled shipping carton.
+type Carton struct {
+	Packs  map[int]int `json:"packs"`
+	Units  int         `json:"units"`
+	Volume float64     `json:"volume"`
+	Weight float64     `json:"weight"`
+}
+
+// Pallet is a filled pallet.
+type Pallet struct {
+	Cartons []Carton `json:"cartons"`
+	Volume  float64  `json:"volume"`
+	Weight  float64  `json:"weight"`
+}
+
+// ContainerPlan is the full nested pallet -> carton -> packs plan.
+type ContainerPlan struct {
+	Packets      map[int]int `json:"packets"`
+	Pallets      []Pallet    `json:"pallets"`
+	TotalPallets int         `json:"total_pallets"`
+	TotalCartons int         `json:"total_cartons"`
+	TotalPacks   int         `json:"total_packs"`
+}
+
+// validate checks the capacity has at least one limit and no negative limits.
+func (c Capacity) validate() error {
+	if c.MaxUnits < 0 || c.MaxVolume < 0 || c.MaxWeight < 0 {
+		return errors.New(ErrorNegativeCapacity)
+	}
+	if c.MaxUnits == 0 && c.MaxVolume == 0 && c.MaxWeight == 0 {
+		return errors.New(ErrorEmptyCapacity)
+	}
+	return nil
+}
+
+// room returns how many more items of the given volume and weight a container
+// already holding units/volume/weight can take, up to limit.
+func (c Capacity) room(units int, volume, weight, addVolume, addWeight float64, limit int) int {
+	n := limit
+	if c.MaxUnits > 0 {
+		n = min(n, c.MaxUnits-units)
+	}
+	if c.MaxVolume > 0 && addVolume > 0 {
+		n = min(n, int(min((c.MaxVolume-volume)/addVolume, float64(limit))))
+	}
+	if c.MaxWeight > 0 && addWeight > 0 {
+		n = min(n, int(min((c.MaxWeight-weight)/addWeight, float64(limit))))
+	}
+	if n <= 0 || !c.fitsMany(units, volume, weight, addVolume, addWeight, 1) {
+		return 0
+	}
+	// The division may round up past the limit the sums are checked against.
+	for n > 1 && !c.fitsMany(units, volume, weight, addVolume, addWeight, n) {
+		n--
+	}
+	return n
+}
+
+// fitsMany reports whether a container already holding units/volume/weight
+// can take n more items of the given volume and weight.
+func (c Capacity) fitsMany(units int, volume, weight, addVolume, addWeight float64, n int) bool {
+	if c.MaxUnits > 0 && units+n > c.MaxUnits {
+		return false
+	}
+	if c.MaxVolume > 0 && volume+float64(n)*addVolume > c.MaxVolume {
+		return false
+	}
+	if c.MaxWeight > 0 && weight+float64(n)*addWeight > c.MaxWeight {
+		return false
+	}
+	return true
+}
+
+// fits reports whether a container already holding units/volume/weight can take
+// one more item of the given volume and weight.
+func (c Capacity) fits(units int, volume, weight, addVolume, addWeight float64) bool {
+	if c.MaxUnits > 0 && units+1 > c.MaxUnits {
+		return false
+	}
+	if c.MaxVolume > 0 && volume+addVolume > c.MaxVolume {
+		return false
+	}
+	if c.MaxWeight > 0 && weight+addWeight > c.MaxWeight {
+		return false
+	}
+	return true
+}
+
+// outerVolume returns the volume the container takes up in its parent.
+func (spec ContainerSpec) outerVolume(contentsVolume float64) float64 {
+	if spec.Volume > 0 {
+		return spec.Volume
+	}
+	return contentsVolume
+}
+
+// PlanContainers nests the packets returned by GetPackets into cartons and the
+// cartons onto pallets using first-fit decreasing, largest packs first.
+func PlanContainers(packets map[int]int, spec HierarchySpec) (ContainerPlan, error) {
+	if err := spec.Carton.Capacity.validate(); err != nil {
+		return ContainerPlan{}, fmt.Errorf("carton: %w", err)
+	}
+	if err := spec.Pallet.Capacity.validate(); err != nil {
+		return ContainerPlan{}, fmt.Errorf("pallet: %w", err)
+	}
+
+	sizes := make([]int, 0, len(packets))
+	for size, quantity := range packets {
+		if quantity > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+	if len(sizes) == 0 {
+		return ContainerPlan{}, errors.New(ErrorNoPacketsToPlan)
+	}
+	slices.Sort(sizes)
+	slices.Reverse(sizes)
+
+	// Packs of a size are placed in bulk: the first cartons with room for
+	// them take as many as fit, the rest go into new cartons.
+	var cartons []Carton
+	totalPacks := 0
+	for _, size := range sizes {
+		pack := spec.Packs[size]
+		left := packets[size]
+		perCarton := spec.Carton.Capacity.room(0, 0, 0, pack.Volume, pack.Weight, left)
+		if perCarton == 0 {
+			return ContainerPlan{}, fmt.Errorf("pack of size %d does not fit into an empty carton", size)
+		}
+		for j := range cartons {
+			if left == 0 {
+				break
+			}
+			carton := &cartons[j]
+			taken := spec.Carton.Capacity.room(carton.Units, carton.Volume, carton.Weight, pack.Volume, pack.Weight, left)
+			if taken == 0 {
+				continue
+			}
+			carton.Packs[size] += taken
+			carton.Units += taken
+			carton.Volume += float64(taken) * pack.Volume
+			carton.Weight += float64(taken) * pack.Weight
+			left -= taken
+		}
+		if left > 0 && (left-1)/perCarton+1 > MaxCartons-len(cartons) {
+			return ContainerPlan{}, errors.New(ErrorTooManyCartons)
+		}
+		for left > 0 {
+			taken := min(perCarton, left)
+			cartons = append(cartons, Carton{
+				Packs:  map[int]int{size: taken},
+				Units:  taken,
+				Volume: float64(taken) * pack.Volume,
+				Weight: float64(taken) * pack.Weight,
+			})
+			left -= taken
+		}
+		totalPacks += packets[size]
+	}
+
+	var pallets []Pallet
+	for _, carton := range cartons {
+		volume := spec.Carton.outerVolume(carton.Volume)
+		weight := carton.Weight + spec.Carton.Weight
+		if !spec.Pallet.Capacity.fits(0, 0, 0, volume, weight) {
+			return ContainerPlan{}, errors.New("carton does not fit onto an empty pallet")
+		}
+		placed := false
+		for j := range pallets {
+			pallet := &pallets[j]
+			if spec.Pallet.Capacity.fits(len(pallet.Cartons), pallet.Volume, pallet.Weight, volume, weight) {
+				pallet.Cartons = append(pallet.Cartons, carton)
+				pallet.Volume += volume
+				pallet.Weight += weight
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			pallets = append(pallets, Pallet{
+				Cartons: []Carton{carton},
+				Volume:  volume,
+				Weight:  weight,
+			})
+		}
+	}
+
+	for i := range pallets {
+		pallets[i].Volume = spec.Pallet.outerVolume(pallets[i].Volume)
+		pallets[i].Weight += spec.Pallet.Weight
+	}
+
+	return ContainerPlan{
+		Packets:      packets,
+		Pallets:      pallets,
+		TotalPallets: len(pallets),
+		TotalCartons: len(cartons),
+		TotalPacks:   totalPacks,
+	}, nil
+}
@@ -0,0 +1,138 @@
+package packer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanContainers(t *testing.T) {
+	testCases := []struct {
+		name        string
+		packets     map[int]int
+		spec        HierarchySpec
+		checkResult func(t *testing.T, plan ContainerPlan, err error)
+	}{
+		{
+			name:    "OK capacity in packs",
+			packets: map[int]int{5000: 2, 2000: 1, 250: 1},
+			spec: HierarchySpec{
+				Carton: ContainerSpec{Capacity: Capacity{MaxUnits: 2}},
+				Pallet: ContainerSpec{Capacity: Capacity{MaxUnits: 1}},
+			},
+			checkResult: func(t *testing.T, plan ContainerPlan, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 4, plan.TotalPacks)
+				require.Equal(t, 2, plan.TotalCartons)
+				require.Equal(t, 2, plan.TotalPallets)
+				require.Equal(t, map[int]int{5000: 2}, plan.Pallets[0].Cartons[0].Packs)
+				require.Equal(t, map[int]int{2000: 1, 250: 1}, plan.Pallets[1].Cartons[0].Packs)
+			},
+		},
+		{
+			name:    "OK capacity by volume and weight",
+			packets: map[int]int{500: 3, 250: 2},
+			spec: HierarchySpec{
+				Carton: ContainerSpec{Capacity: Capacity{MaxVolume: 10}, Weight: 1},
+				Pallet: ContainerSpec{Capacity: Capacity{MaxWeight: 100}, Weight: 20},
+				Packs: map[int]PackSpec{
+					500: {Volume: 4, Weight: 8},
+					250: {Volume: 2, Weight: 4},
+				},
+			},
+			checkResult: func(t *testing.T, plan ContainerPlan, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 5, plan.TotalPacks)
+				require.Equal(t, 2, plan.TotalCartons)
+				require.Equal(t, 1, plan.TotalPallets)
+				require.Equal(t, map[int]int{500: 2, 250: 1}, plan.Pallets[0].Cartons[0].Packs)
+				require.Equal(t, map[int]int{500: 1, 250: 1}, plan.Pallets[0].Cartons[1].Packs)
+				require.Equal(t, float64(54), plan.Pallets[0].Weight)
+			},
+		},
+		{
+			name:    "OK many packs",
+			packets: map[int]int{5000: 1_000_000_000, 250: 1},
+			spec: HierarchySpec{
+				Carton: ContainerSpec{Capacity: Capacity{MaxUnits: 200_000}},
+				Pallet: ContainerSpec{Capacity: Capacity{MaxUnits: 4}},
+			},
+			checkResult: func(t *testing.T, plan ContainerPlan, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1_000_000_001, plan.TotalPacks)
+				require.Equal(t, 5001, plan.TotalCartons)
+				require.Equal(t, 1251, plan.TotalPallets)
+				require.Equal(t, map[int]int{250: 1}, plan.Pallets[1250].Cartons[0].Packs)
+			},
+		},
+		{
+			name:    "ERR too many cartons",
+			packets: map[int]int{5000: 1_000_000_000},
+			spec: HierarchySpec{
+				Carton: ContainerSpec{Capacity: Capacity{MaxUnits: 1}},
+				Pallet: ContainerSpec{Capacity: Capacity{MaxUnits: 1}},
+			},
+			checkResult: func(t *testing.T, plan ContainerPlan, err error) {
+				require.EqualError(t, err, ErrorTooManyCartons)
+			},
+		},
+		{
+			name:    "ERR pack does not fit into carton",
+			packets: map[int]int{5000: 1},
+			spec: HierarchySpec{
+				Carton: ContainerSpec{Capacity: Capacity{MaxWeight: 1}},
+				Pallet: ContainerSpec{Capacity: Capacity{MaxUnits: 1}},
+				Packs:  map[int]PackSpec{5000: {Weight: 2}},
+			},
+			checkResult: func(t *testing.T, plan ContainerPlan, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name:    "ERR empty capacity",
+			packets: map[int]int{5000: 1},
+			spec: HierarchySpec{
+				Pallet: ContainerSpec{Capacity: Capacity{MaxUnits: 1}},
+			},
+			checkResult: func(t *testing.T, plan ContainerPlan, err error) {
+				require.ErrorContains(t, err, ErrorEmptyCapacity)
+			},
+		},
+		{
+			name:    "ERR no packets",
+			packets: map[int]int{},
+			spec: HierarchySpec{
+				Carton: ContainerSpec{Capacity: Capacity{MaxUnits: 1}},
+				Pallet: ContainerSpec{Capacity: Capacity{MaxUnits: 1}},
+			},
+			checkResult: func(t *testing.T, plan ContainerPlan, err error) {
+				require.EqualError(t, err, ErrorNoPacketsToPlan)
+			},
+		},
+	}
+
+	for index := range testCases {
+		tc := testCases[index]
+		t.Run(tc.name, func(t *testing.T) {
+			plan, err := PlanContainers(tc.packets, tc.spec)
+			tc.checkResult(t, plan, err)
+		})
+	}
+}
+
+func TestPacketsService_GetContainerPlan(t *testing.T) {
+	packer := newPacker()
+
+	plan, err := packer.GetContainerPlan(context.Background(), 12001, HierarchySpec{
+		Carton: ContainerSpec{Capacity: Capacity{MaxUnits: 3}},
+		Pallet: ContainerSpec{Capacity: Capacity{MaxUnits: 4}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[int]int{5000: 2, 2000: 1, 250: 1}, plan.Packets)
+	require.Equal(t, 2, plan.TotalCartons)
+	require.Equal(t, 1, plan.TotalPallets)
+
+	_, err = packer.GetContainerPlan(context.Background(), 0, HierarchySpec{})
+	require.EqualError(t, err, ErrorNegativeOrZeroItems)
+}
@@ -0,0 +1,145 @@
+package packer
+
+import (
+	"context"
+
+	"golang.org/x/exp/slices"
+)
+
+// Reasons why a candidate lost against the chosen packing.
+const (
+	RejectedBelowOrder       = "below_order"
+	RejectedAboveOrder       = "above_order"
+	RejectedMoreOvershoot    = "more_overshoot"
+	RejectedFewerItems       = "fewer_items"
+	RejectedFurtherFromOrder = "further_from_order"
+	RejectedMaxTotalPacks    = "max_total_packs"
+)
+
+// Objectives of the rounding policies.
+var objectives = map[string]string{
+	RoundingUp:      "cover the order with the fewest items, then with the fewest packs",
+	RoundingDown:    "ship as many items as possible without exceeding the order, then use the fewest packs",
+	RoundingNearest: "ship the number of items closest to the order, covering it on a tie, then use the fewest packs",
+}
+
+// Candidate is a packing the solver considered for the order.
+type Candidate struct {
+	Packets   map[int]int `json:"packets"`
+	Total     int         `json:"total"`
+	Packs     int         `json:"packs"`
+	Overshoot int         `json:"overshoot"`
+	Chosen    bool        `json:"chosen"`
+	Rejected  string      `json:"rejected,omitempty"`
+}
+
+// Explanation is the structured derivation of a packing result.
+type Explanation struct {
+	Items          int         `json:"items"`
+	Objective      string      `json:"objective"`
+	Rounding       string      `json:"rounding"`
+	CatalogID      string      `json:"catalog"`
+	CatalogVersion int         `json:"catalog_version"`
+	CatalogSizes   []int       `json:"catalog_sizes"`
+	AllowedSizes   []int       `json:"allowed_sizes"`
+	ProfileID      string      `json:"profile_id,omitempty"`
+	Constraints    Constraints `json:"constraints"`
+	Packets        map[int]int `json:"packets"`
+	Candidates     []Candidate `json:"candidates"`
+}
+
+// ExplainPackets calculates the packets like GetPackets and returns the derivation
+// of the result: the objective, the candidates considered and why they lost.
+func (packets PacketsService) ExplainPackets(ctx context.Context, itemsToPack int, opts ...PacketsOption) (Explanation, error) {
+	result, err := packets.pack(ctx, itemsToPack, opts)
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	return Explanation{
+		Items:          itemsToPack,
+		Objective:      objectives[result.rounding],
+		Rounding:       result.rounding,
+		CatalogID:      result.catalogID,
+		CatalogVersion: result.catalogVersion,
+		CatalogSizes:   result.catalog,
+		AllowedSizes:   slices.Clone(result.solver.sizes),
+		ProfileID:      result.profileID,
+		Constraints:    result.constraints,
+		Packets:        result.best.Packs,
+		Candidates:     result.solver.candidates(result.best, result.rounding),
+	}, nil
+}
+
+// candidates lists the totals next to the order and the totals needing fewer
+// packs than any smaller covering total, annotated against the chosen solution.
+func (s *solver) candidates(best solution, rounding string) []Candidate {
+	totals := []int{best.Total - s.baseTotal}
+
+	for t := s.target - 1; t >= 0; t-- {
+		if t+s.baseTotal > 0 && s.withinBudget(t) {
+			totals = append(totals, t)
+			break
+		}
+	}
+
+	fewestPacks := int32(unreachable)
+	overBudget := false
+	for t := s.target; t < len(s.packs); t++ {
+		if s.packs[t] == unreachable {
+			continue
+		}
+		if !s.withinBudget(t) {
+			if !overBudget {
+				totals = append(totals, t)
+				overBudget = true
+			}
+			continue
+		}
+		if s.packs[t] < fewestPacks {
+			fewestPacks = s.packs[t]
+			totals = append(totals, t)
+		}
+	}
+
+	slices.Sort(totals)
+	totals = slices.Compact(totals)
+
+	candidates := make([]Candidate, 0, len(totals))
+	for _, t := range totals {
+		option := s.solution(t)
+		candidate := Candidate{
+			Packets:   option.Packs,
+			Total:     option.Total,
+			Packs:     option.Count,
+			Overshoot: option.Total - s.items,
+			Chosen:    option.Total == best.Total,
+		}
+		if !candidate.Chosen {
+			candidate.Rejected = s.rejection(t, option, best, rounding)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates
+}
+
+// rejection returns why the option at the exact total t lost against the best one.
+func (s *solver) rejection(t int, option, best solution, rounding string) string {
+	if !s.withinBudget(t) {
+		return RejectedMaxTotalPacks
+	}
+	switch rounding {
+	case RoundingDown:
+		if option.Total > s.items {
+			return RejectedAboveOrder
+		}
+		return RejectedFewerItems
+	case RoundingNearest:
+		return RejectedFurtherFromOrder
+	}
+	if option.Total < s.items {
+		return RejectedBelowOrder
+	}
+	return RejectedMoreOvershoot
+}
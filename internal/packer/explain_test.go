@@ -0,0 +1,33 @@
+package packer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketsService_ExplainPackets(t *testing.T) {
+	packer := newPacker()
+
+	explanation, err := packer.ExplainPackets(context.Background(), 12001)
+	require.NoError(t, err)
+	require.Equal(t, map[int]int{5000: 2, 2000: 1, 250: 1}, explanation.Packets)
+	require.Equal(t, DefaultCatalogID, explanation.CatalogID)
+	require.Equal(t, 1, explanation.CatalogVersion)
+	require.Equal(t, RoundingUp, explanation.Rounding)
+	require.Equal(t, []Candidate{
+		{Packets: map[int]int{5000: 2, 2000: 1}, Total: 12000, Packs: 3, Overshoot: -1, Rejected: RejectedBelowOrder},
+		{Packets: map[int]int{5000: 2, 2000: 1, 250: 1}, Total: 12250, Packs: 4, Overshoot: 249, Chosen: true},
+		{Packets: map[int]int{5000: 3}, Total: 15000, Packs: 3, Overshoot: 2999, Rejected: RejectedMoreOvershoot},
+	}, explanation.Candidates)
+
+	explanation, err = packer.ExplainPackets(context.Background(), 12001, WithConstraints(Constraints{MaxTotalPacks: 3}))
+	require.NoError(t, err)
+	require.Equal(t, map[int]int{5000: 3}, explanation.Packets)
+	require.Equal(t, RejectedMaxTotalPacks, explanation.Candidates[1].Rejected)
+	require.Equal(t, 12250, explanation.Candidates[1].Total)
+
+	_, err = packer.ExplainPackets(context.Background(), 0)
+	require.EqualError(t, err, ErrorNegativeOrZeroItems)
+}
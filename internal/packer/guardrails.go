@@ -0,0 +1,106 @@
+package packer
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Guardrail limits how many items a packing may ship over the order. A zero
+// value of a field means that limit is not applied; when both are set the
+// stricter one wins.
+type Guardrail struct {
+	MaxOvershoot        int     `json:"max_overshoot"`
+	MaxOvershootPercent float64 `json:"max_overshoot_percent"`
+}
+
+// limit returns the maximum acceptable overshoot for the items and whether any
+// limit is set.
+func (g Guardrail) limit(items int) (int, bool) {
+	limit, limited := 0, false
+	if g.MaxOvershoot > 0 {
+		limit, limited = g.MaxOvershoot, true
+	}
+	if g.MaxOvershootPercent > 0 {
+		percentLimit := int(float64(items) * g.MaxOvershootPercent / 100)
+		if !limited || percentLimit < limit {
+			limit, limited = percentLimit, true
+		}
+	}
+	return limit, limited
+}
+
+// OvershootError is returned when the best packing ships more items over the
+// order than the guardrail allows.
+type OvershootError struct {
+	CatalogID    string
+	Items        int
+	Total        int
+	Overshoot    int
+	MaxOvershoot int
+}
+
+// Error implements the error interface.
+func (e *OvershootError) Error() string {
+	return fmt.Sprintf("overshoot of %d items exceeds the maximum of %d items for catalog %s",
+		e.Overshoot, e.MaxOvershoot, e.CatalogID)
+}
+
+// Guardrails holds the default overshoot guardrail and the per catalog overrides.
+type Guardrails struct {
+	mu       sync.RWMutex
+	fallback Guardrail
+	catalogs map[string]Guardrail
+}
+
+// NewGuardrails is a constructor of the Guardrails with the default guardrail.
+func NewGuardrails(fallback Guardrail) *Guardrails {
+	return &Guardrails{
+		fallback: fallback,
+		catalogs: make(map[string]Guardrail),
+	}
+}
+
+// GetGuardrail returns the guardrail of the catalog, or the default one when
+// the catalog has no override.
+func (guardrails *Guardrails) GetGuardrail(catalogID string) Guardrail {
+	guardrails.mu.RLock()
+	defer guardrails.mu.RUnlock()
+
+	if guardrail, exists := guardrails.catalogs[catalogID]; exists {
+		return guardrail
+	}
+
+	return guardrails.fallback
+}
+
+// SetGuardrail overrides the guardrail of the catalog.
+func (guardrails *Guardrails) SetGuardrail(catalogID string, guardrail Guardrail) {
+	guardrails.mu.Lock()
+	defer guardrails.mu.Unlock()
+
+	guardrails.catalogs[catalogID] = guardrail
+}
+
+// SetDefault replaces the guardrail used by catalogs without an override.
+func (guardrails *Guardrails) SetDefault(guardrail Guardrail) {
+	guardrails.mu.Lock()
+	defer guardrails.mu.Unlock()
+
+	guardrails.fallback = guardrail
+}
+
+// check returns an OvershootError when the total overshoots the catalog guardrail.
+func (guardrails *Guardrails) check(catalogID string, items, total int) error {
+	limit, limited := guardrails.GetGuardrail(catalogID).limit(items)
+	if !limited || total-items <= limit {
+		return nil
+	}
+
+	return &OvershootError{
+		CatalogID:    catalogID,
+		Items:        items,
+		Total:        total,
+		Overshoot:    total - items,
+		MaxOvershoot: limit,
+	}
+}
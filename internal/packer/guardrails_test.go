@@ -0,0 +1,80 @@
+package packer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardrail_limit(t *testing.T) {
+	testCases := []struct {
+		name        string
+		guardrail   Guardrail
+		items       int
+		wantLimit   int
+		wantLimited bool
+	}{
+		{
+			name:        "no limit",
+			guardrail:   Guardrail{},
+			items:       100,
+			wantLimited: false,
+		},
+		{
+			name:        "absolute",
+			guardrail:   Guardrail{MaxOvershoot: 50},
+			items:       100,
+			wantLimit:   50,
+			wantLimited: true,
+		},
+		{
+			name:        "percentage",
+			guardrail:   Guardrail{MaxOvershootPercent: 10},
+			items:       1000,
+			wantLimit:   100,
+			wantLimited: true,
+		},
+		{
+			name:        "stricter of both",
+			guardrail:   Guardrail{MaxOvershoot: 50, MaxOvershootPercent: 10},
+			items:       1000,
+			wantLimit:   50,
+			wantLimited: true,
+		},
+	}
+
+	for index := range testCases {
+		tc := testCases[index]
+		t.Run(tc.name, func(t *testing.T) {
+			limit, limited := tc.guardrail.limit(tc.items)
+			require.Equal(t, tc.wantLimited, limited)
+			require.Equal(t, tc.wantLimit, limit)
+		})
+	}
+}
+
+func TestPacketsService_GetPacketsWithGuardrail(t *testing.T) {
+	packer := newPacker()
+	ctx := context.Background()
+
+	packer.Guardrails.SetDefault(Guardrail{MaxOvershoot: 1000})
+	packets, err := packer.GetPackets(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, map[int]int{250: 1}, packets)
+
+	packer.Guardrails.SetGuardrail(DefaultCatalogID, Guardrail{MaxOvershootPercent: 100})
+	_, err = packer.GetPackets(ctx, 1)
+	var overshootErr *OvershootError
+	require.ErrorAs(t, err, &overshootErr)
+	require.Equal(t, 249, overshootErr.Overshoot)
+	require.Equal(t, 1, overshootErr.MaxOvershoot)
+	require.Equal(t, DefaultCatalogID, overshootErr.CatalogID)
+
+	packets, err = packer.GetPackets(ctx, 12001)
+	require.NoError(t, err)
+	require.Equal(t, map[int]int{5000: 2, 2000: 1, 250: 1}, packets)
+
+	_, err = packer.GetPackets(ctx, 1, WithCatalog("unknown"))
+	require.EqualError(t, err, ErrorCatalogNotFound)
+}
@@ -0,0 +1,218 @@
+package packer
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
+)
+
+// ERR consts ...
+const (
+	ErrorEmptyOrder = "order must contain at least one line"
+)
+
+// OrderLine is a single line item of an order.
+type OrderLine struct {
+	ID      string `json:"id"`
+	Catalog string `json:"catalog"`
+	Items   int    `json:"items"`
+}
+
+// CatalogPacking holds the consolidated packs of all lines sharing a catalog.
+// Packs lists the packs in runs of a size, largest first; PackShare.Pack is
+// the index of a pack counted through the runs.
+type CatalogPacking struct {
+	Catalog string      `json:"catalog"`
+	Items   int         `json:"items"`
+	Total   int         `json:"total"`
+	Packets map[int]int `json:"packets"`
+	Packs   []PackRun   `json:"packs"`
+}
+
+// PackRun is a run of packs of the same size.
+type PackRun struct {
+	Size  int `json:"size"`
+	Count int `json:"count"`
+}
+
+// PackShare is the part of a consolidated pack allocated to a line.
+type PackShare struct {
+	Pack  int `json:"pack"`
+	Size  int `json:"size"`
+	Items int `json:"items"`
+}
+
+// LineAllocation holds the packs allocated back to an order line. Packets are
+// whole packs of the line, Shares are packs split with other lines. Overshoot
+// is the difference between the allocated and the ordered items.
+type LineAllocation struct {
+	ID        string      `json:"id"`
+	Catalog   string      `json:"catalog"`
+	Items     int         `json:"items"`
+	Allocated int         `json:"allocated"`
+	Overshoot int         `json:"overshoot"`
+	Packets   map[int]int `json:"packets"`
+	Shares    []PackShare `json:"shares"`
+}
+
+// OrderPlan is the consolidated packing of an order and its allocation to lines.
+type OrderPlan struct {
+	Catalogs []CatalogPacking `json:"catalogs"`
+	Lines    []LineAllocation `json:"lines"`
+}
+
+// GetOrderPackets packs the summed items of the lines per catalog and allocates
+// the packs back to the lines in their order. Options apply to every catalog.
+func (packets PacketsService) GetOrderPackets(ctx context.Context, lines []OrderLine, opts ...PacketsOption) (OrderPlan, error) {
+	if len(lines) == 0 {
+		slog.ErrorContext(ctx, ErrorEmptyOrder)
+		return OrderPlan{}, errors.New(ErrorEmptyOrder)
+	}
+
+	lines = slices.Clone(lines)
+	itemsByCatalog := make(map[string]int)
+	for i := range lines {
+		if lines[i].Catalog == "" {
+			lines[i].Catalog = DefaultCatalogID
+		}
+		if lines[i].Items <= 0 {
+			slog.ErrorContext(ctx,
+				ErrorNegativeOrZeroItems,
+				"incoming_line", lines[i].ID,
+				"incoming_items", lines[i].Items)
+			return OrderPlan{}, errors.New(ErrorNegativeOrZeroItems)
+		}
+		itemsByCatalog[lines[i].Catalog] += lines[i].Items
+	}
+
+	catalogIDs := maps.Keys(itemsByCatalog)
+	slices.Sort(catalogIDs)
+
+	plan := OrderPlan{
+		Catalogs: make([]CatalogPacking, 0, len(catalogIDs)),
+		Lines:    make([]LineAllocation, len(lines)),
+	}
+	for _, catalogID := range catalogIDs {
+		items := itemsByCatalog[catalogID]
+		necessaryPacks, err := packets.GetPackets(ctx, items, append(slices.Clone(opts), WithCatalog(catalogID))...)
+		if err != nil {
+			return OrderPlan{}, err
+		}
+
+		packs := packRuns(necessaryPacks)
+		total := 0
+		for _, run := range packs {
+			total += run.Size * run.Count
+		}
+		plan.Catalogs = append(plan.Catalogs, CatalogPacking{
+			Catalog: catalogID,
+			Items:   items,
+			Total:   total,
+			Packets: necessaryPacks,
+			Packs:   packs,
+		})
+
+		var indexes []int
+		for i := range lines {
+			if lines[i].Catalog == catalogID {
+				indexes = append(indexes, i)
+			}
+		}
+		for i, allocation := range allocatePacks(packs, lines, indexes) {
+			plan.Lines[indexes[i]] = allocation
+		}
+	}
+
+	return plan, nil
+}
+
+// packRuns lists the packs in runs of a size, largest first.
+func packRuns(necessaryPacks map[int]int) []PackRun {
+	sizes := maps.Keys(necessaryPacks)
+	slices.Sort(sizes)
+	slices.Reverse(sizes)
+
+	var packs []PackRun
+	for _, size := range sizes {
+		if count := necessaryPacks[size]; count > 0 {
+			packs = append(packs, PackRun{Size: size, Count: count})
+		}
+	}
+	return packs
+}
+
+// allocatePacks pours the items of the packs into the lines one after another,
+// so at most one pack is split between two neighbouring lines. Whatever is left
+// in the packs once all lines are filled goes to the last line. Whole packs are
+// allocated a run at a time.
+func allocatePacks(packs []PackRun, lines []OrderLine, indexes []int) []LineAllocation {
+	allocations := make([]LineAllocation, len(indexes))
+	for i, index := range indexes {
+		allocations[i] = LineAllocation{
+			ID:      lines[index].ID,
+			Catalog: lines[index].Catalog,
+			Items:   lines[index].Items,
+			Packets: make(map[int]int),
+		}
+	}
+
+	// pack is the index of the current pack, used the packs of its run taken
+	// before it and left the items still in it.
+	run, pack, used, left := 0, 0, 0, 0
+	if len(packs) > 0 {
+		left = packs[0].Size
+	}
+	next := func(count int) {
+		pack += count
+		used += count
+		if used == packs[run].Count {
+			run, used = run+1, 0
+		}
+		if run < len(packs) {
+			left = packs[run].Size
+		}
+	}
+	for i := range allocations {
+		allocation := &allocations[i]
+		need := allocation.Items
+		last := i == len(allocations)-1
+		for run < len(packs) && (need > 0 || last) {
+			size := packs[run].Size
+			if left == size {
+				whole := packs[run].Count - used
+				if !last {
+					whole = min(whole, need/size)
+				}
+				if whole > 0 {
+					allocation.Packets[size] += whole
+					allocation.Allocated += whole * size
+					need -= whole * size
+					next(whole)
+					continue
+				}
+			}
+
+			taken := left
+			if !last && taken > need {
+				taken = need
+			}
+			allocation.Shares = append(allocation.Shares, PackShare{
+				Pack:  pack,
+				Size:  size,
+				Items: taken,
+			})
+			allocation.Allocated += taken
+			need -= taken
+			left -= taken
+			if left == 0 {
+				next(1)
+			}
+		}
+		allocation.Overshoot = allocation.Allocated - allocation.Items
+	}
+
+	return allocations
+}
@@ -0,0 +1,93 @@
+package packer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketsService_GetOrderPackets(t *testing.T) {
+	packer := newPacker()
+	ctx := context.Background()
+	require.NoError(t, packer.Catalogs.AddCatalog(ctx, "kits", NewSizerService([]int{10, 20})))
+
+	plan, err := packer.GetOrderPackets(ctx, []OrderLine{
+		{ID: "a", Items: 6000},
+		{ID: "b", Catalog: "kits", Items: 15},
+		{ID: "c", Catalog: DefaultCatalogID, Items: 6001},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []CatalogPacking{
+		{
+			Catalog: DefaultCatalogID,
+			Items:   12001,
+			Total:   12250,
+			Packets: map[int]int{5000: 2, 2000: 1, 250: 1},
+			Packs:   []PackRun{{Size: 5000, Count: 2}, {Size: 2000, Count: 1}, {Size: 250, Count: 1}},
+		},
+		{
+			Catalog: "kits",
+			Items:   15,
+			Total:   20,
+			Packets: map[int]int{20: 1},
+			Packs:   []PackRun{{Size: 20, Count: 1}},
+		},
+	}, plan.Catalogs)
+
+	require.Equal(t, []LineAllocation{
+		{
+			ID:        "a",
+			Catalog:   DefaultCatalogID,
+			Items:     6000,
+			Allocated: 6000,
+			Packets:   map[int]int{5000: 1},
+			Shares:    []PackShare{{Pack: 1, Size: 5000, Items: 1000}},
+		},
+		{
+			ID:        "b",
+			Catalog:   "kits",
+			Items:     15,
+			Allocated: 20,
+			Overshoot: 5,
+			Packets:   map[int]int{20: 1},
+		},
+		{
+			ID:        "c",
+			Catalog:   DefaultCatalogID,
+			Items:     6001,
+			Allocated: 6250,
+			Overshoot: 249,
+			Packets:   map[int]int{2000: 1, 250: 1},
+			Shares:    []PackShare{{Pack: 1, Size: 5000, Items: 4000}},
+		},
+	}, plan.Lines)
+
+	_, err = packer.GetOrderPackets(ctx, []OrderLine{{ID: "a", Items: MaxItems}, {ID: "b", Items: 1}})
+	require.EqualError(t, err, ErrorTooManyItems)
+
+	_, err = packer.GetOrderPackets(ctx, nil)
+	require.EqualError(t, err, ErrorEmptyOrder)
+
+	_, err = packer.GetOrderPackets(ctx, []OrderLine{{ID: "a", Catalog: "unknown", Items: 1}})
+	require.EqualError(t, err, ErrorCatalogNotFound)
+}
+
+func Test_allocatePacks(t *testing.T) {
+	lines := []OrderLine{{ID: "a", Items: 100}, {ID: "b", Items: 4900}}
+	allocations := allocatePacks([]PackRun{{Size: 5000, Count: 1}}, lines, []int{0, 1})
+
+	require.Equal(t, []PackShare{{Pack: 0, Size: 5000, Items: 100}}, allocations[0].Shares)
+	require.Equal(t, []PackShare{{Pack: 0, Size: 5000, Items: 4900}}, allocations[1].Shares)
+	require.Equal(t, 0, allocations[1].Overshoot)
+
+	lines = []OrderLine{{ID: "a", Items: 999_996_000}, {ID: "b", Items: 1000}}
+	allocations = allocatePacks([]PackRun{{Size: 5000, Count: 199_999}, {Size: 2000, Count: 2}, {Size: 250, Count: 1}}, lines, []int{0, 1})
+
+	require.Equal(t, map[int]int{5000: 199_999}, allocations[0].Packets)
+	require.Equal(t, []PackShare{{Pack: 199_999, Size: 2000, Items: 1000}}, allocations[0].Shares)
+	require.Equal(t, map[int]int{2000: 1, 250: 1}, allocations[1].Packets)
+	require.Equal(t, []PackShare{{Pack: 199_999, Size: 2000, Items: 1000}}, allocations[1].Shares)
+	require.Equal(t, 3250, allocations[1].Allocated)
+}
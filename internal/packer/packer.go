@@ -11,7 +11,24 @@ type Sizer interface {
 	Exists(sizeToCheckFor int) bool
 }
 
+// Profiler ...
+type Profiler interface {
+	ListProfiles() []Profile
+	GetProfile(id string) (Profile, error)
+	CreateProfile(ctx context.Context, profile Profile) (Profile, error)
+	UpdateProfile(ctx context.Context, profile Profile) (Profile, error)
+	DeleteProfile(ctx context.Context, id string) error
+}
+
 // Packer ...
 type Packer interface {
-	GetPackets(ctx context.Context, itemsToPack int) (map[int]int, error)
+	GetPackets(ctx context.Context, itemsToPack int, opts ...PacketsOption) (map[int]int, error)
+	GetUnitPackets(ctx context.Context, quantity Quantity, opts ...PacketsOption) (UnitPackets, error)
+	ExplainPackets(ctx context.Context, itemsToPack int, opts ...PacketsOption) (Explanation, error)
+	GetOrderPackets(ctx context.Context, lines []OrderLine, opts ...PacketsOption) (OrderPlan, error)
+	GetBackorderPackets(ctx context.Context, itemsToPack int, opts ...PacketsOption) (BackorderPlan, error)
+	GetBundlePackets(ctx context.Context, demand map[string]int) (BundlePlan, error)
+	GetContainerPlan(ctx context.Context, itemsToPack int, spec HierarchySpec, opts ...PacketsOption) (ContainerPlan, error)
+	GetFulfilmentPlan(ctx context.Context, itemsToPack int, warehouses []Warehouse, opts ...PacketsOption) (FulfilmentPlan, error)
+	CreateQuote(ctx context.Context, itemsToPack int, opts ...PacketsOption) (Quote, error)
 }
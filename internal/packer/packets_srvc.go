@@ -3,60 +3,232 @@ package packer
 import (
 	"context"
 	"errors"
+	"time"
+
 	"golang.org/x/exp/slog"
 )
 
 const (
 	// ErrorNegativeOrZeroItems ...
 	ErrorNegativeOrZeroItems = "items must be more than 0"
+	// ErrorTooManyItems ...
+	ErrorTooManyItems = "items must not be more than 1000000000"
 )
 
+// MaxItems caps the items of a single packing.
+const MaxItems = 1_000_000_000
+
 // Ensure PacketsService defined types fully satisfy Packer interfaces.
 var _ Packer = &PacketsService{}
 
 // PacketsService holds the Packets service related params.
 type PacketsService struct {
+	Catalogs   *CatalogService
+	Profiles   *ProfileService
+	Guardrails *Guardrails
+	Bundles    *BundleService
+	Units      *Units
+	Pricing    *PricingService
+	Quotes     *QuoteService
+	Stock      *StockService
 }
 
 // NewPacketsService is a constructor of the PacketsService.
-func NewPacketsService() *PacketsService {
-	return &PacketsService{}
+func NewPacketsService(catalogs *CatalogService, profiles *ProfileService) *PacketsService {
+	return &PacketsService{
+		Catalogs:   catalogs,
+		Profiles:   profiles,
+		Guardrails: NewGuardrails(Guardrail{}),
+		Bundles:    NewBundleService(),
+		Units:      NewUnits(),
+		Pricing:    NewPricingService(),
+		Quotes:     NewQuoteService(DefaultQuoteTTL),
+		Stock:      NewStockService(),
+	}
 }
 
-// GetPackets ...
-func (packets PacketsService) GetPackets(ctx context.Context, itemsToPack int) (map[int]int, error) {
+// GetPackets calculates the packs with the least items overshoot and, among those,
+// the fewest packs. Options such as WithConstraints narrow down the allowed packs.
+func (packets PacketsService) GetPackets(ctx context.Context, itemsToPack int, opts ...PacketsOption) (map[int]int, error) {
+	result, err := packets.pack(ctx, itemsToPack, opts)
+	if err != nil {
+		return map[int]int{}, err
+	}
+
+	return result.best.Packs, nil
+}
+
+// packing is the outcome of a pack call together with the inputs that led to it.
+type packing struct {
+	best           solution
+	solver         *solver
+	rounding       string
+	catalogID      string
+	catalogVersion int
+	catalog        []int
+	constraints    Constraints
+	profileID      string
+}
+
+// pack applies the options on top of the catalog and runs the solver.
+func (packets PacketsService) pack(ctx context.Context, itemsToPack int, opts []PacketsOption) (packing, error) {
 	if itemsToPack <= 0 {
 		slog.ErrorContext(ctx,
 			ErrorNegativeOrZeroItems,
 			"incoming_items", itemsToPack)
-		return map[int]int{}, errors.New(ErrorNegativeOrZeroItems)
+		return packing{}, errors.New(ErrorNegativeOrZeroItems)
+	}
+	if itemsToPack > MaxItems {
+		slog.ErrorContext(ctx,
+			ErrorTooManyItems,
+			"incoming_items", itemsToPack)
+		return packing{}, errors.New(ErrorTooManyItems)
+	}
+
+	options := newPacketsOptions(opts)
+	sizer, err := packets.Catalogs.GetCatalog(options.catalogID)
+	if err != nil {
+		slog.ErrorContext(ctx,
+			err.Error(),
+			"incoming_catalog", options.catalogID)
+		return packing{}, err
+	}
+	catalog, catalogVersion := sizer.Catalog()
+	if !options.asOf.IsZero() {
+		catalog, catalogVersion, err = sizer.CatalogAt(options.asOf)
+		if err != nil {
+			slog.ErrorContext(ctx,
+				err.Error(),
+				"incoming_catalog", options.catalogID,
+				"incoming_as_of", options.asOf)
+			return packing{}, err
+		}
+	}
+	constraints := options.constraints
+	profile := Profile{Rounding: RoundingUp}
+	if options.profileID != "" {
+		profile, err = packets.Profiles.GetProfile(options.profileID)
+		if err != nil {
+			slog.ErrorContext(ctx,
+				err.Error(),
+				"incoming_profile", options.profileID)
+			return packing{}, err
+		}
+		constraints = profile.apply(catalog, constraints)
+	}
+	if options.stock != nil {
+		constraints = constraints.withinStock(catalog, options.stock)
+	}
+	if options.rounding != "" {
+		profile.Rounding = options.rounding
+	}
+
+	// Failures of probes are expected, the caller tries other options.
+	level := slog.LevelError
+	if options.probe {
+		level = slog.LevelDebug
+	}
+
+	var best solution
+	s, err := newSolver(catalog, itemsToPack, constraints, profile.Rounding)
+	if err == nil {
+		best, err = s.best(profile.Rounding)
+	}
+	if err != nil {
+		slog.Log(ctx, level,
+			err.Error(),
+			"incoming_items", itemsToPack,
+			slog.Any("constraints", constraints))
+		return packing{}, err
+	}
+
+	overshoot := best.Total - itemsToPack
+	if profile.MaxOvershootPercent > 0 && float64(overshoot) > float64(itemsToPack)*profile.MaxOvershootPercent/100 {
+		err = newInfeasibleError(ConstraintMaxOvershootPercent,
+			"overshoot of %d items exceeds %.2f%% of %d items", overshoot, profile.MaxOvershootPercent, itemsToPack)
+		slog.Log(ctx, level,
+			err.Error(),
+			"incoming_items", itemsToPack,
+			"incoming_profile", profile.ID)
+		return packing{}, err
 	}
 
-	return getMinNecessaryPacks(itemsToPack), nil
+	err = packets.Guardrails.check(options.catalogID, itemsToPack, best.Total)
+	if err != nil {
+		slog.Log(ctx, level,
+			err.Error(),
+			"incoming_items", itemsToPack,
+			"incoming_catalog", options.catalogID)
+		return packing{}, err
+	}
+
+	return packing{
+		best:           best,
+		solver:         s,
+		rounding:       profile.Rounding,
+		catalogID:      options.catalogID,
+		catalogVersion: catalogVersion,
+		catalog:        catalog,
+		constraints:    constraints,
+		profileID:      profile.ID,
+	}, nil
 }
 
-// getMinNecessaryPacks calculates minimum packs quantity for given items based on packs sizes.
-func getMinNecessaryPacks(items int) map[int]int {
-	necessaryPacks := make(map[int]int)
-	lastUsedPackIndex := len(SortedSizes) - 1
-
-	for lastUsedPackIndex > 0 {
-		if items-SortedSizes[lastUsedPackIndex] >= 0 {
-			necessaryPacks[SortedSizes[lastUsedPackIndex]]++
-			items -= SortedSizes[lastUsedPackIndex]
-		} else {
-			lastUsedPackIndex--
-		}
+// GetContainerPlan packs the items and nests the resulting packets into cartons and pallets.
+func (packets PacketsService) GetContainerPlan(ctx context.Context, itemsToPack int, spec HierarchySpec, opts ...PacketsOption) (ContainerPlan, error) {
+	necessaryPacks, err := packets.GetPackets(ctx, itemsToPack, opts...)
+	if err != nil {
+		return ContainerPlan{}, err
+	}
+
+	plan, err := PlanContainers(necessaryPacks, spec)
+	if err != nil {
+		slog.ErrorContext(ctx,
+			err.Error(),
+			"incoming_items", itemsToPack,
+			"packets", necessaryPacks)
+		return ContainerPlan{}, err
 	}
 
-	if items > 0 {
-		for _, size := range SortedSizes {
-			if size >= items {
-				necessaryPacks[size]++
-				break
+	return plan, nil
+}
+
+// Sweep releases expired reservations and prunes settled reservations and
+// expired quotes every interval until the context is done.
+func (packets PacketsService) Sweep(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if released := packets.Stock.ReleaseExpired(); released > 0 {
+				slog.InfoContext(ctx, "released expired reservations",
+					slog.Int("released", released),
+				)
+			}
+			if pruned := packets.Stock.PruneSettled(); pruned > 0 {
+				slog.DebugContext(ctx, "pruned settled reservations",
+					slog.Int("pruned", pruned),
+				)
+			}
+			if pruned := packets.Quotes.PruneExpired(); pruned > 0 {
+				slog.DebugContext(ctx, "pruned expired quotes",
+					slog.Int("pruned", pruned),
+				)
 			}
 		}
 	}
+}
+
+// getMinNecessaryPacks calculates minimum packs quantity for given items based on packs sizes.
+func getMinNecessaryPacks(sizes []int, items int, constraints Constraints) (map[int]int, error) {
+	best, err := solve(sizes, items, constraints, RoundingUp)
+	if err != nil {
+		return nil, err
+	}
 
-	return necessaryPacks
+	return best.Packs, nil
 }
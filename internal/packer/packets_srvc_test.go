@@ -9,7 +9,7 @@ import (
 )
 
 func newPacker() *PacketsService {
-	return NewPacketsService()
+	return NewPacketsService(NewCatalogService(newSizer(SortedSizes)), NewProfileService())
 }
 
 func TestPacketsService_GetPackets(t *testing.T) {
@@ -56,6 +56,12 @@ func Test_getMinNecessaryPacks(t *testing.T) {
 				250: 1,
 			},
 		},
+		{
+			Items: 751,
+			WantNecessaryPacks: map[int]int{
+				1000: 1,
+			},
+		},
 		{
 			Items: 12001,
 			WantNecessaryPacks: map[int]int{
@@ -64,12 +70,146 @@ func Test_getMinNecessaryPacks(t *testing.T) {
 				250:  1,
 			},
 		},
+		{
+			Items: 500_000_001,
+			WantNecessaryPacks: map[int]int{
+				5000: 100_000,
+				250:  1,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
-		gotNecessaryPacks := getMinNecessaryPacks(tc.Items)
+		gotNecessaryPacks, err := getMinNecessaryPacks(SortedSizes, tc.Items, Constraints{})
+		require.NoError(t, err)
 		if !reflect.DeepEqual(tc.WantNecessaryPacks, gotNecessaryPacks) {
 			t.Fatalf("For %v items, expected: %v, got %v", tc.Items, tc.WantNecessaryPacks, gotNecessaryPacks)
 		}
 	}
 }
+
+func TestPacketsService_GetPacketsWithConstraints(t *testing.T) {
+	testCases := []struct {
+		name           string
+		items          int
+		constraints    Constraints
+		wantPackets    map[int]int
+		wantConstraint string
+	}{
+		{
+			name:        "OK max total packs",
+			items:       12001,
+			constraints: Constraints{MaxTotalPacks: 3},
+			wantPackets: map[int]int{5000: 3},
+		},
+		{
+			name:        "OK max per size",
+			items:       12001,
+			constraints: Constraints{MaxPerSize: map[int]int{5000: 1}},
+			wantPackets: map[int]int{5000: 1, 2000: 3, 1000: 1, 250: 1},
+		},
+		{
+			name:        "OK min per size",
+			items:       12001,
+			constraints: Constraints{MinPerSize: map[int]int{1000: 2}},
+			wantPackets: map[int]int{5000: 2, 1000: 2, 250: 1},
+		},
+		{
+			name:        "OK excluded sizes",
+			items:       12001,
+			constraints: Constraints{ExcludedSizes: []int{250}},
+			wantPackets: map[int]int{5000: 2, 2000: 1, 500: 1},
+		},
+		{
+			name:           "ERR all sizes excluded",
+			items:          1,
+			constraints:    Constraints{ExcludedSizes: []int{250, 500, 1000, 2000, 5000}},
+			wantConstraint: ConstraintExcludedSizes,
+		},
+		{
+			name:           "ERR min per excluded size",
+			items:          1,
+			constraints:    Constraints{ExcludedSizes: []int{250}, MinPerSize: map[int]int{250: 1}},
+			wantConstraint: ConstraintExcludedSizes,
+		},
+		{
+			name:           "ERR min per unknown size",
+			items:          1,
+			constraints:    Constraints{MinPerSize: map[int]int{300: 1}},
+			wantConstraint: ConstraintMinPerSize,
+		},
+		{
+			name:           "ERR min above max per size",
+			items:          1,
+			constraints:    Constraints{MinPerSize: map[int]int{250: 2}, MaxPerSize: map[int]int{250: 1}},
+			wantConstraint: ConstraintMaxPerSize,
+		},
+		{
+			name:           "ERR max per size cannot cover items",
+			items:          9000,
+			constraints:    Constraints{MaxPerSize: map[int]int{250: 1, 500: 1, 1000: 1, 2000: 1, 5000: 1}},
+			wantConstraint: ConstraintMaxPerSize,
+		},
+		{
+			name:           "ERR min per size above max total packs",
+			items:          1,
+			constraints:    Constraints{MaxTotalPacks: 1, MinPerSize: map[int]int{250: 1, 500: 1}},
+			wantConstraint: ConstraintMaxTotalPacks,
+		},
+		{
+			name:           "ERR max total packs cannot cover items",
+			items:          12001,
+			constraints:    Constraints{MaxTotalPacks: 2, MaxPerSize: map[int]int{5000: 2}},
+			wantConstraint: ConstraintMaxTotalPacks,
+		},
+	}
+
+	packer := newPacker()
+	for index := range testCases {
+		tc := testCases[index]
+		t.Run(tc.name, func(t *testing.T) {
+			packets, err := packer.GetPackets(context.Background(), tc.items, WithConstraints(tc.constraints))
+			if tc.wantConstraint == "" {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantPackets, packets)
+				return
+			}
+			var infeasibleErr *InfeasibleError
+			require.ErrorAs(t, err, &infeasibleErr)
+			require.Equal(t, tc.wantConstraint, infeasibleErr.Constraint)
+		})
+	}
+}
+
+func Test_solveRoundingWithPackCap(t *testing.T) {
+	testCases := []struct {
+		name           string
+		sizes          []int
+		items          int
+		maxTotalPacks  int
+		rounding       string
+		wantPacks      map[int]int
+		wantConstraint string
+	}{
+		{name: "OK down under the cap", sizes: []int{6}, items: 111, maxTotalPacks: 5, rounding: RoundingDown, wantPacks: map[int]int{6: 5}},
+		{name: "OK nearest under the cap", sizes: []int{6}, items: 111, maxTotalPacks: 5, rounding: RoundingNearest, wantPacks: map[int]int{6: 5}},
+		{name: "OK down on a large order", sizes: SortedSizes, items: 40_000, maxTotalPacks: 3, rounding: RoundingDown, wantPacks: map[int]int{5000: 3}},
+		{name: "OK down past the solver span", sizes: SortedSizes, items: 100_000_000, maxTotalPacks: 4, rounding: RoundingDown, wantPacks: map[int]int{5000: 4}},
+		{name: "OK nearest on a large order", sizes: SortedSizes, items: 40_000, maxTotalPacks: 3, rounding: RoundingNearest, wantPacks: map[int]int{5000: 3}},
+		{name: "ERR up over the cap", sizes: []int{6}, items: 111, maxTotalPacks: 5, rounding: RoundingUp, wantConstraint: ConstraintMaxTotalPacks},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			best, err := solve(tc.sizes, tc.items, Constraints{MaxTotalPacks: tc.maxTotalPacks}, tc.rounding)
+			if tc.wantConstraint != "" {
+				var infeasibleErr *InfeasibleError
+				require.ErrorAs(t, err, &infeasibleErr)
+				require.Equal(t, tc.wantConstraint, infeasibleErr.Constraint)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPacks, best.Packs)
+		})
+	}
+}
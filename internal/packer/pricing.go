@@ -0,0 +1,93 @@
+package packer
+
+import (
+	"errors"
+	"sync"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// ERR consts ...
+const (
+	ErrorPricingNotFound = "catalog has no pricing"
+	ErrorSizeNotPriced   = "pack size has no price"
+	ErrorPriceOverflow   = "quote amounts are too large to be computed"
+)
+
+// MaxPackPrice caps the price of a pack in minor units, so that the amounts
+// of a quote of up to MaxItems packs fit in an int64.
+const MaxPackPrice = 1_000_000_000
+
+// DiscountTier grants a discount on orders of at least MinItems items.
+type DiscountTier struct {
+	MinItems        int     `json:"min_items"`
+	DiscountPercent float64 `json:"discount_percent"`
+}
+
+// Pricing holds the price of every pack size of a catalog in minor units of
+// the currency (e.g. cents) together with the discount tiers.
+type Pricing struct {
+	Currency   string         `json:"currency"`
+	PackPrices map[int]int64  `json:"pack_prices"`
+	Tiers      []DiscountTier `json:"tiers"`
+}
+
+// discount returns the discount percent of the highest tier the items reach.
+func (p Pricing) discount(items int) float64 {
+	percent, minItems := 0.0, -1
+	for _, tier := range p.Tiers {
+		if items >= tier.MinItems && tier.MinItems > minItems {
+			percent, minItems = tier.DiscountPercent, tier.MinItems
+		}
+	}
+	return percent
+}
+
+// clone returns a deep copy of the pricing.
+func (p Pricing) clone() Pricing {
+	return Pricing{
+		Currency:   p.Currency,
+		PackPrices: maps.Clone(p.PackPrices),
+		Tiers:      slices.Clone(p.Tiers),
+	}
+}
+
+// PricingService holds the pricing of every catalog.
+type PricingService struct {
+	mu       sync.RWMutex
+	catalogs map[string]Pricing
+}
+
+// NewPricingService is a constructor of the PricingService.
+func NewPricingService() *PricingService {
+	return &PricingService{
+		catalogs: make(map[string]Pricing),
+	}
+}
+
+// GetPricing returns the pricing of the catalog.
+func (pricing *PricingService) GetPricing(catalogID string) (Pricing, error) {
+	pricing.mu.RLock()
+	defer pricing.mu.RUnlock()
+
+	catalogPricing, exists := pricing.catalogs[catalogID]
+	if !exists {
+		return Pricing{}, errors.New(ErrorPricingNotFound)
+	}
+
+	return catalogPricing.clone(), nil
+}
+
+// SetPricing replaces the pricing of the catalog.
+func (pricing *PricingService) SetPricing(catalogID string, catalogPricing Pricing) {
+	catalogPricing = catalogPricing.clone()
+	slices.SortFunc(catalogPricing.Tiers, func(a, b DiscountTier) int {
+		return a.MinItems - b.MinItems
+	})
+
+	pricing.mu.Lock()
+	defer pricing.mu.Unlock()
+
+	pricing.catalogs[catalogID] = catalogPricing
+}
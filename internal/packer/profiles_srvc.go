@@ -0,0 +1,162 @@
+package packer
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
+)
+
+// Rounding policies of a profile.
+const (
+	RoundingUp      = "up"
+	RoundingDown    = "down"
+	RoundingNearest = "nearest"
+)
+
+// ERR consts ...
+const (
+	ErrorProfileNotFound = "profile does not exist"
+	ErrorProfileExists   = "profile already exists"
+)
+
+// Profile holds negotiated packing rules of a customer.
+type Profile struct {
+	ID                  string  `json:"id"`
+	AllowedSizes        []int   `json:"allowed_sizes"`
+	Rounding            string  `json:"rounding"`
+	MaxOvershootPercent float64 `json:"max_overshoot_percent"`
+}
+
+// Ensure ProfileService defined types fully satisfy Profiler interfaces.
+var _ Profiler = &ProfileService{}
+
+// ProfileService is an in-memory store of customer profiles.
+type ProfileService struct {
+	mu       sync.RWMutex
+	profiles map[string]Profile
+}
+
+// NewProfileService is a constructor of the ProfileService.
+func NewProfileService() *ProfileService {
+	return &ProfileService{
+		profiles: make(map[string]Profile),
+	}
+}
+
+// ListProfiles returns all profiles sorted by ID.
+func (profiles *ProfileService) ListProfiles() []Profile {
+	profiles.mu.RLock()
+	defer profiles.mu.RUnlock()
+
+	list := make([]Profile, 0, len(profiles.profiles))
+	for _, profile := range profiles.profiles {
+		list = append(list, profile)
+	}
+	slices.SortFunc(list, func(a, b Profile) int {
+		switch {
+		case a.ID < b.ID:
+			return -1
+		case a.ID > b.ID:
+			return 1
+		}
+		return 0
+	})
+
+	return list
+}
+
+// GetProfile ...
+func (profiles *ProfileService) GetProfile(id string) (Profile, error) {
+	profiles.mu.RLock()
+	defer profiles.mu.RUnlock()
+
+	profile, exists := profiles.profiles[id]
+	if !exists {
+		return Profile{}, errors.New(ErrorProfileNotFound)
+	}
+
+	return profile, nil
+}
+
+// CreateProfile ...
+func (profiles *ProfileService) CreateProfile(ctx context.Context, profile Profile) (Profile, error) {
+	profiles.mu.Lock()
+	defer profiles.mu.Unlock()
+
+	if _, exists := profiles.profiles[profile.ID]; exists {
+		slog.ErrorContext(ctx,
+			ErrorProfileExists,
+			slog.Any("incoming_profile", profile.ID),
+		)
+		return Profile{}, errors.New(ErrorProfileExists)
+	}
+
+	profile = normalizeProfile(profile)
+	profiles.profiles[profile.ID] = profile
+
+	return profile, nil
+}
+
+// UpdateProfile ...
+func (profiles *ProfileService) UpdateProfile(ctx context.Context, profile Profile) (Profile, error) {
+	profiles.mu.Lock()
+	defer profiles.mu.Unlock()
+
+	if _, exists := profiles.profiles[profile.ID]; !exists {
+		slog.ErrorContext(ctx,
+			ErrorProfileNotFound,
+			slog.Any("incoming_profile", profile.ID),
+		)
+		return Profile{}, errors.New(ErrorProfileNotFound)
+	}
+
+	profile = normalizeProfile(profile)
+	profiles.profiles[profile.ID] = profile
+
+	return profile, nil
+}
+
+// DeleteProfile ...
+func (profiles *ProfileService) DeleteProfile(ctx context.Context, id string) error {
+	profiles.mu.Lock()
+	defer profiles.mu.Unlock()
+
+	if _, exists := profiles.profiles[id]; !exists {
+		slog.ErrorContext(ctx,
+			ErrorProfileNotFound,
+			slog.Any("incoming_profile", id),
+		)
+		return errors.New(ErrorProfileNotFound)
+	}
+
+	delete(profiles.profiles, id)
+
+	return nil
+}
+
+// normalizeProfile sorts allowed sizes and fills the default rounding policy.
+func normalizeProfile(profile Profile) Profile {
+	profile.AllowedSizes = slices.Clone(profile.AllowedSizes)
+	slices.Sort(profile.AllowedSizes)
+	if profile.Rounding == "" {
+		profile.Rounding = RoundingUp
+	}
+	return profile
+}
+
+// apply narrows down the constraints to the sizes allowed by the profile.
+func (profile Profile) apply(catalog []int, constraints Constraints) Constraints {
+	if len(profile.AllowedSizes) == 0 {
+		return constraints
+	}
+	constraints.ExcludedSizes = slices.Clone(constraints.ExcludedSizes)
+	for _, size := range catalog {
+		if !slices.Contains(profile.AllowedSizes, size) {
+			constraints.ExcludedSizes = append(constraints.ExcludedSizes, size)
+		}
+	}
+	return constraints
+}
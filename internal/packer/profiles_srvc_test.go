@@ -0,0 +1,108 @@
+package packer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileService_CRUD(t *testing.T) {
+	profiles := NewProfileService()
+	ctx := context.Background()
+
+	profile, err := profiles.CreateProfile(ctx, Profile{ID: "acme", AllowedSizes: []int{1000, 500}})
+	require.NoError(t, err)
+	require.Equal(t, []int{500, 1000}, profile.AllowedSizes)
+	require.Equal(t, RoundingUp, profile.Rounding)
+
+	_, err = profiles.CreateProfile(ctx, Profile{ID: "acme"})
+	require.EqualError(t, err, ErrorProfileExists)
+
+	profile, err = profiles.UpdateProfile(ctx, Profile{ID: "acme", Rounding: RoundingDown})
+	require.NoError(t, err)
+	require.Equal(t, RoundingDown, profile.Rounding)
+
+	_, err = profiles.UpdateProfile(ctx, Profile{ID: "globex"})
+	require.EqualError(t, err, ErrorProfileNotFound)
+
+	_, err = profiles.CreateProfile(ctx, Profile{ID: "globex"})
+	require.NoError(t, err)
+	list := profiles.ListProfiles()
+	require.Len(t, list, 2)
+	require.Equal(t, "acme", list[0].ID)
+
+	require.NoError(t, profiles.DeleteProfile(ctx, "acme"))
+	require.EqualError(t, profiles.DeleteProfile(ctx, "acme"), ErrorProfileNotFound)
+	_, err = profiles.GetProfile("acme")
+	require.EqualError(t, err, ErrorProfileNotFound)
+}
+
+func TestPacketsService_GetPacketsWithProfile(t *testing.T) {
+	testCases := []struct {
+		name           string
+		items          int
+		profile        Profile
+		wantPackets    map[int]int
+		wantConstraint string
+	}{
+		{
+			name:        "OK allowed sizes",
+			items:       12001,
+			profile:     Profile{ID: "allowed", AllowedSizes: []int{500, 1000}},
+			wantPackets: map[int]int{1000: 12, 500: 1},
+		},
+		{
+			name:        "OK rounding down",
+			items:       12001,
+			profile:     Profile{ID: "down", Rounding: RoundingDown},
+			wantPackets: map[int]int{5000: 2, 2000: 1},
+		},
+		{
+			name:        "OK rounding nearest",
+			items:       12001,
+			profile:     Profile{ID: "nearest", Rounding: RoundingNearest},
+			wantPackets: map[int]int{5000: 2, 2000: 1},
+		},
+		{
+			name:        "OK overshoot within limit",
+			items:       12001,
+			profile:     Profile{ID: "overshoot-ok", MaxOvershootPercent: 10},
+			wantPackets: map[int]int{5000: 2, 2000: 1, 250: 1},
+		},
+		{
+			name:           "ERR overshoot over limit",
+			items:          1,
+			profile:        Profile{ID: "overshoot", MaxOvershootPercent: 10},
+			wantConstraint: ConstraintMaxOvershootPercent,
+		},
+		{
+			name:           "ERR nothing to round down to",
+			items:          1,
+			profile:        Profile{ID: "down-small", Rounding: RoundingDown},
+			wantConstraint: ConstraintRounding,
+		},
+	}
+
+	packer := newPacker()
+	for index := range testCases {
+		tc := testCases[index]
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := packer.Profiles.CreateProfile(context.Background(), tc.profile)
+			require.NoError(t, err)
+
+			packets, err := packer.GetPackets(context.Background(), tc.items, WithProfile(tc.profile.ID))
+			if tc.wantConstraint == "" {
+				require.NoError(t, err)
+				require.Equal(t, tc.wantPackets, packets)
+				return
+			}
+			var infeasibleErr *InfeasibleError
+			require.ErrorAs(t, err, &infeasibleErr)
+			require.Equal(t, tc.wantConstraint, infeasibleErr.Constraint)
+		})
+	}
+
+	_, err := packer.GetPackets(context.Background(), 1, WithProfile("unknown"))
+	require.EqualError(t, err, ErrorProfileNotFound)
+}
@@ -0,0 +1,197 @@
+package packer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
+)
+
+// DefaultQuoteTTL is how long a quote stays valid after it is created.
+const DefaultQuoteTTL = 24 * time.Hour
+
+// QuoteRetention is how long quotes are kept past their expiry, so that
+// clients can still look them up.
+const QuoteRetention = 24 * time.Hour
+
+// ERR consts ...
+const (
+	ErrorQuoteNotFound = "quote does not exist"
+)
+
+// QuoteLine is the price of the packs of a single size.
+type QuoteLine struct {
+	Size      int   `json:"size"`
+	Packs     int   `json:"packs"`
+	UnitPrice int64 `json:"unit_price"`
+	Amount    int64 `json:"amount"`
+}
+
+// Quote is an immutable priced packing. Amounts are in minor units of the currency.
+type Quote struct {
+	ID              string      `json:"id"`
+	CatalogID       string      `json:"catalog"`
+	CatalogVersion  int         `json:"catalog_version"`
+	Items           int         `json:"items"`
+	Shipped         int         `json:"shipped"`
+	Packets         map[int]int `json:"packets"`
+	Currency        string      `json:"currency"`
+	Lines           []QuoteLine `json:"lines"`
+	Subtotal        int64       `json:"subtotal"`
+	DiscountPercent float64     `json:"discount_percent"`
+	Discount        int64       `json:"discount"`
+	Total           int64       `json:"total"`
+	CreatedAt       time.Time   `json:"created_at"`
+	ExpiresAt       time.Time   `json:"expires_at"`
+}
+
+// Expired reports whether the quote is no longer valid at the time.
+func (q Quote) Expired(at time.Time) bool {
+	return !at.Before(q.ExpiresAt)
+}
+
+// clone returns a deep copy of the quote.
+func (q Quote) clone() Quote {
+	q.Packets = maps.Clone(q.Packets)
+	q.Lines = slices.Clone(q.Lines)
+	return q
+}
+
+// QuoteService is an in-memory store of issued quotes.
+type QuoteService struct {
+	mu     sync.RWMutex
+	ttl    time.Duration
+	now    func() time.Time
+	quotes map[string]Quote
+}
+
+// NewQuoteService is a constructor of the QuoteService issuing quotes valid for the ttl.
+func NewQuoteService(ttl time.Duration) *QuoteService {
+	return &QuoteService{
+		ttl:    ttl,
+		now:    time.Now,
+		quotes: make(map[string]Quote),
+	}
+}
+
+// GetQuote returns the quote by ID, expired or not.
+func (quotes *QuoteService) GetQuote(id string) (Quote, error) {
+	quotes.mu.RLock()
+	defer quotes.mu.RUnlock()
+
+	quote, exists := quotes.quotes[id]
+	if !exists {
+		return Quote{}, errors.New(ErrorQuoteNotFound)
+	}
+
+	return quote.clone(), nil
+}
+
+// Now returns the current time of the service clock.
+func (quotes *QuoteService) Now() time.Time {
+	return quotes.now()
+}
+
+// PruneExpired deletes the quotes whose retention has passed and returns how
+// many were deleted.
+func (quotes *QuoteService) PruneExpired() int {
+	quotes.mu.Lock()
+	defer quotes.mu.Unlock()
+
+	now, pruned := quotes.now(), 0
+	for id, quote := range quotes.quotes {
+		if !now.Before(quote.ExpiresAt.Add(QuoteRetention)) {
+			delete(quotes.quotes, id)
+			pruned++
+		}
+	}
+
+	return pruned
+}
+
+// issue stamps the quote with an ID and validity and stores it.
+func (quotes *QuoteService) issue(quote Quote) (Quote, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Quote{}, err
+	}
+
+	quote.ID = hex.EncodeToString(id)
+	quote.CreatedAt = quotes.now().UTC()
+	quote.ExpiresAt = quote.CreatedAt.Add(quotes.ttl)
+
+	quotes.mu.Lock()
+	defer quotes.mu.Unlock()
+
+	quotes.quotes[quote.ID] = quote.clone()
+
+	return quote, nil
+}
+
+// CreateQuote calculates the packets like GetPackets, prices them with the
+// catalog pricing and issues an immutable quote.
+func (packets PacketsService) CreateQuote(ctx context.Context, itemsToPack int, opts ...PacketsOption) (Quote, error) {
+	result, err := packets.pack(ctx, itemsToPack, opts)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	pricing, err := packets.Pricing.GetPricing(result.catalogID)
+	if err != nil {
+		slog.ErrorContext(ctx,
+			err.Error(),
+			"incoming_catalog", result.catalogID)
+		return Quote{}, err
+	}
+
+	quote := Quote{
+		CatalogID:      result.catalogID,
+		CatalogVersion: result.catalogVersion,
+		Items:          itemsToPack,
+		Shipped:        result.best.Total,
+		Packets:        result.best.Packs,
+		Currency:       pricing.Currency,
+	}
+
+	sizes := maps.Keys(result.best.Packs)
+	slices.Sort(sizes)
+	for _, size := range sizes {
+		price, priced := pricing.PackPrices[size]
+		if !priced {
+			slog.ErrorContext(ctx,
+				ErrorSizeNotPriced,
+				"incoming_catalog", result.catalogID,
+				"size", size)
+			return Quote{}, errors.New(ErrorSizeNotPriced)
+		}
+		packs := int64(result.best.Packs[size])
+		if price > 0 && (packs > math.MaxInt64/price || quote.Subtotal > math.MaxInt64-packs*price) {
+			slog.ErrorContext(ctx,
+				ErrorPriceOverflow,
+				"incoming_catalog", result.catalogID,
+				"size", size)
+			return Quote{}, errors.New(ErrorPriceOverflow)
+		}
+		line := QuoteLine{
+			Size:      size,
+			Packs:     result.best.Packs[size],
+			UnitPrice: price,
+			Amount:    packs * price,
+		}
+		quote.Lines = append(quote.Lines, line)
+		quote.Subtotal += line.Amount
+	}
+
+	quote.DiscountPercent = pricing.discount(itemsToPack)
+	quote.Discount = int64(math.Round(float64(quote.Subtotal) * quote.DiscountPercent / 100))
+	quote.Total = quote.Subtotal - quote.Discount
+
+	return packets.Quotes.issue(quote)
+}
@@ -0,0 +1,199 @@
+package packer
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newPricing() Pricing {
+	return Pricing{
+		Currency: "EUR",
+		PackPrices: map[int]int64{
+			250:  500,
+			500:  900,
+			1000: 1600,
+			2000: 3000,
+			5000: 7000,
+		},
+		Tiers: []DiscountTier{
+			{MinItems: 5000, DiscountPercent: 10},
+			{MinItems: 1000, DiscountPercent: 5},
+		},
+	}
+}
+
+func TestPacketsService_CreateQuote(t *testing.T) {
+	packer := newPacker()
+	packer.Pricing.SetPricing(DefaultCatalogID, newPricing())
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	packer.Quotes.now = func() time.Time { return createdAt }
+
+	testCases := []struct {
+		name      string
+		items     int
+		wantQuote Quote
+	}{
+		{
+			name:  "OK no discount",
+			items: 501,
+			wantQuote: Quote{
+				CatalogID:      DefaultCatalogID,
+				CatalogVersion: 1,
+				Items:          501,
+				Shipped:        750,
+				Packets:        map[int]int{250: 1, 500: 1},
+				Currency:       "EUR",
+				Lines: []QuoteLine{
+					{Size: 250, Packs: 1, UnitPrice: 500, Amount: 500},
+					{Size: 500, Packs: 1, UnitPrice: 900, Amount: 900},
+				},
+				Subtotal: 1400,
+				Total:    1400,
+			},
+		},
+		{
+			name:  "OK lower tier",
+			items: 1200,
+			wantQuote: Quote{
+				CatalogID:      DefaultCatalogID,
+				CatalogVersion: 1,
+				Items:          1200,
+				Shipped:        1250,
+				Packets:        map[int]int{250: 1, 1000: 1},
+				Currency:       "EUR",
+				Lines: []QuoteLine{
+					{Size: 250, Packs: 1, UnitPrice: 500, Amount: 500},
+					{Size: 1000, Packs: 1, UnitPrice: 1600, Amount: 1600},
+				},
+				Subtotal:        2100,
+				DiscountPercent: 5,
+				Discount:        105,
+				Total:           1995,
+			},
+		},
+		{
+			name:  "OK upper tier",
+			items: 12001,
+			wantQuote: Quote{
+				CatalogID:      DefaultCatalogID,
+				CatalogVersion: 1,
+				Items:          12001,
+				Shipped:        12250,
+				Packets:        map[int]int{250: 1, 2000: 1, 5000: 2},
+				Currency:       "EUR",
+				Lines: []QuoteLine{
+					{Size: 250, Packs: 1, UnitPrice: 500, Amount: 500},
+					{Size: 2000, Packs: 1, UnitPrice: 3000, Amount: 3000},
+					{Size: 5000, Packs: 2, UnitPrice: 7000, Amount: 14000},
+				},
+				Subtotal:        17500,
+				DiscountPercent: 10,
+				Discount:        1750,
+				Total:           15750,
+			},
+		},
+	}
+
+	for index := range testCases {
+		tc := testCases[index]
+		t.Run(tc.name, func(t *testing.T) {
+			quote, err := packer.CreateQuote(context.Background(), tc.items)
+			require.NoError(t, err)
+			require.Len(t, quote.ID, 32)
+			require.Equal(t, createdAt, quote.CreatedAt)
+			require.Equal(t, createdAt.Add(DefaultQuoteTTL), quote.ExpiresAt)
+
+			tc.wantQuote.ID = quote.ID
+			tc.wantQuote.CreatedAt = quote.CreatedAt
+			tc.wantQuote.ExpiresAt = quote.ExpiresAt
+			require.Equal(t, tc.wantQuote, quote)
+
+			stored, err := packer.Quotes.GetQuote(quote.ID)
+			require.NoError(t, err)
+			require.Equal(t, quote, stored)
+		})
+	}
+}
+
+func TestPacketsService_CreateQuoteErrors(t *testing.T) {
+	packer := newPacker()
+
+	_, err := packer.CreateQuote(context.Background(), 1)
+	require.EqualError(t, err, ErrorPricingNotFound)
+
+	pricing := newPricing()
+	delete(pricing.PackPrices, 250)
+	packer.Pricing.SetPricing(DefaultCatalogID, pricing)
+
+	_, err = packer.CreateQuote(context.Background(), 1)
+	require.EqualError(t, err, ErrorSizeNotPriced)
+
+	// Prices set past the validation of the API must not wrap around.
+	pricing = newPricing()
+	pricing.PackPrices[5000] = math.MaxInt64 / 2
+	packer.Pricing.SetPricing(DefaultCatalogID, pricing)
+
+	_, err = packer.CreateQuote(context.Background(), 15000)
+	require.EqualError(t, err, ErrorPriceOverflow)
+
+	_, err = packer.Quotes.GetQuote("unknown")
+	require.EqualError(t, err, ErrorQuoteNotFound)
+}
+
+func TestQuoteService_Immutable(t *testing.T) {
+	packer := newPacker()
+	packer.Pricing.SetPricing(DefaultCatalogID, newPricing())
+
+	quote, err := packer.CreateQuote(context.Background(), 501)
+	require.NoError(t, err)
+	require.False(t, quote.Expired(quote.CreatedAt))
+	require.True(t, quote.Expired(quote.ExpiresAt))
+
+	quote.Packets[250] = 100
+	quote.Lines[0].Amount = 0
+
+	stored, err := packer.Quotes.GetQuote(quote.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[int]int{250: 1, 500: 1}, stored.Packets)
+	require.Equal(t, int64(500), stored.Lines[0].Amount)
+}
+
+func TestPacketsService_Sweep(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	packer := newPacker()
+	packer.Pricing.SetPricing(DefaultCatalogID, newPricing())
+	require.NoError(t, packer.Stock.SetStock(ctx, DefaultCatalogID, map[int]int{250: 1}))
+
+	reservation, err := packer.Stock.Reserve(ctx, DefaultCatalogID, map[int]int{250: 1}, time.Millisecond)
+	require.NoError(t, err)
+	packer.Quotes.now = func() time.Time { return time.Now().Add(-DefaultQuoteTTL - QuoteRetention) }
+	quote, err := packer.CreateQuote(ctx, 250)
+	require.NoError(t, err)
+	packer.Quotes.now = time.Now
+
+	done := make(chan struct{})
+	go func() {
+		packer.Sweep(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		expired, err := packer.Stock.GetReservation(reservation.ID)
+		return err == nil && expired.Status == ReservationExpired
+	}, time.Second, time.Millisecond)
+	require.Eventually(t, func() bool {
+		_, err := packer.Quotes.GetQuote(quote.ID)
+		return err != nil
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sweeper did not stop after the context was cancelled")
+	}
+}
@@ -0,0 +1,270 @@
+package packer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
+)
+
+// Operations of a scheduled catalog change.
+const (
+	ChangeAddSize    = "add_size"
+	ChangePutSizes   = "put_sizes"
+	ChangeDeleteSize = "delete_size"
+)
+
+// ERR consts ...
+const (
+	ErrorUnknownChange       = "change operation must be one of add_size, put_sizes or delete_size"
+	ErrorEffectiveAtNotAhead = "effective_at must be in the future"
+	ErrorChangeNotFound      = "scheduled change does not exist"
+	ErrorNoCatalogHistory    = "catalog has no history at the time"
+	ErrorTooManyScheduled    = "too many changes are already scheduled"
+)
+
+// ScheduledChange is a catalog mutation applied once its effective time comes.
+type ScheduledChange struct {
+	ID          string    `json:"id"`
+	Op          string    `json:"op"`
+	Size        int       `json:"size,omitempty"`
+	Sizes       []int     `json:"sizes,omitempty"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+// apply returns the sizes after the change without modifying the given ones.
+func (change ScheduledChange) apply(sorted []int) ([]int, error) {
+	switch change.Op {
+	case ChangeAddSize:
+		if change.Size <= 0 {
+			return nil, errors.New(ErrorNegativeOrZeroSize)
+		}
+		if _, exists := slices.BinarySearch(sorted, change.Size); exists {
+			return nil, errors.New(ErrorDuplicatedSizes)
+		}
+		return insertSorted(slices.Clone(sorted), change.Size), nil
+	case ChangeDeleteSize:
+		index, exists := slices.BinarySearch(sorted, change.Size)
+		if !exists {
+			return nil, errors.New(ErrorSizeDoesNotExist)
+		}
+		return slices.Delete(slices.Clone(sorted), index, index+1), nil
+	case ChangePutSizes:
+		if len(change.Sizes) == 0 {
+			return nil, errors.New(ErrorZeroSizesQuantity)
+		}
+		sizes := slices.Clone(change.Sizes)
+		slices.Sort(sizes)
+		for i, size := range sizes {
+			if size <= 0 {
+				return nil, errors.New(ErrorNegativeOrZeroSize)
+			}
+			if i > 0 && sizes[i-1] == size {
+				return nil, errors.New(ErrorDuplicatedSizes)
+			}
+		}
+		return sizes, nil
+	}
+	return nil, errors.New(ErrorUnknownChange)
+}
+
+// maxHistory caps the snapshots kept of the sizes; catalogs older than the
+// oldest kept one are no longer known.
+const maxHistory = 1000
+
+// maxScheduled caps the pending changes, as many as the history keeps.
+const maxScheduled = maxHistory
+
+// snapshot is the state of the sizes from a point in time on.
+type snapshot struct {
+	at      time.Time
+	version int
+	sizes   []int
+}
+
+// record appends the current sizes to the history, dropping the oldest
+// snapshot once the history is full. The caller must hold the lock.
+func (sizes *SizerService) record(at time.Time) {
+	if n := len(sizes.history); n > 0 && at.Before(sizes.history[n-1].at) {
+		at = sizes.history[n-1].at
+	}
+	sizes.history = append(sizes.history, snapshot{
+		at:      at,
+		version: sizes.Version,
+		sizes:   slices.Clone(sizes.SortedSizes),
+	})
+	if len(sizes.history) > maxHistory {
+		sizes.history = slices.Delete(sizes.history, 0, len(sizes.history)-maxHistory)
+	}
+}
+
+// Schedule queues the change to be applied at its effective time. The change
+// must be valid against the sizes as of that time.
+func (sizes *SizerService) Schedule(ctx context.Context, change ScheduledChange) (ScheduledChange, error) {
+	sizes.mu.Lock()
+	defer sizes.mu.Unlock()
+
+	if !change.EffectiveAt.After(sizes.now()) {
+		return ScheduledChange{}, errors.New(ErrorEffectiveAtNotAhead)
+	}
+	if len(sizes.scheduled) >= maxScheduled {
+		return ScheduledChange{}, errors.New(ErrorTooManyScheduled)
+	}
+
+	asOf, _, err := sizes.catalogAt(change.EffectiveAt)
+	if err == nil {
+		_, err = change.apply(asOf)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx,
+			err.Error(),
+			"incoming_op", change.Op,
+			"incoming_effective_at", change.EffectiveAt)
+		return ScheduledChange{}, err
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return ScheduledChange{}, err
+	}
+	change.ID = hex.EncodeToString(id)
+	change.Sizes = slices.Clone(change.Sizes)
+	change.EffectiveAt = change.EffectiveAt.UTC()
+
+	index, _ := slices.BinarySearchFunc(sizes.scheduled, change, func(a, b ScheduledChange) int {
+		if a.EffectiveAt.After(b.EffectiveAt) {
+			return 1
+		}
+		return -1
+	})
+	sizes.scheduled = slices.Insert(sizes.scheduled, index, change)
+
+	return change, nil
+}
+
+// ListScheduled returns the pending changes in the order they take effect.
+func (sizes *SizerService) ListScheduled() []ScheduledChange {
+	sizes.mu.RLock()
+	defer sizes.mu.RUnlock()
+
+	return slices.Clone(sizes.scheduled)
+}
+
+// CancelScheduled drops the pending change.
+func (sizes *SizerService) CancelScheduled(ctx context.Context, id string) error {
+	sizes.mu.Lock()
+	defer sizes.mu.Unlock()
+
+	for i, change := range sizes.scheduled {
+		if change.ID == id {
+			sizes.scheduled = slices.Delete(sizes.scheduled, i, i+1)
+			return nil
+		}
+	}
+
+	slog.ErrorContext(ctx,
+		ErrorChangeNotFound,
+		"incoming_change", id)
+	return errors.New(ErrorChangeNotFound)
+}
+
+// ApplyDue applies the pending changes whose effective time has come and
+// returns how many were applied. Changes no longer valid are dropped.
+func (sizes *SizerService) ApplyDue(ctx context.Context) int {
+	sizes.mu.Lock()
+	defer sizes.mu.Unlock()
+
+	now, applied := sizes.now(), 0
+	for len(sizes.scheduled) > 0 && !sizes.scheduled[0].EffectiveAt.After(now) {
+		change := sizes.scheduled[0]
+		sizes.scheduled = sizes.scheduled[1:]
+
+		sorted, err := change.apply(sizes.SortedSizes)
+		if err != nil {
+			slog.ErrorContext(ctx,
+				err.Error(),
+				"change", change.ID,
+				"op", change.Op)
+			continue
+		}
+		sizes.SortedSizes = sorted
+		sizes.Version++
+		sizes.record(change.EffectiveAt)
+		applied++
+	}
+
+	return applied
+}
+
+// CatalogAt returns the sizes and their version as of the time. Past times
+// are answered from the history, future ones by applying the pending changes.
+func (sizes *SizerService) CatalogAt(at time.Time) ([]int, int, error) {
+	sizes.mu.RLock()
+	defer sizes.mu.RUnlock()
+
+	return sizes.catalogAt(at)
+}
+
+// catalogAt is CatalogAt for callers holding the lock.
+func (sizes *SizerService) catalogAt(at time.Time) ([]int, int, error) {
+	if n := len(sizes.history); n > 0 && at.Before(sizes.history[n-1].at) {
+		for i := len(sizes.history) - 1; i >= 0; i-- {
+			if !at.Before(sizes.history[i].at) {
+				return slices.Clone(sizes.history[i].sizes), sizes.history[i].version, nil
+			}
+		}
+		return nil, 0, errors.New(ErrorNoCatalogHistory)
+	}
+
+	sorted, version := slices.Clone(sizes.SortedSizes), sizes.Version
+	for _, change := range sizes.scheduled {
+		if change.EffectiveAt.After(at) {
+			break
+		}
+		if next, err := change.apply(sorted); err == nil {
+			sorted, version = next, version+1
+		}
+	}
+
+	return sorted, version, nil
+}
+
+// ApplyDue applies the due changes of every catalog.
+func (catalogs *CatalogService) ApplyDue(ctx context.Context) int {
+	catalogs.mu.RLock()
+	list := make([]*SizerService, 0, len(catalogs.catalogs))
+	for _, catalog := range catalogs.catalogs {
+		list = append(list, catalog)
+	}
+	catalogs.mu.RUnlock()
+
+	applied := 0
+	for _, catalog := range list {
+		applied += catalog.ApplyDue(ctx)
+	}
+
+	return applied
+}
+
+// RunScheduler applies the due catalog changes every interval until the context is done.
+func (catalogs *CatalogService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if applied := catalogs.ApplyDue(ctx); applied > 0 {
+				slog.InfoContext(ctx, "applied scheduled catalog changes",
+					slog.Int("applied", applied),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package packer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizerService_Schedule(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	sizer := NewSizerService([]int{250, 500, 1000})
+	sizer.now = func() time.Time { return now }
+	sizer.history[0].at = start
+
+	november := time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC)
+	december := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	discontinue, err := sizer.Schedule(ctx, ScheduledChange{Op: ChangeDeleteSize, Size: 250, EffectiveAt: november})
+	require.NoError(t, err)
+	_, err = sizer.Schedule(ctx, ScheduledChange{Op: ChangeAddSize, Size: 2000, EffectiveAt: december})
+	require.NoError(t, err)
+	require.Len(t, sizer.ListScheduled(), 2)
+
+	_, err = sizer.Schedule(ctx, ScheduledChange{Op: ChangeDeleteSize, Size: 250, EffectiveAt: december})
+	require.EqualError(t, err, ErrorSizeDoesNotExist)
+	_, err = sizer.Schedule(ctx, ScheduledChange{Op: ChangeAddSize, Size: 5000, EffectiveAt: start})
+	require.EqualError(t, err, ErrorEffectiveAtNotAhead)
+	_, err = sizer.Schedule(ctx, ScheduledChange{Op: "rename", EffectiveAt: december})
+	require.EqualError(t, err, ErrorUnknownChange)
+
+	sizes, version, err := sizer.CatalogAt(november.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []int{500, 1000}, sizes)
+	require.Equal(t, 2, version)
+
+	sizes, version, err = sizer.CatalogAt(december)
+	require.NoError(t, err)
+	require.Equal(t, []int{500, 1000, 2000}, sizes)
+	require.Equal(t, 3, version)
+
+	now = november.Add(time.Minute)
+	require.Equal(t, 1, sizer.ApplyDue(ctx))
+	require.Equal(t, []int{500, 1000}, sizer.ListSizes())
+	require.NotContains(t, sizer.ListScheduled(), discontinue)
+
+	sizes, version, err = sizer.CatalogAt(november.Add(-time.Second))
+	require.NoError(t, err)
+	require.Equal(t, []int{250, 500, 1000}, sizes)
+	require.Equal(t, 1, version)
+
+	_, _, err = sizer.CatalogAt(start.Add(-time.Second))
+	require.EqualError(t, err, ErrorNoCatalogHistory)
+}
+
+func TestSizerService_CancelScheduled(t *testing.T) {
+	ctx := context.Background()
+	sizer := NewSizerService([]int{250, 500})
+
+	change, err := sizer.Schedule(ctx, ScheduledChange{Op: ChangePutSizes, Sizes: []int{100}, EffectiveAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	require.NoError(t, sizer.CancelScheduled(ctx, change.ID))
+	require.Empty(t, sizer.ListScheduled())
+	require.EqualError(t, sizer.CancelScheduled(ctx, change.ID), ErrorChangeNotFound)
+}
+
+func TestPacketsService_GetPacketsAsOf(t *testing.T) {
+	ctx := context.Background()
+	packer := newPacker()
+	sizer, err := packer.Catalogs.GetCatalog(DefaultCatalogID)
+	require.NoError(t, err)
+
+	effectiveAt := time.Now().Add(time.Hour)
+	_, err = sizer.Schedule(ctx, ScheduledChange{Op: ChangeDeleteSize, Size: 250, EffectiveAt: effectiveAt})
+	require.NoError(t, err)
+
+	packets, err := packer.GetPackets(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, map[int]int{250: 1}, packets)
+
+	packets, err = packer.GetPackets(ctx, 1, WithAsOf(effectiveAt))
+	require.NoError(t, err)
+	require.Equal(t, map[int]int{500: 1}, packets)
+
+	_, err = packer.GetPackets(ctx, 1, WithAsOf(time.Unix(0, 0)))
+	require.EqualError(t, err, ErrorNoCatalogHistory)
+}
+
+func TestSizerService_HistoryIsBounded(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	sizer := NewSizerService([]int{250})
+	sizer.now = func() time.Time { return now }
+	sizer.history[0].at = start
+
+	for i := 0; i < maxHistory; i++ {
+		now = now.Add(time.Minute)
+		_, err := sizer.AddSize(ctx, 500)
+		require.NoError(t, err)
+		_, err = sizer.DeleteSize(ctx, 500)
+		require.NoError(t, err)
+	}
+	require.Len(t, sizer.history, maxHistory)
+
+	_, _, err := sizer.CatalogAt(start)
+	require.EqualError(t, err, ErrorNoCatalogHistory)
+	sizes, _, err := sizer.CatalogAt(now.Add(-time.Second))
+	require.NoError(t, err)
+	require.Equal(t, []int{250}, sizes)
+}
+
+func TestSizerService_ScheduledIsBounded(t *testing.T) {
+	ctx := context.Background()
+	sizer := NewSizerService([]int{250})
+	at := time.Now().Add(time.Hour)
+
+	for i := 0; i < maxScheduled; i++ {
+		_, err := sizer.Schedule(ctx, ScheduledChange{Op: ChangePutSizes, Sizes: []int{500}, EffectiveAt: at})
+		require.NoError(t, err)
+	}
+	_, err := sizer.Schedule(ctx, ScheduledChange{Op: ChangePutSizes, Sizes: []int{500}, EffectiveAt: at})
+	require.EqualError(t, err, ErrorTooManyScheduled)
+	require.Len(t, sizer.ListScheduled(), maxScheduled)
+
+	require.NoError(t, sizer.CancelScheduled(ctx, sizer.ListScheduled()[0].ID))
+	_, err = sizer.Schedule(ctx, ScheduledChange{Op: ChangePutSizes, Sizes: []int{500}, EffectiveAt: at})
+	require.NoError(t, err)
+}
@@ -0,0 +1,189 @@
+package packer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
+	"gopkg.in/yaml.v3"
+)
+
+// ERR consts ...
+const (
+	ErrorUnknownSeedFormat  = "catalog seed file must be .yaml, .yml or .json"
+	ErrorSeedWithoutDefault = "catalog seed file must hold the default catalog"
+)
+
+// SeedError is a problem found at a line of a catalog seed file.
+type SeedError struct {
+	Line    int
+	Catalog string
+	Reason  string
+}
+
+// Error implements the error interface.
+func (e *SeedError) Error() string {
+	if e.Catalog == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+	}
+	return fmt.Sprintf("line %d: catalog %s: %s", e.Line, e.Catalog, e.Reason)
+}
+
+// LoadSeed reads the initial catalogs from a YAML or JSON file. The file holds
+// the sizes of every catalog by ID:
+//
+//	catalogs:
+//	  default: [250, 500, 1000]
+//	  eu: [100, 200]
+func LoadSeed(path string) (map[string][]int, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return nil, errors.New(ErrorUnknownSeedFormat)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := ParseSeed(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return seed, nil
+}
+
+// ParseSeed decodes and validates a catalog seed. JSON is parsed as the YAML
+// subset it is, so both formats report the lines of their problems. Sizes
+// follow the rules of AddSize: positive and not duplicated within a catalog.
+// Every problem of the file is returned joined.
+func ParseSeed(data []byte) (map[string][]int, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, &SeedError{Line: 1, Reason: "document must be a mapping with catalogs"}
+	}
+
+	var catalogsNode *yaml.Node
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "catalogs" {
+			catalogsNode = root.Content[i+1]
+		}
+	}
+	if catalogsNode == nil || catalogsNode.Kind != yaml.MappingNode || len(catalogsNode.Content) == 0 {
+		return nil, &SeedError{Line: root.Line, Reason: "catalogs must be a mapping of catalog IDs to sizes"}
+	}
+
+	var errs []error
+	seed := make(map[string][]int)
+	for i := 0; i+1 < len(catalogsNode.Content); i += 2 {
+		key, list := catalogsNode.Content[i], catalogsNode.Content[i+1]
+		id := key.Value
+		switch _, exists := seed[id]; {
+		case !validator.Matches(id, validator.IDRX):
+			errs = append(errs, &SeedError{Line: key.Line, Catalog: id, Reason: "id must be 1-64 letters, digits, dashes or underscores"})
+			continue
+		case exists:
+			errs = append(errs, &SeedError{Line: key.Line, Catalog: id, Reason: ErrorCatalogExists})
+			continue
+		case list.Kind != yaml.SequenceNode || len(list.Content) == 0:
+			errs = append(errs, &SeedError{Line: list.Line, Catalog: id, Reason: ErrorZeroSizesQuantity})
+			continue
+		}
+
+		sizes := make([]int, 0, len(list.Content))
+		lines := make(map[int]int)
+		for _, item := range list.Content {
+			size, err := strconv.Atoi(item.Value)
+			switch {
+			case item.Kind != yaml.ScalarNode || err != nil:
+				errs = append(errs, &SeedError{Line: item.Line, Catalog: id, Reason: "size must be an integer"})
+			case size <= 0:
+				errs = append(errs, &SeedError{Line: item.Line, Catalog: id, Reason: ErrorNegativeOrZeroSize})
+			case lines[size] != 0:
+				errs = append(errs, &SeedError{Line: item.Line, Catalog: id, Reason: fmt.Sprintf("size %d repeats line %d", size, lines[size])})
+			default:
+				lines[size] = item.Line
+				sizes = append(sizes, size)
+			}
+		}
+		seed[id] = sizes
+	}
+	if _, exists := seed[DefaultCatalogID]; !exists && len(errs) == 0 {
+		errs = append(errs, &SeedError{Line: catalogsNode.Line, Reason: ErrorSeedWithoutDefault})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return seed, nil
+}
+
+// NewSeededCatalogService is a constructor of the CatalogService holding the
+// catalogs of the seed. The seed must hold the default catalog.
+func NewSeededCatalogService(ctx context.Context, seed map[string][]int) (*CatalogService, error) {
+	sizes, exists := seed[DefaultCatalogID]
+	if !exists {
+		return nil, errors.New(ErrorSeedWithoutDefault)
+	}
+
+	catalogs := NewCatalogService(NewSizerService(sizes))
+	for id, sizes := range seed {
+		if id == DefaultCatalogID {
+			continue
+		}
+		if err := catalogs.AddCatalog(ctx, id, NewSizerService(sizes)); err != nil {
+			return nil, err
+		}
+	}
+
+	return catalogs, nil
+}
+
+// Reseed replaces the sizes of the catalogs of the seed and adds the ones not
+// registered yet. Catalogs missing from the seed are kept as they are. Every
+// catalog is checked before any is changed, so either all of them are
+// reseeded or none; catalogs whose sizes are unchanged keep their version.
+func (catalogs *CatalogService) Reseed(ctx context.Context, seed map[string][]int) error {
+	catalogs.mu.Lock()
+	defer catalogs.mu.Unlock()
+
+	for id, sizes := range seed {
+		if _, err := (ScheduledChange{Op: ChangePutSizes, Sizes: sizes}).apply(nil); err != nil {
+			slog.ErrorContext(ctx,
+				err.Error(),
+				"incoming_catalog", id)
+			return err
+		}
+	}
+
+	for id, sizes := range seed {
+		catalog, exists := catalogs.catalogs[id]
+		if !exists {
+			catalogs.catalogs[id] = NewSizerService(slices.Clone(sizes))
+			continue
+		}
+		sorted := slices.Clone(sizes)
+		slices.Sort(sorted)
+		if slices.Equal(catalog.ListSizes(), sorted) {
+			continue
+		}
+		if _, err := catalog.PutSizes(ctx, sorted); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package packer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeed(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		want     map[string][]int
+		wantErrs []string
+	}{
+		{
+			name: "yaml",
+			data: "catalogs:\n  default: [250, 500]\n  eu:\n    - 100\n",
+			want: map[string][]int{DefaultCatalogID: {250, 500}, "eu": {100}},
+		},
+		{
+			name: "json",
+			data: `{"catalogs": {"default": [250, 500, 1000]}}`,
+			want: map[string][]int{DefaultCatalogID: {250, 500, 1000}},
+		},
+		{
+			name: "invalid sizes reference their lines",
+			data: "catalogs:\n  default:\n    - 250\n    - -1\n    - 250\n  eu:\n    - big\n",
+			wantErrs: []string{
+				"line 4: catalog default: " + ErrorNegativeOrZeroSize,
+				"line 5: catalog default: size 250 repeats line 3",
+				"line 7: catalog eu: size must be an integer",
+			},
+		},
+		{
+			name: "json invalid sizes reference their lines",
+			data: "{\n  \"catalogs\": {\n    \"default\": [\n      0\n    ]\n  }\n}",
+			wantErrs: []string{
+				"line 4: catalog default: " + ErrorNegativeOrZeroSize,
+			},
+		},
+		{
+			name:     "empty catalog",
+			data:     "catalogs:\n  default: []\n",
+			wantErrs: []string{"line 2: catalog default: " + ErrorZeroSizesQuantity},
+		},
+		{
+			name:     "no default catalog",
+			data:     "catalogs:\n  eu: [100]\n",
+			wantErrs: []string{"line 2: " + ErrorSeedWithoutDefault},
+		},
+		{
+			name:     "no catalogs",
+			data:     "sizes: [250]\n",
+			wantErrs: []string{"line 1: catalogs must be a mapping of catalog IDs to sizes"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			seed, err := ParseSeed([]byte(tc.data))
+			if len(tc.wantErrs) > 0 {
+				require.Error(t, err)
+				for _, want := range tc.wantErrs {
+					require.Contains(t, err.Error(), want)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, seed)
+		})
+	}
+}
+
+func TestLoadSeed(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "catalogs.yml")
+	require.NoError(t, os.WriteFile(path, []byte("catalogs:\n  default: [500, 250]\n  eu: [100]\n"), 0o600))
+
+	seed, err := LoadSeed(path)
+	require.NoError(t, err)
+
+	catalogs, err := NewSeededCatalogService(context.Background(), seed)
+	require.NoError(t, err)
+	require.Equal(t, []string{DefaultCatalogID, "eu"}, catalogs.ListCatalogs())
+	sizer, err := catalogs.GetCatalog("")
+	require.NoError(t, err)
+	require.Equal(t, []int{250, 500}, sizer.ListSizes())
+
+	_, err = LoadSeed(filepath.Join(dir, "catalogs.toml"))
+	require.EqualError(t, err, ErrorUnknownSeedFormat)
+	_, err = LoadSeed(filepath.Join(dir, "missing.json"))
+	require.Error(t, err)
+}
+
+func TestCatalogService_Reseed(t *testing.T) {
+	ctx := context.Background()
+	catalogs, err := NewSeededCatalogService(ctx, map[string][]int{DefaultCatalogID: {250, 500}, "eu": {100}})
+	require.NoError(t, err)
+	sizer, _ := catalogs.GetCatalog("")
+	eu, _ := catalogs.GetCatalog("eu")
+
+	err = catalogs.Reseed(ctx, map[string][]int{DefaultCatalogID: {1000, 250}, "eu": {100}, "us": {50}})
+	require.NoError(t, err)
+	require.Equal(t, []int{250, 1000}, sizer.ListSizes())
+	require.Equal(t, 2, sizer.Version)
+	require.Equal(t, 1, eu.Version)
+	require.Equal(t, []string{DefaultCatalogID, "eu", "us"}, catalogs.ListCatalogs())
+
+	err = catalogs.Reseed(ctx, map[string][]int{DefaultCatalogID: {5000}, "eu": {100, 100}})
+	require.EqualError(t, err, ErrorDuplicatedSizes)
+	require.Equal(t, []int{250, 1000}, sizer.ListSizes())
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"sort"
+	"sync"
+	"time"
 
 	"golang.org/x/exp/slices"
 	"golang.org/x/exp/slog"
@@ -26,6 +28,13 @@ var _ Sizer = &SizerService{}
 // SizerService ...
 type SizerService struct {
 	SortedSizes []int
+	// Version is incremented on every change of the sizes.
+	Version int
+
+	mu        sync.RWMutex
+	now       func() time.Time
+	history   []snapshot
+	scheduled []ScheduledChange
 }
 
 // NewSizerService ...
@@ -36,23 +45,40 @@ func NewSizerService(sizes []int) *SizerService {
 
 	sizesSrvc := &SizerService{
 		SortedSizes: sizes,
+		Version:     1,
+		now:         time.Now,
 	}
 	sort.Ints(sizesSrvc.SortedSizes)
+	sizesSrvc.record(sizesSrvc.now())
 
 	return sizesSrvc
 }
 
 // ListSizes ...
 func (sizes *SizerService) ListSizes() []int {
-	return sizes.SortedSizes
+	sizes.mu.RLock()
+	defer sizes.mu.RUnlock()
+
+	return slices.Clone(sizes.SortedSizes)
+}
+
+// Catalog returns a copy of the sizes together with their version.
+func (sizes *SizerService) Catalog() ([]int, int) {
+	sizes.mu.RLock()
+	defer sizes.mu.RUnlock()
+
+	return slices.Clone(sizes.SortedSizes), sizes.Version
 }
 
 // AddSize ...
 func (sizes *SizerService) AddSize(ctx context.Context, sizeToAdd int) ([]int, error) {
+	sizes.mu.Lock()
+	defer sizes.mu.Unlock()
+
 	if sizeToAdd <= 0 {
 		return []int{}, errors.New(ErrorNegativeOrZeroSize)
 	}
-	if sizes.Exists(sizeToAdd) {
+	if sizes.exists(sizeToAdd) {
 		slog.ErrorContext(ctx,
 			ErrorDuplicatedSizes,
 			slog.Any("incoming_size", sizeToAdd),
@@ -62,12 +88,17 @@ func (sizes *SizerService) AddSize(ctx context.Context, sizeToAdd int) ([]int, e
 	}
 
 	sizes.SortedSizes = insertSorted(sizes.SortedSizes, sizeToAdd)
+	sizes.Version++
+	sizes.record(sizes.now())
 
-	return sizes.SortedSizes, nil
+	return slices.Clone(sizes.SortedSizes), nil
 }
 
 // PutSizes ...
 func (sizes *SizerService) PutSizes(ctx context.Context, sizesToPut []int) ([]int, error) {
+	sizes.mu.Lock()
+	defer sizes.mu.Unlock()
+
 	if len(sizesToPut) == 0 {
 		return []int{}, errors.New(ErrorZeroSizesQuantity)
 	}
@@ -96,12 +127,17 @@ func (sizes *SizerService) PutSizes(ctx context.Context, sizesToPut []int) ([]in
 	sizes.SortedSizes = []int{}
 	slices.Sort(sizesToPut)
 	sizes.SortedSizes = append(sizes.SortedSizes, sizesToPut...)
+	sizes.Version++
+	sizes.record(sizes.now())
 
-	return sizes.SortedSizes, nil
+	return slices.Clone(sizes.SortedSizes), nil
 }
 
 // DeleteSize ...
 func (sizes *SizerService) DeleteSize(ctx context.Context, sizeToDelete int) ([]int, error) {
+	sizes.mu.Lock()
+	defer sizes.mu.Unlock()
+
 	if sizeToDelete <= 0 {
 		slog.ErrorContext(ctx,
 			ErrorNegativeOrZeroSize,
@@ -111,7 +147,7 @@ func (sizes *SizerService) DeleteSize(ctx context.Context, sizeToDelete int) ([]
 		return []int{}, errors.New(ErrorNegativeOrZeroSize)
 	}
 
-	if !sizes.Exists(sizeToDelete) {
+	if !sizes.exists(sizeToDelete) {
 		slog.ErrorContext(ctx,
 			ErrorSizeDoesNotExist,
 			slog.Any("incoming_size", sizeToDelete),
@@ -122,12 +158,22 @@ func (sizes *SizerService) DeleteSize(ctx context.Context, sizeToDelete int) ([]
 
 	indexOfSizeToDelete, _ := slices.BinarySearch(sizes.SortedSizes, sizeToDelete)
 	sizes.SortedSizes = slices.Delete(sizes.SortedSizes, indexOfSizeToDelete, indexOfSizeToDelete+1)
+	sizes.Version++
+	sizes.record(sizes.now())
 
-	return sizes.SortedSizes, nil
+	return slices.Clone(sizes.SortedSizes), nil
 }
 
 // Exists ...
 func (sizes *SizerService) Exists(sizeToCheckFor int) bool {
+	sizes.mu.RLock()
+	defer sizes.mu.RUnlock()
+
+	return sizes.exists(sizeToCheckFor)
+}
+
+// exists reports whether the size is in the catalog. The caller must hold the lock.
+func (sizes *SizerService) exists(sizeToCheckFor int) bool {
 	_, exists := slices.BinarySearch(sizes.SortedSizes, sizeToCheckFor)
 	return exists
 }
@@ -234,3 +234,18 @@ func TestSizerService_DeleteSize(t *testing.T) {
 		})
 	}
 }
+
+func TestSizerService_ReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	sizer := NewSizerService([]int{250, 500, 1000})
+
+	listed := sizer.ListSizes()
+	added, err := sizer.AddSize(ctx, 750)
+	require.NoError(t, err)
+	_, err = sizer.DeleteSize(ctx, 250)
+	require.NoError(t, err)
+
+	require.Equal(t, []int{250, 500, 1000}, listed)
+	require.Equal(t, []int{250, 500, 750, 1000}, added)
+	require.Equal(t, []int{500, 750, 1000}, sizer.ListSizes())
+}
@@ -0,0 +1,275 @@
+package packer
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of exported and imported catalogs.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Modes of a catalog import.
+const (
+	ImportReplace = "replace"
+	ImportMerge   = "merge"
+)
+
+// Statuses of an imported row.
+const (
+	RowImported = "imported"
+	RowSkipped  = "skipped"
+	RowInvalid  = "invalid"
+)
+
+// ERR consts ...
+const (
+	ErrorUnknownFormat     = "format must be one of csv, json or yaml"
+	ErrorUnknownImportMode = "mode must be one of replace or merge"
+	ErrorMalformedCatalog  = "catalog file is malformed"
+	ErrorInvalidRows       = "catalog file contains invalid rows"
+)
+
+// catalogFile is the layout of JSON and YAML catalog files.
+type catalogFile struct {
+	Sizes []int `json:"sizes" yaml:"sizes"`
+}
+
+// ImportRow is the validation report of a single row of an imported catalog.
+// Row is the line in CSV and YAML files and the index of the size in JSON ones.
+type ImportRow struct {
+	Row    int    `json:"row"`
+	Value  string `json:"value"`
+	Size   int    `json:"size,omitempty"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ImportReport holds the report of every row of an imported catalog.
+type ImportReport struct {
+	Mode  string      `json:"mode"`
+	Valid bool        `json:"valid"`
+	Rows  []ImportRow `json:"rows"`
+	Sizes []int       `json:"sizes"`
+}
+
+// ExportSizes encodes the sizes in the format.
+func ExportSizes(sizes []int, format string) ([]byte, error) {
+	switch format {
+	case FormatCSV:
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		records := [][]string{{"size"}}
+		for _, size := range sizes {
+			records = append(records, []string{strconv.Itoa(size)})
+		}
+		if err := w.WriteAll(records); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case FormatJSON:
+		return json.MarshalIndent(catalogFile{Sizes: sizes}, "", "\t")
+	case FormatYAML:
+		return yaml.Marshal(catalogFile{Sizes: sizes})
+	}
+	return nil, errors.New(ErrorUnknownFormat)
+}
+
+// rawRow is a value read from a catalog file before validation.
+type rawRow struct {
+	row   int
+	value string
+}
+
+// ParseSizes decodes a catalog file and validates every row of it. Rows are
+// invalid when they hold no positive integer or repeat a size of an earlier row.
+func ParseSizes(data []byte, format string) ([]ImportRow, error) {
+	var (
+		raw []rawRow
+		err error
+	)
+	switch format {
+	case FormatCSV:
+		raw, err = readCSVRows(data)
+	case FormatJSON:
+		raw, err = readJSONRows(data)
+	case FormatYAML:
+		raw, err = readYAMLRows(data)
+	default:
+		return nil, errors.New(ErrorUnknownFormat)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrorMalformedCatalog, err)
+	}
+
+	rows := make([]ImportRow, 0, len(raw))
+	seen := make(map[int]int)
+	for _, r := range raw {
+		row := ImportRow{Row: r.row, Value: r.value, Status: RowImported}
+		size, err := strconv.Atoi(strings.TrimSpace(r.value))
+		switch {
+		case err != nil:
+			row.Status, row.Reason = RowInvalid, "size must be an integer"
+		case size <= 0:
+			row.Status, row.Reason = RowInvalid, ErrorNegativeOrZeroSize
+		default:
+			row.Size = size
+			if first, exists := seen[size]; exists {
+				row.Status, row.Reason = RowInvalid, fmt.Sprintf("size repeats row %d", first)
+			} else {
+				seen[size] = r.row
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// readCSVRows reads the first column of every record, skipping a "size" header.
+func readCSVRows(data []byte) ([]rawRow, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var rows []rawRow
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		if len(rows) == 0 && line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "size") {
+			continue
+		}
+		rows = append(rows, rawRow{row: line, value: record[0]})
+	}
+
+	return rows, nil
+}
+
+// readJSONRows reads the sizes of a {"sizes": [...]} document keeping them raw.
+func readJSONRows(data []byte) ([]rawRow, error) {
+	var file struct {
+		Sizes []json.RawMessage `json:"sizes"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	rows := make([]rawRow, 0, len(file.Sizes))
+	for i, value := range file.Sizes {
+		rows = append(rows, rawRow{row: i + 1, value: string(value)})
+	}
+
+	return rows, nil
+}
+
+// readYAMLRows reads the sizes of a "sizes:" document with their line numbers.
+func readYAMLRows(data []byte) ([]rawRow, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: document must be a mapping with sizes", root.Line)
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "sizes" {
+			continue
+		}
+		list := root.Content[i+1]
+		if list.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("line %d: sizes must be a list", list.Line)
+		}
+		rows := make([]rawRow, 0, len(list.Content))
+		for _, item := range list.Content {
+			rows = append(rows, rawRow{row: item.Line, value: item.Value})
+		}
+		return rows, nil
+	}
+
+	return nil, nil
+}
+
+// ImportSizes validates the catalog file and, when every row is valid, applies
+// it atomically. Replace makes the file the catalog, merge adds the sizes of
+// the file to it. The report is returned whether the import is applied or not.
+func (sizes *SizerService) ImportSizes(ctx context.Context, data []byte, format, mode string) (ImportReport, error) {
+	if mode != ImportReplace && mode != ImportMerge {
+		return ImportReport{}, errors.New(ErrorUnknownImportMode)
+	}
+
+	rows, err := ParseSizes(data, format)
+	if err != nil {
+		slog.ErrorContext(ctx,
+			err.Error(),
+			"incoming_format", format)
+		return ImportReport{}, err
+	}
+
+	if mode == ImportReplace && len(rows) == 0 {
+		return ImportReport{}, errors.New(ErrorZeroSizesQuantity)
+	}
+
+	sizes.mu.Lock()
+	defer sizes.mu.Unlock()
+
+	report := ImportReport{Mode: mode, Valid: true, Rows: rows}
+	var imported []int
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		switch {
+		case row.Status == RowInvalid:
+			report.Valid = false
+		case mode == ImportMerge && sizes.exists(row.Size):
+			row.Status, row.Reason = RowSkipped, "size already exists"
+		default:
+			imported = append(imported, row.Size)
+		}
+	}
+	if !report.Valid {
+		report.Sizes = slices.Clone(sizes.SortedSizes)
+		slog.ErrorContext(ctx,
+			ErrorInvalidRows,
+			"incoming_format", format,
+			"incoming_mode", mode)
+		return report, errors.New(ErrorInvalidRows)
+	}
+
+	next := slices.Clone(imported)
+	if mode == ImportMerge {
+		next = append(next, sizes.SortedSizes...)
+	}
+	slices.Sort(next)
+	if len(imported) > 0 {
+		sizes.SortedSizes = next
+		sizes.Version++
+		sizes.record(sizes.now())
+	}
+	report.Sizes = slices.Clone(sizes.SortedSizes)
+
+	return report, nil
+}
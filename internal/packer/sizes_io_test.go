@@ -0,0 +1,143 @@
+package packer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportSizes(t *testing.T) {
+	sizes := []int{250, 500, 1000}
+
+	for _, format := range []string{FormatCSV, FormatJSON, FormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			data, err := ExportSizes(sizes, format)
+			require.NoError(t, err)
+
+			rows, err := ParseSizes(data, format)
+			require.NoError(t, err)
+			require.Len(t, rows, len(sizes))
+			for i, row := range rows {
+				require.Equal(t, RowImported, row.Status)
+				require.Equal(t, sizes[i], row.Size)
+			}
+		})
+	}
+
+	_, err := ExportSizes(sizes, "xml")
+	require.EqualError(t, err, ErrorUnknownFormat)
+}
+
+func TestParseSizes(t *testing.T) {
+	testCases := []struct {
+		name       string
+		format     string
+		data       string
+		wantErr    bool
+		wantRows   []int
+		wantStatus []string
+	}{
+		{
+			name:       "csv with header",
+			format:     FormatCSV,
+			data:       "size\n250\nabc\n-5\n250\n",
+			wantRows:   []int{2, 3, 4, 5},
+			wantStatus: []string{RowImported, RowInvalid, RowInvalid, RowInvalid},
+		},
+		{
+			name:       "csv without header",
+			format:     FormatCSV,
+			data:       "250,small\n500,medium\n",
+			wantRows:   []int{1, 2},
+			wantStatus: []string{RowImported, RowImported},
+		},
+		{
+			name:       "json",
+			format:     FormatJSON,
+			data:       `{"sizes": [250, "500", 1.5]}`,
+			wantRows:   []int{1, 2, 3},
+			wantStatus: []string{RowImported, RowInvalid, RowInvalid},
+		},
+		{
+			name:       "yaml reports lines",
+			format:     FormatYAML,
+			data:       "# pack sizes\nsizes:\n  - 250\n  - 0\n  - 500\n",
+			wantRows:   []int{3, 4, 5},
+			wantStatus: []string{RowImported, RowInvalid, RowImported},
+		},
+		{
+			name:    "malformed json",
+			format:  FormatJSON,
+			data:    `{"sizes": [250`,
+			wantErr: true,
+		},
+		{
+			name:    "yaml sizes not a list",
+			format:  FormatYAML,
+			data:    "sizes: 250\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := ParseSizes([]byte(tc.data), tc.format)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, rows, len(tc.wantRows))
+			for i, row := range rows {
+				require.Equal(t, tc.wantRows[i], row.Row)
+				require.Equal(t, tc.wantStatus[i], row.Status, row.Reason)
+			}
+		})
+	}
+}
+
+func TestSizerService_ImportSizes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("merge skips existing sizes", func(t *testing.T) {
+		sizer := NewSizerService([]int{250, 500})
+		report, err := sizer.ImportSizes(ctx, []byte("size\n500\n2000\n"), FormatCSV, ImportMerge)
+		require.NoError(t, err)
+		require.True(t, report.Valid)
+		require.Equal(t, RowSkipped, report.Rows[0].Status)
+		require.Equal(t, RowImported, report.Rows[1].Status)
+		require.Equal(t, []int{250, 500, 2000}, sizer.ListSizes())
+		require.Equal(t, 2, sizer.Version)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		sizer := NewSizerService([]int{250, 500})
+		report, err := sizer.ImportSizes(ctx, []byte("sizes: [1000, 100]\n"), FormatYAML, ImportReplace)
+		require.NoError(t, err)
+		require.Equal(t, []int{100, 1000}, report.Sizes)
+		require.Equal(t, []int{100, 1000}, sizer.ListSizes())
+	})
+
+	t.Run("invalid rows apply nothing", func(t *testing.T) {
+		sizer := NewSizerService([]int{250, 500})
+		report, err := sizer.ImportSizes(ctx, []byte(`{"sizes": [1000, 1000]}`), FormatJSON, ImportReplace)
+		require.EqualError(t, err, ErrorInvalidRows)
+		require.False(t, report.Valid)
+		require.Equal(t, RowInvalid, report.Rows[1].Status)
+		require.Equal(t, []int{250, 500}, sizer.ListSizes())
+		require.Equal(t, 1, sizer.Version)
+	})
+
+	t.Run("replace with no sizes", func(t *testing.T) {
+		sizer := NewSizerService([]int{250, 500})
+		_, err := sizer.ImportSizes(ctx, []byte("size\n"), FormatCSV, ImportReplace)
+		require.EqualError(t, err, ErrorZeroSizesQuantity)
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		sizer := NewSizerService([]int{250, 500})
+		_, err := sizer.ImportSizes(ctx, []byte("size\n"), FormatCSV, "append")
+		require.EqualError(t, err, ErrorUnknownImportMode)
+	})
+}
@@ -0,0 +1,302 @@
+package packer
+
+import (
+	"errors"
+	"math"
+
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// maxSolverSpan caps the size of the solver table to keep memory bounded.
+	maxSolverSpan = 1_000_000
+
+	// unreachable marks totals that cannot be composed from the allowed packs.
+	unreachable = math.MaxInt32
+)
+
+// ERR consts ...
+const (
+	ErrorSolverSpanExceeded = "order is too large to be packed with the given sizes and constraints"
+)
+
+// solution is a packing with its total items and number of packs.
+type solution struct {
+	Packs map[int]int
+	Total int
+	Count int
+}
+
+// solver holds the table of the minimum number of packs needed to compose every
+// exact total up to span, on top of the packs that are forced in advance.
+type solver struct {
+	sizes     []int
+	items     int
+	base      map[int]int
+	baseTotal int
+	baseCount int
+	target    int
+	maxPacks  int
+	maxTotal  int
+	packs     []int32
+	take      [][]int32
+}
+
+// solve packs the items with the given catalog, constraints and rounding policy.
+func solve(catalog []int, items int, c Constraints, rounding string) (solution, error) {
+	s, err := newSolver(catalog, items, c, rounding)
+	if err != nil {
+		return solution{}, err
+	}
+
+	return s.best(rounding)
+}
+
+// newSolver validates the constraints against the catalog and builds the solver
+// table for the rounding policy.
+func newSolver(catalog []int, items int, c Constraints, rounding string) (*solver, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	var allowed []int
+	for _, size := range catalog {
+		if !c.isExcluded(size) {
+			allowed = append(allowed, size)
+		}
+	}
+	slices.Sort(allowed)
+	if len(allowed) == 0 {
+		return nil, newInfeasibleError(ConstraintExcludedSizes, "all sizes are excluded")
+	}
+
+	s := &solver{items: items, base: make(map[int]int), maxPacks: -1}
+	for size, quantity := range c.MinPerSize {
+		if quantity == 0 {
+			continue
+		}
+		if c.isExcluded(size) {
+			return nil, newInfeasibleError(ConstraintExcludedSizes, "size %d is excluded but requires at least %d packs", size, quantity)
+		}
+		if !slices.Contains(allowed, size) {
+			return nil, newInfeasibleError(ConstraintMinPerSize, "size %d is not in the catalog", size)
+		}
+		if upper, ok := c.maxFor(size); ok && upper < quantity {
+			return nil, newInfeasibleError(ConstraintMaxPerSize, "size %d allows at most %d packs but requires at least %d", size, upper, quantity)
+		}
+		s.base[size] = quantity
+		s.baseTotal += size * quantity
+		s.baseCount += quantity
+	}
+	if c.MaxTotalPacks > 0 && s.baseCount > c.MaxTotalPacks {
+		return nil, newInfeasibleError(ConstraintMaxTotalPacks, "minimum packs per size require %d packs but at most %d are allowed", s.baseCount, c.MaxTotalPacks)
+	}
+
+	s.target = items - s.baseTotal
+	if s.target < 0 {
+		s.target = 0
+	}
+
+	// Any solution using many small packs can trade them for fewer largest packs,
+	// so the bulk of a large order is prefilled with the largest size. A trade
+	// adds up to step largest packs, so a bounded largest size is prefilled only
+	// as far as every trade still fits under its bound.
+	largest := allowed[len(allowed)-1]
+	bound, step := 0, 1
+	for _, size := range allowed[:len(allowed)-1] {
+		g := gcd(size, largest)
+		bound += (largest/g - 1) * size
+		step = max(step, size/g)
+	}
+	prefill := (s.target-bound)/largest - 1
+	if upper, bounded := c.maxFor(largest); bounded {
+		prefill = min(prefill, upper-s.base[largest]-step+1)
+	}
+	// Rounding down or to the nearest may ship less than the items, so a pack
+	// cap bounds the prefill rather than making the order infeasible.
+	if c.MaxTotalPacks > 0 && rounding != RoundingUp {
+		prefill = min(prefill, c.MaxTotalPacks-s.baseCount)
+	}
+	if prefill > 0 {
+		s.base[largest] += prefill
+		s.baseTotal += prefill * largest
+		s.baseCount += prefill
+		s.target -= prefill * largest
+	}
+	if c.MaxTotalPacks > 0 {
+		s.maxTotal = c.MaxTotalPacks
+		s.maxPacks = c.MaxTotalPacks - s.baseCount
+		if s.maxPacks < 0 {
+			return nil, newInfeasibleError(ConstraintMaxTotalPacks, "at least %d packs are needed but at most %d are allowed", s.baseCount, c.MaxTotalPacks)
+		}
+	}
+
+	span := s.target + largest - 1
+	if rounding == RoundingDown && s.maxPacks >= 0 {
+		// Totals above the cap in largest packs are out of reach.
+		span = min(span, s.maxPacks*largest)
+	}
+	if span > maxSolverSpan {
+		return nil, errors.New(ErrorSolverSpanExceeded)
+	}
+
+	s.sizes = allowed
+	s.fill(span, c)
+
+	return s, nil
+}
+
+// fill computes the minimum number of packs for every exact total in [0, span]
+// with a bounded knapsack, using a monotone queue per residue of each size.
+func (s *solver) fill(span int, c Constraints) {
+	prev := make([]int32, span+1)
+	for t := 1; t <= span; t++ {
+		prev[t] = unreachable
+	}
+
+	s.take = make([][]int32, len(s.sizes))
+	queue := make([]int, 0, span+1)
+	for i, size := range s.sizes {
+		bound := span / size
+		if upper, ok := c.maxFor(size); ok {
+			upper -= s.base[size]
+			if upper < bound {
+				bound = upper
+			}
+		}
+
+		cur := make([]int32, span+1)
+		take := make([]int32, span+1)
+		for r := 0; r < size && r <= span; r++ {
+			queue = queue[:0]
+			head := 0
+			for j := 0; r+j*size <= span; j++ {
+				t := r + j*size
+				if prev[t] != unreachable {
+					value := prev[t] - int32(j)
+					for len(queue) > head && prev[r+queue[len(queue)-1]*size]-int32(queue[len(queue)-1]) >= value {
+						queue = queue[:len(queue)-1]
+					}
+					queue = append(queue, j)
+				}
+				for len(queue) > head && queue[head] < j-bound {
+					head++
+				}
+				if len(queue) == head {
+					cur[t] = unreachable
+					continue
+				}
+				best := queue[head]
+				cur[t] = prev[r+best*size] - int32(best) + int32(j)
+				take[t] = int32(j - best)
+			}
+		}
+		prev = cur
+		s.take[i] = take
+	}
+	s.packs = prev
+}
+
+// withinBudget reports whether the exact total t is reachable within the pack
+// budget. A negative budget means the number of packs is not limited.
+func (s *solver) withinBudget(t int) bool {
+	if s.packs[t] == unreachable {
+		return false
+	}
+	return s.maxPacks < 0 || int(s.packs[t]) <= s.maxPacks
+}
+
+// best returns the solution preferred by the rounding policy.
+func (s *solver) best(rounding string) (solution, error) {
+	switch rounding {
+	case RoundingDown:
+		return s.atMost()
+	case RoundingNearest:
+		return s.nearest()
+	}
+	return s.atLeast()
+}
+
+// atLeast returns the solution with the smallest total covering the items and,
+// among those, the fewest packs.
+func (s *solver) atLeast() (solution, error) {
+	reachable := false
+	for t := s.target; t < len(s.packs); t++ {
+		if s.packs[t] == unreachable {
+			continue
+		}
+		reachable = true
+		if s.withinBudget(t) {
+			return s.solution(t), nil
+		}
+	}
+	if reachable {
+		return solution{}, newInfeasibleError(ConstraintMaxTotalPacks, "items cannot be covered with at most %d packs", s.maxTotal)
+	}
+	return solution{}, newInfeasibleError(ConstraintMaxPerSize, "items cannot be covered with the allowed packs per size")
+}
+
+// atMost returns the solution with the largest total not exceeding the items and,
+// among those, the fewest packs.
+func (s *solver) atMost() (solution, error) {
+	limit := s.items - s.baseTotal
+	if limit < 0 {
+		return solution{}, newInfeasibleError(ConstraintMinPerSize, "minimum packs per size exceed %d items", s.items)
+	}
+	if limit >= len(s.packs) {
+		limit = len(s.packs) - 1
+	}
+	for t := limit; t >= 0; t-- {
+		if t+s.baseTotal > 0 && s.withinBudget(t) {
+			return s.solution(t), nil
+		}
+	}
+	return solution{}, newInfeasibleError(ConstraintRounding, "no packing ships at most %d items", s.items)
+}
+
+// nearest returns the solution with the total closest to the items, preferring
+// to cover the items on a tie.
+func (s *solver) nearest() (solution, error) {
+	above, aboveErr := s.atLeast()
+	below, belowErr := s.atMost()
+	switch {
+	case aboveErr != nil && belowErr != nil:
+		return solution{}, aboveErr
+	case belowErr != nil:
+		return above, nil
+	case aboveErr != nil:
+		return below, nil
+	case s.items-below.Total < above.Total-s.items:
+		return below, nil
+	}
+	return above, nil
+}
+
+// solution reconstructs the packs used for the exact total t, including base packs.
+func (s *solver) solution(t int) solution {
+	packs := make(map[int]int, len(s.base)+len(s.sizes))
+	for size, quantity := range s.base {
+		packs[size] += quantity
+	}
+	total, count := t+s.baseTotal, int(s.packs[t])+s.baseCount
+	for i := len(s.sizes) - 1; i >= 0; i-- {
+		quantity := int(s.take[i][t])
+		if quantity > 0 {
+			packs[s.sizes[i]] += quantity
+			t -= quantity * s.sizes[i]
+		}
+	}
+	return solution{
+		Packs: packs,
+		Total: total,
+		Count: count,
+	}
+}
+
+// gcd returns the greatest common divisor of a and b.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
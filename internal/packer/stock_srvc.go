@@ -0,0 +1,292 @@
+package packer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slog"
+)
+
+// DefaultReservationTTL is how long a reservation holds stock when the request names no TTL.
+const DefaultReservationTTL = 15 * time.Minute
+
+// ReservationRetention is how long reservations no longer held are kept past
+// their expiry, so that clients can still look up how they ended.
+const ReservationRetention = 24 * time.Hour
+
+// Statuses of a reservation.
+const (
+	ReservationHeld      = "held"
+	ReservationConfirmed = "confirmed"
+	ReservationCancelled = "cancelled"
+	ReservationExpired   = "expired"
+)
+
+// ERR consts ...
+const (
+	ErrorInsufficientStock   = "not enough stock to hold the packets"
+	ErrorReservationNotFound = "reservation does not exist"
+	ErrorReservationNotHeld  = "reservation is no longer held"
+	ErrorEmptyReservation    = "reservation must hold at least one pack"
+	ErrorNegativeStock       = "stock must not be negative"
+	ErrorNegativeOrZeroTTL   = "ttl must be more than 0"
+)
+
+// Reservation holds pack counts of a catalog until it is confirmed, cancelled
+// or expires.
+type Reservation struct {
+	ID        string      `json:"id"`
+	CatalogID string      `json:"catalog"`
+	Packets   map[int]int `json:"packets"`
+	Status    string      `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// StockLevel is the stock of a single pack size.
+type StockLevel struct {
+	OnHand    int `json:"on_hand"`
+	Held      int `json:"held"`
+	Available int `json:"available"`
+}
+
+// StockService keeps the on hand pack counts of every catalog and the
+// reservations holding them.
+type StockService struct {
+	mu           sync.Mutex
+	now          func() time.Time
+	onHand       map[string]map[int]int
+	held         map[string]map[int]int
+	reservations map[string]Reservation
+}
+
+// NewStockService is a constructor of the StockService.
+func NewStockService() *StockService {
+	return &StockService{
+		now:          time.Now,
+		onHand:       make(map[string]map[int]int),
+		held:         make(map[string]map[int]int),
+		reservations: make(map[string]Reservation),
+	}
+}
+
+// GetStock returns the stock levels of every pack size of the catalog.
+func (stock *StockService) GetStock(catalogID string) map[int]StockLevel {
+	stock.mu.Lock()
+	defer stock.mu.Unlock()
+
+	levels := make(map[int]StockLevel)
+	for size, onHand := range stock.onHand[catalogID] {
+		held := stock.held[catalogID][size]
+		levels[size] = StockLevel{OnHand: onHand, Held: held, Available: onHand - held}
+	}
+
+	return levels
+}
+
+// Available returns the pack counts of the catalog that are not held.
+func (stock *StockService) Available(catalogID string) map[int]int {
+	stock.mu.Lock()
+	defer stock.mu.Unlock()
+
+	available := make(map[int]int)
+	for size, onHand := range stock.onHand[catalogID] {
+		if free := onHand - stock.held[catalogID][size]; free > 0 {
+			available[size] = free
+		}
+	}
+
+	return available
+}
+
+// SetStock replaces the on hand pack counts of the catalog. Holds stay in place,
+// so the available stock of a size may drop below zero until they are released.
+func (stock *StockService) SetStock(ctx context.Context, catalogID string, onHand map[int]int) error {
+	for size, count := range onHand {
+		if count < 0 {
+			slog.ErrorContext(ctx,
+				ErrorNegativeStock,
+				"incoming_catalog", catalogID,
+				"incoming_size", size)
+			return errors.New(ErrorNegativeStock)
+		}
+	}
+
+	stock.mu.Lock()
+	defer stock.mu.Unlock()
+
+	stock.onHand[catalogID] = make(map[int]int)
+	for size, count := range onHand {
+		stock.onHand[catalogID][size] = count
+	}
+
+	return nil
+}
+
+// Reserve holds the packets of the catalog for the ttl when all of them are available.
+func (stock *StockService) Reserve(ctx context.Context, catalogID string, packets map[int]int, ttl time.Duration) (Reservation, error) {
+	if ttl <= 0 {
+		return Reservation{}, errors.New(ErrorNegativeOrZeroTTL)
+	}
+	packs := 0
+	for _, count := range packets {
+		if count < 0 {
+			return Reservation{}, errors.New(ErrorEmptyReservation)
+		}
+		packs += count
+	}
+	if packs == 0 {
+		return Reservation{}, errors.New(ErrorEmptyReservation)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Reservation{}, err
+	}
+
+	stock.mu.Lock()
+	defer stock.mu.Unlock()
+
+	for size, count := range packets {
+		if available := stock.onHand[catalogID][size] - stock.held[catalogID][size]; count > available {
+			slog.ErrorContext(ctx,
+				ErrorInsufficientStock,
+				"incoming_catalog", catalogID,
+				"incoming_size", size,
+				"incoming_packs", count,
+				"available", available)
+			return Reservation{}, errors.New(ErrorInsufficientStock)
+		}
+	}
+
+	if stock.held[catalogID] == nil {
+		stock.held[catalogID] = make(map[int]int)
+	}
+	for size, count := range packets {
+		stock.held[catalogID][size] += count
+	}
+
+	now := stock.now().UTC()
+	reservation := Reservation{
+		ID:        hex.EncodeToString(id),
+		CatalogID: catalogID,
+		Packets:   maps.Clone(packets),
+		Status:    ReservationHeld,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	stock.reservations[reservation.ID] = reservation
+
+	return stock.cloneReservation(reservation), nil
+}
+
+// GetReservation returns the reservation by ID.
+func (stock *StockService) GetReservation(id string) (Reservation, error) {
+	stock.mu.Lock()
+	defer stock.mu.Unlock()
+
+	reservation, exists := stock.reservations[id]
+	if !exists {
+		return Reservation{}, errors.New(ErrorReservationNotFound)
+	}
+
+	return stock.cloneReservation(reservation), nil
+}
+
+// ConfirmReservation takes the held packs out of the on hand stock.
+func (stock *StockService) ConfirmReservation(ctx context.Context, id string) (Reservation, error) {
+	return stock.settle(ctx, id, ReservationConfirmed)
+}
+
+// CancelReservation returns the held packs to the available stock.
+func (stock *StockService) CancelReservation(ctx context.Context, id string) (Reservation, error) {
+	return stock.settle(ctx, id, ReservationCancelled)
+}
+
+// settle moves a held reservation to the status, expiring it instead when its
+// TTL has already passed.
+func (stock *StockService) settle(ctx context.Context, id, status string) (Reservation, error) {
+	stock.mu.Lock()
+	defer stock.mu.Unlock()
+
+	reservation, exists := stock.reservations[id]
+	if !exists {
+		return Reservation{}, errors.New(ErrorReservationNotFound)
+	}
+	if reservation.Status == ReservationHeld && !stock.now().Before(reservation.ExpiresAt) {
+		reservation = stock.release(reservation, ReservationExpired)
+	}
+	if reservation.Status != ReservationHeld {
+		slog.ErrorContext(ctx,
+			ErrorReservationNotHeld,
+			"incoming_reservation", id,
+			"status", reservation.Status)
+		return stock.cloneReservation(reservation), errors.New(ErrorReservationNotHeld)
+	}
+
+	reservation = stock.release(reservation, status)
+	if status == ReservationConfirmed {
+		for size, count := range reservation.Packets {
+			stock.onHand[reservation.CatalogID][size] -= count
+		}
+	}
+
+	return stock.cloneReservation(reservation), nil
+}
+
+// ReleaseExpired expires every held reservation whose TTL has passed and
+// returns how many were released.
+func (stock *StockService) ReleaseExpired() int {
+	stock.mu.Lock()
+	defer stock.mu.Unlock()
+
+	now, released := stock.now(), 0
+	for _, reservation := range stock.reservations {
+		if reservation.Status == ReservationHeld && !now.Before(reservation.ExpiresAt) {
+			stock.release(reservation, ReservationExpired)
+			released++
+		}
+	}
+
+	return released
+}
+
+// PruneSettled deletes the reservations no longer held once their retention
+// has passed and returns how many were deleted.
+func (stock *StockService) PruneSettled() int {
+	stock.mu.Lock()
+	defer stock.mu.Unlock()
+
+	now, pruned := stock.now(), 0
+	for id, reservation := range stock.reservations {
+		if reservation.Status != ReservationHeld && !now.Before(reservation.ExpiresAt.Add(ReservationRetention)) {
+			delete(stock.reservations, id)
+			pruned++
+		}
+	}
+
+	return pruned
+}
+
+// release drops the holds of the reservation and stores it with the status.
+// The caller must hold the lock.
+func (stock *StockService) release(reservation Reservation, status string) Reservation {
+	for size, count := range reservation.Packets {
+		stock.held[reservation.CatalogID][size] -= count
+	}
+	reservation.Status = status
+	stock.reservations[reservation.ID] = reservation
+
+	return reservation
+}
+
+// cloneReservation returns a copy of the reservation safe to hand out.
+func (stock *StockService) cloneReservation(reservation Reservation) Reservation {
+	reservation.Packets = maps.Clone(reservation.Packets)
+	return reservation
+}
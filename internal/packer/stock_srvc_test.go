@@ -0,0 +1,97 @@
+package packer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStockService_Reservations(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	stock := NewStockService()
+	stock.now = func() time.Time { return now }
+	require.NoError(t, stock.SetStock(ctx, DefaultCatalogID, map[int]int{250: 2, 500: 1}))
+
+	held, err := stock.Reserve(ctx, DefaultCatalogID, map[int]int{250: 1, 500: 1}, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, ReservationHeld, held.Status)
+	require.Equal(t, now.Add(time.Minute), held.ExpiresAt)
+	require.Equal(t, map[int]int{250: 1}, stock.Available(DefaultCatalogID))
+
+	_, err = stock.Reserve(ctx, DefaultCatalogID, map[int]int{500: 1}, time.Minute)
+	require.EqualError(t, err, ErrorInsufficientStock)
+
+	cancelled, err := stock.CancelReservation(ctx, held.ID)
+	require.NoError(t, err)
+	require.Equal(t, ReservationCancelled, cancelled.Status)
+	require.Equal(t, map[int]int{250: 2, 500: 1}, stock.Available(DefaultCatalogID))
+
+	_, err = stock.ConfirmReservation(ctx, held.ID)
+	require.EqualError(t, err, ErrorReservationNotHeld)
+
+	held, err = stock.Reserve(ctx, DefaultCatalogID, map[int]int{250: 2}, time.Minute)
+	require.NoError(t, err)
+	confirmed, err := stock.ConfirmReservation(ctx, held.ID)
+	require.NoError(t, err)
+	require.Equal(t, ReservationConfirmed, confirmed.Status)
+	require.Equal(t, map[int]StockLevel{
+		250: {OnHand: 0, Held: 0, Available: 0},
+		500: {OnHand: 1, Held: 0, Available: 1},
+	}, stock.GetStock(DefaultCatalogID))
+
+	_, err = stock.GetReservation("unknown")
+	require.EqualError(t, err, ErrorReservationNotFound)
+}
+
+func TestStockService_ReserveErrors(t *testing.T) {
+	ctx := context.Background()
+	stock := NewStockService()
+
+	require.EqualError(t, stock.SetStock(ctx, DefaultCatalogID, map[int]int{250: -1}), ErrorNegativeStock)
+
+	_, err := stock.Reserve(ctx, DefaultCatalogID, map[int]int{}, time.Minute)
+	require.EqualError(t, err, ErrorEmptyReservation)
+
+	_, err = stock.Reserve(ctx, DefaultCatalogID, map[int]int{250: 1}, 0)
+	require.EqualError(t, err, ErrorNegativeOrZeroTTL)
+
+	_, err = stock.Reserve(ctx, DefaultCatalogID, map[int]int{250: 1}, time.Minute)
+	require.EqualError(t, err, ErrorInsufficientStock)
+}
+
+func TestStockService_Expiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	stock := NewStockService()
+	stock.now = func() time.Time { return now }
+	require.NoError(t, stock.SetStock(ctx, DefaultCatalogID, map[int]int{250: 2}))
+
+	first, err := stock.Reserve(ctx, DefaultCatalogID, map[int]int{250: 1}, time.Minute)
+	require.NoError(t, err)
+	second, err := stock.Reserve(ctx, DefaultCatalogID, map[int]int{250: 1}, time.Hour)
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	require.Equal(t, 1, stock.ReleaseExpired())
+	require.Equal(t, map[int]int{250: 1}, stock.Available(DefaultCatalogID))
+
+	expired, err := stock.GetReservation(first.ID)
+	require.NoError(t, err)
+	require.Equal(t, ReservationExpired, expired.Status)
+
+	now = now.Add(time.Hour)
+	_, err = stock.ConfirmReservation(ctx, second.ID)
+	require.EqualError(t, err, ErrorReservationNotHeld)
+	require.Equal(t, map[int]int{250: 2}, stock.Available(DefaultCatalogID))
+
+	require.Zero(t, stock.PruneSettled())
+	now = first.ExpiresAt.Add(ReservationRetention)
+	require.Equal(t, 1, stock.PruneSettled())
+	_, err = stock.GetReservation(first.ID)
+	require.EqualError(t, err, ErrorReservationNotFound)
+	_, err = stock.GetReservation(second.ID)
+	require.NoError(t, err)
+}
@@ -0,0 +1,172 @@
+package packer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slog"
+)
+
+// BaseUnit is the unit of the catalog sizes; a single item.
+const BaseUnit = "item"
+
+// MinUnitFactor and MaxUnitFactor bound the factors of the units, so that any
+// quantity of up to MaxItems items stays finite in every unit.
+const (
+	MinUnitFactor = 1e-6
+	MaxUnitFactor = MaxItems
+)
+
+// ERR consts ...
+const (
+	ErrorUnitNotFound          = "unit does not exist"
+	ErrorNonIntegralConversion = "quantity does not convert to a whole number of items"
+)
+
+// Quantity is an amount expressed in a unit of measure of a catalog.
+type Quantity struct {
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+}
+
+// UnitPackets is the packing of a quantity reported in both the base and the
+// requested unit.
+type UnitPackets struct {
+	Packets     map[int]int `json:"packets"`
+	Unit        string      `json:"unit"`
+	Factor      float64     `json:"factor"`
+	Quantity    float64     `json:"quantity"`
+	Items       int         `json:"items"`
+	Total       int         `json:"total"`
+	TotalInUnit float64     `json:"total_in_unit"`
+}
+
+// Units holds the units of measure of every catalog. A unit factor is the
+// number of items a single unit stands for; the base unit always has a factor of 1.
+type Units struct {
+	mu       sync.RWMutex
+	catalogs map[string]map[string]float64
+}
+
+// NewUnits is a constructor of the Units.
+func NewUnits() *Units {
+	return &Units{
+		catalogs: make(map[string]map[string]float64),
+	}
+}
+
+// GetUnits returns the units of the catalog including the base unit.
+func (units *Units) GetUnits(catalogID string) map[string]float64 {
+	units.mu.RLock()
+	defer units.mu.RUnlock()
+
+	factors := maps.Clone(units.catalogs[catalogID])
+	if factors == nil {
+		factors = make(map[string]float64)
+	}
+	factors[BaseUnit] = 1
+
+	return factors
+}
+
+// SetUnits replaces the units of the catalog. The base unit is always kept with
+// a factor of 1.
+func (units *Units) SetUnits(catalogID string, factors map[string]float64) {
+	factors = maps.Clone(factors)
+	delete(factors, BaseUnit)
+
+	units.mu.Lock()
+	defer units.mu.Unlock()
+
+	units.catalogs[catalogID] = factors
+}
+
+// Factor returns the factor of the unit in the catalog. An empty unit is the base unit.
+func (units *Units) Factor(catalogID, unit string) (float64, error) {
+	if unit == "" || unit == BaseUnit {
+		return 1, nil
+	}
+
+	units.mu.RLock()
+	defer units.mu.RUnlock()
+
+	factor, exists := units.catalogs[catalogID][unit]
+	if !exists {
+		return 0, errors.New(ErrorUnitNotFound)
+	}
+
+	return factor, nil
+}
+
+// Convert returns the number of items of the quantity in the catalog. It fails
+// when the quantity is not a whole number of items.
+func (units *Units) Convert(catalogID string, quantity Quantity) (int, error) {
+	factor, err := units.Factor(catalogID, quantity.Unit)
+	if err != nil {
+		return 0, err
+	}
+
+	return toItems(quantity.Quantity, factor)
+}
+
+// toItems multiplies the quantity by the factor. It fails when the result is
+// beyond MaxItems or not a whole number.
+func toItems(quantity, factor float64) (int, error) {
+	items := quantity * factor
+	if math.IsNaN(items) || math.Abs(items) > MaxItems {
+		return 0, errors.New(ErrorTooManyItems)
+	}
+	if !validator.Integral(items) {
+		return 0, errors.New(ErrorNonIntegralConversion)
+	}
+	return int(math.Round(items)), nil
+}
+
+// GetUnitPackets converts the quantity to items of the catalog chosen with
+// WithCatalog, calculates the packets like GetPackets and reports the result
+// in both the base and the requested unit.
+func (packets PacketsService) GetUnitPackets(ctx context.Context, quantity Quantity, opts ...PacketsOption) (UnitPackets, error) {
+	catalogID := newPacketsOptions(opts).catalogID
+	factor, err := packets.Units.Factor(catalogID, quantity.Unit)
+	if err != nil {
+		slog.ErrorContext(ctx,
+			err.Error(),
+			"incoming_catalog", catalogID,
+			"incoming_unit", quantity.Unit)
+		return UnitPackets{}, err
+	}
+
+	items, err := toItems(quantity.Quantity, factor)
+	if err != nil {
+		slog.ErrorContext(ctx,
+			err.Error(),
+			"incoming_catalog", catalogID,
+			"incoming_quantity", quantity.Quantity,
+			"incoming_unit", quantity.Unit)
+		return UnitPackets{}, err
+	}
+
+	result, err := packets.pack(ctx, items, opts)
+	if err != nil {
+		return UnitPackets{}, err
+	}
+
+	unit := quantity.Unit
+	if unit == "" {
+		unit = BaseUnit
+	}
+
+	return UnitPackets{
+		Packets:     result.best.Packs,
+		Unit:        unit,
+		Factor:      factor,
+		Quantity:    quantity.Quantity,
+		Items:       items,
+		Total:       result.best.Total,
+		TotalInUnit: float64(result.best.Total) / factor,
+	}, nil
+}
@@ -0,0 +1,128 @@
+package packer
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketsService_GetUnitPackets(t *testing.T) {
+	packer := newPacker()
+	packer.Units.SetUnits(DefaultCatalogID, map[string]float64{
+		"dozen": 12,
+		"case":  500,
+		"gram":  0.004,
+	})
+
+	testCases := []struct {
+		name      string
+		quantity  Quantity
+		wantPacks UnitPackets
+		wantErr   string
+	}{
+		{
+			name:     "OK base unit",
+			quantity: Quantity{Quantity: 501},
+			wantPacks: UnitPackets{
+				Packets:     map[int]int{250: 1, 500: 1},
+				Unit:        BaseUnit,
+				Factor:      1,
+				Quantity:    501,
+				Items:       501,
+				Total:       750,
+				TotalInUnit: 750,
+			},
+		},
+		{
+			name:     "OK cases",
+			quantity: Quantity{Quantity: 2.5, Unit: "case"},
+			wantPacks: UnitPackets{
+				Packets:     map[int]int{250: 1, 1000: 1},
+				Unit:        "case",
+				Factor:      500,
+				Quantity:    2.5,
+				Items:       1250,
+				Total:       1250,
+				TotalInUnit: 2.5,
+			},
+		},
+		{
+			name:     "OK fractional factor",
+			quantity: Quantity{Quantity: 1000, Unit: "gram"},
+			wantPacks: UnitPackets{
+				Packets:     map[int]int{250: 1},
+				Unit:        "gram",
+				Factor:      0.004,
+				Quantity:    1000,
+				Items:       4,
+				Total:       250,
+				TotalInUnit: 62500,
+			},
+		},
+		{
+			name:     "ERR non integral",
+			quantity: Quantity{Quantity: 1.5, Unit: "gram"},
+			wantErr:  ErrorNonIntegralConversion,
+		},
+		{
+			name:     "ERR large non integral",
+			quantity: Quantity{Quantity: 999_999_999.5},
+			wantErr:  ErrorNonIntegralConversion,
+		},
+		{
+			name:     "ERR too many items",
+			quantity: Quantity{Quantity: 1e30},
+			wantErr:  ErrorTooManyItems,
+		},
+		{
+			name:     "ERR too many items in unit",
+			quantity: Quantity{Quantity: 100_000_000, Unit: "dozen"},
+			wantErr:  ErrorTooManyItems,
+		},
+		{
+			name:     "ERR infinite items",
+			quantity: Quantity{Quantity: math.Inf(1)},
+			wantErr:  ErrorTooManyItems,
+		},
+		{
+			name:     "ERR unknown unit",
+			quantity: Quantity{Quantity: 1, Unit: "pallet"},
+			wantErr:  ErrorUnitNotFound,
+		},
+	}
+
+	for index := range testCases {
+		tc := testCases[index]
+		t.Run(tc.name, func(t *testing.T) {
+			packets, err := packer.GetUnitPackets(context.Background(), tc.quantity)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPacks, packets)
+		})
+	}
+}
+
+func TestUnits_SetUnits(t *testing.T) {
+	units := NewUnits()
+	units.SetUnits("shirts", map[string]float64{"dozen": 12, BaseUnit: 3})
+
+	require.Equal(t, map[string]float64{"dozen": 12, BaseUnit: 1}, units.GetUnits("shirts"))
+	require.Equal(t, map[string]float64{BaseUnit: 1}, units.GetUnits(DefaultCatalogID))
+
+	items, err := units.Convert("shirts", Quantity{Quantity: 3, Unit: "dozen"})
+	require.NoError(t, err)
+	require.Equal(t, 36, items)
+
+	items, err = units.Convert("shirts", Quantity{Quantity: 0.1 * 3, Unit: "dozen"})
+	require.EqualError(t, err, ErrorNonIntegralConversion)
+	require.Zero(t, items)
+
+	items, err = units.Convert(DefaultCatalogID, Quantity{Quantity: 0.1 * 3 * 1e9})
+	require.NoError(t, err)
+	require.Equal(t, 300_000_000, items)
+}
@@ -0,0 +1,177 @@
+package packer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/bits"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
+)
+
+// MaxWarehouses caps the warehouses of a fulfilment request, as subsets of
+// them are tried.
+const MaxWarehouses = 10
+
+// maxFulfilmentProbes caps the subsets of warehouses packed for a single
+// fulfilment request, as each runs the solver.
+const maxFulfilmentProbes = 64
+
+// ERR consts ...
+const (
+	ErrorNoWarehouses      = "warehouses must be more than 0 in quantity"
+	ErrorTooManyWarehouses = "warehouses must not be more than 10 in quantity"
+	ErrorUnfulfillable     = "warehouses stock cannot cover the order"
+)
+
+// Warehouse holds the stock of every pack size in a single location.
+type Warehouse struct {
+	ID    string      `json:"id"`
+	Stock map[int]int `json:"stock"`
+}
+
+// WarehouseShipment holds the packs a single warehouse ships.
+type WarehouseShipment struct {
+	Warehouse string      `json:"warehouse"`
+	Packets   map[int]int `json:"packets"`
+	Packs     int         `json:"packs"`
+	Total     int         `json:"total"`
+}
+
+// FulfilmentPlan shows which warehouses ship which packs of an order.
+type FulfilmentPlan struct {
+	CatalogID  string              `json:"catalog"`
+	Items      int                 `json:"items"`
+	Total      int                 `json:"total"`
+	Overshoot  int                 `json:"overshoot"`
+	TotalPacks int                 `json:"total_packs"`
+	Shipments  []WarehouseShipment `json:"shipments"`
+}
+
+// GetFulfilmentPlan picks the warehouses shipping the order so that the number
+// of shipments is the smallest, then the overshoot and then the number of packs.
+// Constraints given with WithConstraints apply on top of the warehouses stock.
+func (packets PacketsService) GetFulfilmentPlan(ctx context.Context, itemsToPack int, warehouses []Warehouse, opts ...PacketsOption) (FulfilmentPlan, error) {
+	if itemsToPack <= 0 {
+		slog.ErrorContext(ctx,
+			ErrorNegativeOrZeroItems,
+			"incoming_items", itemsToPack)
+		return FulfilmentPlan{}, errors.New(ErrorNegativeOrZeroItems)
+	}
+	switch {
+	case len(warehouses) == 0:
+		return FulfilmentPlan{}, errors.New(ErrorNoWarehouses)
+	case len(warehouses) > MaxWarehouses:
+		return FulfilmentPlan{}, errors.New(ErrorTooManyWarehouses)
+	}
+
+	stockOf := func(mask uint) map[int]int {
+		stock := make(map[int]int)
+		for i, warehouse := range warehouses {
+			if mask&(1<<i) == 0 {
+				continue
+			}
+			for size, count := range warehouse.Stock {
+				stock[size] += count
+			}
+		}
+		return stock
+	}
+	// A plan covers the order, whatever the rounding of the profile.
+	fulfil := func(stock map[int]int, extra ...PacketsOption) (packing, error) {
+		return packets.pack(ctx, itemsToPack, append(slices.Clone(opts), append(extra, withStock(stock), withRounding(RoundingUp))...))
+	}
+
+	all := uint(1)<<len(warehouses) - 1
+	fallback, err := fulfil(stockOf(all))
+	if err != nil {
+		var infeasibleErr *InfeasibleError
+		if errors.As(err, &infeasibleErr) {
+			return FulfilmentPlan{}, errors.New(ErrorUnfulfillable)
+		}
+		return FulfilmentPlan{}, err
+	}
+
+	// Subsets are tried by the number of shipments, skipping those without
+	// the stock to cover the order. Once the probes run out the plan ships
+	// from every warehouse holding packs of it.
+	var (
+		bestMask = all
+		best     = fallback
+		found    bool
+		probes   int
+	)
+	for shipments := 1; shipments < len(warehouses) && !found && probes < maxFulfilmentProbes; shipments++ {
+		for mask := uint(1); mask < all && probes < maxFulfilmentProbes; mask++ {
+			if bits.OnesCount(mask) != shipments {
+				continue
+			}
+			stock := stockOf(mask)
+			if capacity(stock) < itemsToPack {
+				continue
+			}
+			probes++
+			option, err := fulfil(stock, asProbe())
+			if err != nil {
+				continue
+			}
+			if !found || option.best.Total < best.best.Total || (option.best.Total == best.best.Total && option.best.Count < best.best.Count) {
+				bestMask, best, found = mask, option, true
+			}
+		}
+	}
+
+	return FulfilmentPlan{
+		CatalogID:  best.catalogID,
+		Items:      itemsToPack,
+		Total:      best.best.Total,
+		Overshoot:  best.best.Total - itemsToPack,
+		TotalPacks: best.best.Count,
+		Shipments:  allocateWarehouses(best.best.Packs, warehouses, bestMask),
+	}, nil
+}
+
+// capacity returns the items the stock holds, capped at math.MaxInt.
+func capacity(stock map[int]int) int {
+	total := 0
+	for size, count := range stock {
+		if count > 0 && size > 0 && count > (math.MaxInt-total)/size {
+			return math.MaxInt
+		}
+		total += size * count
+	}
+	return total
+}
+
+// allocateWarehouses takes the packs of every size from the chosen warehouses
+// in their order. Warehouses left with nothing to ship are skipped.
+func allocateWarehouses(necessaryPacks map[int]int, warehouses []Warehouse, mask uint) []WarehouseShipment {
+	sizes := maps.Keys(necessaryPacks)
+	slices.Sort(sizes)
+
+	var shipments []WarehouseShipment
+	left := maps.Clone(necessaryPacks)
+	for i, warehouse := range warehouses {
+		if mask&(1<<i) == 0 {
+			continue
+		}
+		shipment := WarehouseShipment{Warehouse: warehouse.ID, Packets: make(map[int]int)}
+		for _, size := range sizes {
+			taken := min(left[size], warehouse.Stock[size])
+			if taken <= 0 {
+				continue
+			}
+			left[size] -= taken
+			shipment.Packets[size] = taken
+			shipment.Packs += taken
+			shipment.Total += taken * size
+		}
+		if shipment.Packs > 0 {
+			shipments = append(shipments, shipment)
+		}
+	}
+
+	return shipments
+}
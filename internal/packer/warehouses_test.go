@@ -0,0 +1,156 @@
+package packer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketsService_GetFulfilmentPlan(t *testing.T) {
+	packer := newPacker()
+
+	testCases := []struct {
+		name       string
+		items      int
+		warehouses []Warehouse
+		wantPlan   FulfilmentPlan
+		wantErr    string
+	}{
+		{
+			name:  "OK fewer shipments win over overshoot",
+			items: 750,
+			warehouses: []Warehouse{
+				{ID: "a", Stock: map[int]int{250: 1}},
+				{ID: "b", Stock: map[int]int{500: 1}},
+				{ID: "c", Stock: map[int]int{1000: 1}},
+			},
+			wantPlan: FulfilmentPlan{
+				CatalogID:  DefaultCatalogID,
+				Items:      750,
+				Total:      1000,
+				Overshoot:  250,
+				TotalPacks: 1,
+				Shipments: []WarehouseShipment{
+					{Warehouse: "c", Packets: map[int]int{1000: 1}, Packs: 1, Total: 1000},
+				},
+			},
+		},
+		{
+			name:  "OK fewer packs win among equal overshoot",
+			items: 1500,
+			warehouses: []Warehouse{
+				{ID: "a", Stock: map[int]int{250: 2}},
+				{ID: "b", Stock: map[int]int{1000: 1}},
+				{ID: "c", Stock: map[int]int{500: 1}},
+			},
+			wantPlan: FulfilmentPlan{
+				CatalogID:  DefaultCatalogID,
+				Items:      1500,
+				Total:      1500,
+				TotalPacks: 2,
+				Shipments: []WarehouseShipment{
+					{Warehouse: "b", Packets: map[int]int{1000: 1}, Packs: 1, Total: 1000},
+					{Warehouse: "c", Packets: map[int]int{500: 1}, Packs: 1, Total: 500},
+				},
+			},
+		},
+		{
+			name:  "OK packs of a size split over warehouses",
+			items: 1000,
+			warehouses: []Warehouse{
+				{ID: "a", Stock: map[int]int{250: 3}},
+				{ID: "b", Stock: map[int]int{250: 3}},
+			},
+			wantPlan: FulfilmentPlan{
+				CatalogID:  DefaultCatalogID,
+				Items:      1000,
+				Total:      1000,
+				TotalPacks: 4,
+				Shipments: []WarehouseShipment{
+					{Warehouse: "a", Packets: map[int]int{250: 3}, Packs: 3, Total: 750},
+					{Warehouse: "b", Packets: map[int]int{250: 1}, Packs: 1, Total: 250},
+				},
+			},
+		},
+		{
+			name:  "OK large order within stock",
+			items: 2_000_000,
+			warehouses: []Warehouse{
+				{ID: "a", Stock: map[int]int{250: 10}},
+				{ID: "b", Stock: map[int]int{5000: 1000}},
+			},
+			wantPlan: FulfilmentPlan{
+				CatalogID:  DefaultCatalogID,
+				Items:      2_000_000,
+				Total:      2_000_000,
+				TotalPacks: 400,
+				Shipments: []WarehouseShipment{
+					{Warehouse: "b", Packets: map[int]int{5000: 400}, Packs: 400, Total: 2_000_000},
+				},
+			},
+		},
+		{
+			name:  "ERR large order of small packs",
+			items: 2_000_000,
+			warehouses: []Warehouse{
+				{ID: "a", Stock: map[int]int{250: 10_000}},
+			},
+			wantErr: ErrorSolverSpanExceeded,
+		},
+		{
+			name:  "ERR unfulfillable",
+			items: 10000,
+			warehouses: []Warehouse{
+				{ID: "a", Stock: map[int]int{5000: 1}},
+			},
+			wantErr: ErrorUnfulfillable,
+		},
+		{
+			name:    "ERR no warehouses",
+			items:   1,
+			wantErr: ErrorNoWarehouses,
+		},
+		{
+			name:       "ERR too many warehouses",
+			items:      1,
+			warehouses: make([]Warehouse, MaxWarehouses+1),
+			wantErr:    ErrorTooManyWarehouses,
+		},
+	}
+
+	for index := range testCases {
+		tc := testCases[index]
+		t.Run(tc.name, func(t *testing.T) {
+			plan, err := packer.GetFulfilmentPlan(context.Background(), tc.items, tc.warehouses)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPlan, plan)
+		})
+	}
+}
+
+func TestPacketsService_GetFulfilmentPlanWithProfile(t *testing.T) {
+	ctx := context.Background()
+	packer := newPacker()
+	_, err := packer.Profiles.CreateProfile(ctx, Profile{ID: "acme", AllowedSizes: []int{250}, Rounding: RoundingDown})
+	require.NoError(t, err)
+
+	plan, err := packer.GetFulfilmentPlan(ctx, 600, []Warehouse{
+		{ID: "a", Stock: map[int]int{250: 5, 1000: 1}},
+	}, WithProfile("acme"))
+	require.NoError(t, err)
+	require.Equal(t, FulfilmentPlan{
+		CatalogID:  DefaultCatalogID,
+		Items:      600,
+		Total:      750,
+		Overshoot:  150,
+		TotalPacks: 3,
+		Shipments: []WarehouseShipment{
+			{Warehouse: "a", Packets: map[int]int{250: 3}, Packs: 3, Total: 750},
+		},
+	}, plan)
+}
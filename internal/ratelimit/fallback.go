@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// FallbackStore takes requests from a primary store and, while the primary
+// fails, from a fallback one. After a failure the primary is left alone for
+// the cooldown so that requests do not wait on an unreachable server, and
+// then a single request probes it while the others keep to the fallback.
+type FallbackStore struct {
+	primary  Store
+	fallback *MemoryStore
+	cooldown time.Duration
+
+	mu        sync.Mutex
+	failing   bool
+	nextProbe time.Time
+}
+
+// NewFallbackStore is a constructor of the FallbackStore.
+func NewFallbackStore(primary Store, fallback *MemoryStore, cooldown time.Duration) *FallbackStore {
+	return &FallbackStore{
+		primary:  primary,
+		fallback: fallback,
+		cooldown: cooldown,
+	}
+}
+
+// Take implements the Store interface. It never fails while the fallback
+// store does not.
+func (store *FallbackStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Decision, error) {
+	store.mu.Lock()
+	probe := !store.failing || !now.Before(store.nextProbe)
+	if store.failing && probe {
+		// Hold the others off until this probe has been recorded.
+		store.nextProbe = now.Add(store.cooldown)
+	}
+	store.mu.Unlock()
+
+	if probe {
+		decision, err := store.primary.Take(ctx, key, limit, now)
+		if err == nil || ctx.Err() == nil {
+			store.record(ctx, err, now)
+		} else {
+			// The client went away; that says nothing about the primary.
+			store.release(now)
+		}
+		if err == nil {
+			return decision, nil
+		}
+	}
+
+	return store.fallback.Take(ctx, key, limit, now)
+}
+
+// Failing reports whether the primary store failed last time it was tried.
+func (store *FallbackStore) Failing() bool {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.failing
+}
+
+// EvictIdle drops the idle buckets of the fallback store.
+func (store *FallbackStore) EvictIdle(now time.Time, idle time.Duration) int {
+	return store.fallback.EvictIdle(now, idle)
+}
+
+// Close implements the Store interface.
+func (store *FallbackStore) Close() error {
+	return store.primary.Close()
+}
+
+// release lets the next request probe the primary at once when the probe of
+// this one was cut short.
+func (store *FallbackStore) release(now time.Time) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.failing && store.nextProbe.Equal(now.Add(store.cooldown)) {
+		store.nextProbe = now
+	}
+}
+
+// record notes the outcome of trying the primary store, logging when it goes
+// down and comes back.
+func (store *FallbackStore) record(ctx context.Context, err error, now time.Time) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	switch {
+	case err != nil && !store.failing:
+		slog.WarnContext(ctx, "rate limit backend failed, limiting per replica",
+			slog.String("error", err.Error()),
+			slog.Duration("retry_in", store.cooldown),
+		)
+	case err == nil && store.failing:
+		slog.InfoContext(ctx, "rate limit backend recovered")
+	}
+
+	store.failing = err != nil
+	if store.failing {
+		store.nextProbe = now.Add(store.cooldown)
+	}
+}
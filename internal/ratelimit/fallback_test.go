@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// flakyStore fails while down and counts the requests it is asked to take.
+type flakyStore struct {
+	down  bool
+	calls int
+}
+
+func (store *flakyStore) Take(_ context.Context, _ string, limit Limit, _ time.Time) (Decision, error) {
+	store.calls++
+	if store.down {
+		return Decision{}, errors.New("connection refused")
+	}
+	return Decision{Allowed: true, Limit: limit.Burst, Remaining: limit.Burst}, nil
+}
+
+func (store *flakyStore) Close() error {
+	return nil
+}
+
+func TestFallbackStore_Take(t *testing.T) {
+	ctx := context.Background()
+	primary := &flakyStore{down: true}
+	store := NewFallbackStore(primary, NewMemoryStore(), 5*time.Second)
+	limit := Limit{RPS: 1, Burst: 1}
+	now := time.Unix(1_700_000_000, 0)
+
+	// The fallback limits the client while the primary is down.
+	decision, err := store.Take(ctx, "ip:192.0.2.1", limit, now)
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+	require.True(t, store.Failing())
+	decision, err = store.Take(ctx, "ip:192.0.2.1", limit, now.Add(time.Second/2))
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+	require.Equal(t, 1, primary.calls, "the primary is left alone for the cooldown")
+
+	// It is tried again after the cooldown and used once it is back.
+	primary.down = false
+	decision, err = store.Take(ctx, "ip:192.0.2.1", limit, now.Add(5*time.Second))
+	require.NoError(t, err)
+	require.Equal(t, Decision{Allowed: true, Limit: 1, Remaining: 1}, decision)
+	require.False(t, store.Failing())
+	require.Equal(t, 2, primary.calls)
+}
+
+// blockingStore fails until released and counts the requests it is asked to
+// take.
+type blockingStore struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (store *blockingStore) Take(context.Context, string, Limit, time.Time) (Decision, error) {
+	store.calls.Add(1)
+	<-store.release
+	return Decision{}, errors.New("connection refused")
+}
+
+func (store *blockingStore) Close() error {
+	return nil
+}
+
+func TestFallbackStore_Take_SingleProbe(t *testing.T) {
+	ctx := context.Background()
+	primary := &blockingStore{release: make(chan struct{})}
+	store := NewFallbackStore(primary, NewMemoryStore(), 5*time.Second)
+	limit := Limit{RPS: 100, Burst: 100}
+	now := time.Unix(1_700_000_000, 0)
+
+	store.failing = true
+	store.nextProbe = now
+
+	probed := make(chan struct{})
+	go func() {
+		defer close(probed)
+		_, _ = store.Take(ctx, "ip:192.0.2.1", limit, now)
+	}()
+	require.Eventually(t, func() bool { return primary.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	// The others keep to the fallback while the probe is under way.
+	for i := 0; i < 10; i++ {
+		decision, err := store.Take(ctx, "ip:192.0.2.1", limit, now)
+		require.NoError(t, err)
+		require.True(t, decision.Allowed)
+	}
+	require.Equal(t, int32(1), primary.calls.Load())
+
+	close(primary.release)
+	<-probed
+	require.True(t, store.Failing())
+}
+
+// cancelledStore fails with the error of the context, as a store does when
+// the client goes away while it waits on the server.
+type cancelledStore struct{}
+
+func (cancelledStore) Take(ctx context.Context, _ string, _ Limit, _ time.Time) (Decision, error) {
+	return Decision{}, ctx.Err()
+}
+
+func (cancelledStore) Close() error {
+	return nil
+}
+
+func TestFallbackStore_Take_CancelledContext(t *testing.T) {
+	store := NewFallbackStore(cancelledStore{}, NewMemoryStore(), 5*time.Second)
+	now := time.Unix(1_700_000_000, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	decision, err := store.Take(ctx, "ip:192.0.2.1", Limit{RPS: 1, Burst: 1}, now)
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+	require.False(t, store.Failing(), "a client going away is not a failure of the primary")
+
+	// A probe cut short leaves the next request free to probe.
+	store.failing = true
+	store.nextProbe = now
+	_, err = store.Take(ctx, "ip:192.0.2.1", Limit{RPS: 1, Burst: 1}, now)
+	require.NoError(t, err)
+	require.Equal(t, now, store.nextProbe)
+}
+
+func TestFallbackStore_Take_UnreachableServer(t *testing.T) {
+	srv := newRESPStandIn(t, "")
+	srv.ln.Close()
+
+	store := NewFallbackStore(NewRESPStore(srv.ln.Addr().String(), "", 50*time.Millisecond), NewMemoryStore(), time.Minute)
+	decision, err := store.Take(context.Background(), "ip:192.0.2.1", Limit{RPS: 1, Burst: 2}, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, Decision{Allowed: true, Limit: 2, Remaining: 1}, decision)
+	require.True(t, store.Failing())
+	require.Equal(t, 1, store.EvictIdle(time.Now().Add(time.Hour), time.Minute))
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// bucket is the token bucket of a client and the time it was last used.
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// MemoryStore keeps a token bucket per client in memory. Each replica of the
+// API limits its clients on its own.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// NewMemoryStore is a constructor of the MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+// Take implements the Store interface.
+func (store *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Decision, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	b, found := store.buckets[key]
+	if !found {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.RPS), limit.Burst)}
+		store.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	decision := Decision{
+		Allowed: b.limiter.AllowN(now, 1),
+		Limit:   limit.Burst,
+	}
+	tokens := b.limiter.TokensAt(now)
+	decision.Remaining = int(math.Max(0, math.Floor(tokens)))
+	if !decision.Allowed {
+		decision.RetryAfter = time.Duration((1 - tokens) / limit.RPS * float64(time.Second))
+	}
+
+	return decision, nil
+}
+
+// EvictIdle drops the buckets unused for longer than idle and returns how
+// many it dropped.
+func (store *MemoryStore) EvictIdle(now time.Time, idle time.Duration) int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	evicted := 0
+	for key, b := range store.buckets {
+		if now.Sub(b.lastSeen) > idle {
+			delete(store.buckets, key)
+			evicted++
+		}
+	}
+
+	return evicted
+}
+
+// Len returns the number of buckets held.
+func (store *MemoryStore) Len() int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return len(store.buckets)
+}
+
+// Close implements the Store interface.
+func (store *MemoryStore) Close() error {
+	return nil
+}
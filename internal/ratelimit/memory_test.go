@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	limit := Limit{RPS: 2, Burst: 2}
+	now := time.Unix(1_700_000_000, 0)
+
+	for _, remaining := range []int{1, 0} {
+		decision, err := store.Take(ctx, "ip:192.0.2.1", limit, now)
+		require.NoError(t, err)
+		require.Equal(t, Decision{Allowed: true, Limit: 2, Remaining: remaining}, decision)
+	}
+
+	decision, err := store.Take(ctx, "ip:192.0.2.1", limit, now.Add(100*time.Millisecond))
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+	require.InDelta(t, 400*time.Millisecond, decision.RetryAfter, float64(time.Millisecond))
+
+	decision, err = store.Take(ctx, "ip:192.0.2.1", limit, now.Add(time.Second))
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+
+	_, err = store.Take(ctx, "ip:192.0.2.2", limit, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 1, store.EvictIdle(now.Add(time.Minute+time.Second), 30*time.Second))
+	require.Equal(t, 1, store.Len())
+}
@@ -0,0 +1,44 @@
+// Package ratelimit holds the stores of the API rate limits: one in memory,
+// limiting every replica on its own, and one shared by the replicas through a
+// Redis protocol compatible server.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit lets RPS requests a second through on average and up to Burst at once.
+type Limit struct {
+	RPS   float64
+	Burst int
+}
+
+// window is the time a shared store lets Burst requests through in, so that
+// they average RPS requests a second.
+func (l Limit) window() time.Duration {
+	window := time.Duration(float64(l.Burst) / l.RPS * float64(time.Second))
+	if window < time.Millisecond {
+		return time.Millisecond
+	}
+	return window
+}
+
+// Decision is the outcome of taking a request from a limit.
+type Decision struct {
+	Allowed bool
+	// Limit is the number of requests the client may make at once.
+	Limit int
+	// Remaining is the number of requests the client may still make at once.
+	Remaining int
+	// RetryAfter is how long a client turned away should wait.
+	RetryAfter time.Duration
+}
+
+// Store takes the requests of the clients from their limits.
+type Store interface {
+	// Take takes a request of the client identified by key from the limit.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Decision, error)
+	// Close releases the resources of the store.
+	Close() error
+}
@@ -0,0 +1,279 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxIdleConns caps the connections a RESPStore keeps open between requests.
+const maxIdleConns = 8
+
+// ERR consts ...
+const (
+	ErrorStoreClosed = "resp: store is closed"
+)
+
+// keyPrefix namespaces the keys of the rate limits on the shared server.
+const keyPrefix = "packer:ratelimit:"
+
+// RESPStore shares the limits of the clients between the replicas of the API
+// through a Redis protocol compatible server. Each limit is a fixed window of
+// Burst/RPS seconds letting Burst requests through, counted with INCR on a
+// key of the window that expires once the window is over.
+type RESPStore struct {
+	addr     string
+	password string
+	timeout  time.Duration
+	idle     chan *respConn
+
+	// mu guards closed against connections handed back while closing.
+	mu     sync.Mutex
+	closed bool
+}
+
+// respConn is a connection to the server and its buffered reader.
+type respConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// NewRESPStore is a constructor of the RESPStore talking to the server at
+// addr. Every command, dialling included, fails after the timeout.
+func NewRESPStore(addr, password string, timeout time.Duration) *RESPStore {
+	return &RESPStore{
+		addr:     addr,
+		password: password,
+		timeout:  timeout,
+		idle:     make(chan *respConn, maxIdleConns),
+	}
+}
+
+// Take implements the Store interface.
+func (store *RESPStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Decision, error) {
+	window := limit.window()
+	index := now.UnixNano() / int64(window)
+	windowKey := keyPrefix + key + ":" + strconv.FormatInt(index, 10)
+	// The key outlives its window so that the clocks of the replicas may
+	// drift a little.
+	ttl := strconv.FormatInt((2*window).Milliseconds()+1, 10)
+
+	replies, err := store.do(ctx, []string{"INCR", windowKey}, []string{"PEXPIRE", windowKey, ttl})
+	if err != nil {
+		return Decision{}, err
+	}
+	count, ok := replies[0].(int64)
+	if !ok {
+		return Decision{}, fmt.Errorf("resp: unexpected INCR reply %v", replies[0])
+	}
+
+	decision := Decision{
+		Allowed:   count <= int64(limit.Burst),
+		Limit:     limit.Burst,
+		Remaining: max(limit.Burst-int(count), 0),
+	}
+	if !decision.Allowed {
+		decision.RetryAfter = time.Unix(0, (index+1)*int64(window)).Sub(now)
+	}
+
+	return decision, nil
+}
+
+// Close implements the Store interface. Connections still in use by Take
+// calls are closed when they are handed back.
+func (store *RESPStore) Close() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.closed = true
+	for {
+		select {
+		case conn := <-store.idle:
+			conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do sends the commands in a pipeline and returns their replies. Error
+// replies are returned as errors.
+func (store *RESPStore) do(ctx context.Context, commands ...[]string) ([]any, error) {
+	conn, err := store.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := conn.pipeline(store.deadline(ctx), commands...)
+	var replyErr respError
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection is in an unknown state after a network error.
+		conn.Close()
+		return nil, err
+	}
+
+	store.release(conn)
+
+	return replies, err
+}
+
+// release keeps the connection for later requests, or closes it when enough
+// are kept or the store is closed.
+func (store *RESPStore) release(conn *respConn) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if store.closed {
+		conn.Close()
+		return
+	}
+	select {
+	case store.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+// conn returns an idle connection or dials a new one.
+func (store *RESPStore) conn(ctx context.Context) (*respConn, error) {
+	store.mu.Lock()
+	closed := store.closed
+	store.mu.Unlock()
+	if closed {
+		return nil, errors.New(ErrorStoreClosed)
+	}
+
+	select {
+	case conn := <-store.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Deadline: store.deadline(ctx)}
+	c, err := dialer.DialContext(ctx, "tcp", store.addr)
+	if err != nil {
+		return nil, err
+	}
+	conn := &respConn{Conn: c, r: bufio.NewReader(c)}
+
+	if store.password != "" {
+		if _, err := conn.pipeline(store.deadline(ctx), []string{"AUTH", store.password}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// deadline returns the earlier of the context deadline and the timeout.
+func (store *RESPStore) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(store.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
+// respError is an error reply of the server.
+type respError string
+
+func (e respError) Error() string {
+	return "resp: " + string(e)
+}
+
+// pipeline writes the commands and reads a reply for each. The first error
+// reply is returned once every reply is read.
+func (conn *respConn) pipeline(deadline time.Time, commands ...[]string) ([]any, error) {
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	for _, command := range commands {
+		fmt.Fprintf(&b, "*%d\r\n", len(command))
+		for _, arg := range command {
+			fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	if _, err := io.WriteString(conn, b.String()); err != nil {
+		return nil, err
+	}
+
+	replies := make([]any, len(commands))
+	var firstErr error
+	for i := range commands {
+		reply, err := readReply(conn.r)
+		var replyErr respError
+		switch {
+		case errors.As(err, &replyErr):
+			if firstErr == nil {
+				firstErr = err
+			}
+		case err != nil:
+			return nil, err
+		}
+		replies[i] = reply
+	}
+
+	return replies, firstErr
+}
+
+// readReply reads a RESP reply: a simple string, error, integer, bulk string
+// or an array of those.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("resp: malformed reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, respError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]any, n)
+		for i := range items {
+			items[i], err = readReply(r)
+			var replyErr respError
+			switch {
+			case errors.As(err, &replyErr):
+				// Keep reading so that the connection stays in step.
+				items[i] = replyErr
+			case err != nil:
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+
+	return nil, fmt.Errorf("resp: unknown reply type %q", kind)
+}
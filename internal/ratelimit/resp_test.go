@@ -0,0 +1,182 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// respStandIn is a Redis protocol server holding counters in memory, standing
+// in for a shared server in tests.
+type respStandIn struct {
+	ln       net.Listener
+	password string
+
+	mu       sync.Mutex
+	counters map[string]int64
+	ttls     map[string]int64
+}
+
+func newRESPStandIn(t *testing.T, password string) *respStandIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &respStandIn{ln: ln, password: password, counters: make(map[string]int64), ttls: make(map[string]int64)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+
+	return srv
+}
+
+func (srv *respStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authenticated := srv.password == ""
+	for {
+		command, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		var reply string
+		switch name := strings.ToUpper(command[0]); {
+		case name == "AUTH":
+			authenticated = len(command) == 2 && command[1] == srv.password
+			reply = "+OK\r\n"
+			if !authenticated {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case name == "INCR":
+			srv.mu.Lock()
+			srv.counters[command[1]]++
+			reply = fmt.Sprintf(":%d\r\n", srv.counters[command[1]])
+			srv.mu.Unlock()
+		case name == "PEXPIRE":
+			ttl, _ := strconv.ParseInt(command[2], 10, 64)
+			srv.mu.Lock()
+			srv.ttls[command[1]] = ttl
+			srv.mu.Unlock()
+			reply = ":1\r\n"
+		default:
+			reply = fmt.Sprintf("-ERR unknown command '%s'\r\n", command[0])
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	reply, err := readReply(r)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]any)
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("not a command: %v", reply)
+	}
+	command := make([]string, len(items))
+	for i, item := range items {
+		command[i], _ = item.(string)
+	}
+	return command, nil
+}
+
+func TestRESPStore_Take(t *testing.T) {
+	srv := newRESPStandIn(t, "s3cret")
+	ctx := context.Background()
+	limit := Limit{RPS: 1, Burst: 3}
+	// The start of a window of 3 seconds.
+	now := time.Unix(1_700_000_001, 0)
+
+	// Two replicas share the limit of the client.
+	replicas := []*RESPStore{
+		NewRESPStore(srv.ln.Addr().String(), "s3cret", time.Second),
+		NewRESPStore(srv.ln.Addr().String(), "s3cret", time.Second),
+	}
+	for i := 0; i < 3; i++ {
+		decision, err := replicas[i%2].Take(ctx, "ip:192.0.2.1", limit, now)
+		require.NoError(t, err)
+		require.Equal(t, Decision{Allowed: true, Limit: 3, Remaining: 2 - i}, decision)
+	}
+
+	decision, err := replicas[1].Take(ctx, "ip:192.0.2.1", limit, now.Add(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, Decision{Allowed: false, Limit: 3, Remaining: 0, RetryAfter: 2 * time.Second}, decision)
+
+	// Other clients and the next window have counts of their own.
+	decision, err = replicas[0].Take(ctx, "ip:192.0.2.2", limit, now)
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+	decision, err = replicas[0].Take(ctx, "ip:192.0.2.1", limit, now.Add(3*time.Second))
+	require.NoError(t, err)
+	require.Equal(t, Decision{Allowed: true, Limit: 3, Remaining: 2}, decision)
+
+	srv.mu.Lock()
+	require.Equal(t, int64(6001), srv.ttls["packer:ratelimit:ip:192.0.2.1:566666667"])
+	srv.mu.Unlock()
+
+	for _, replica := range replicas {
+		require.NoError(t, replica.Close())
+	}
+}
+
+func TestRESPStore_Take_Errors(t *testing.T) {
+	srv := newRESPStandIn(t, "s3cret")
+	ctx := context.Background()
+
+	_, err := NewRESPStore(srv.ln.Addr().String(), "guess", time.Second).Take(ctx, "ip:192.0.2.1", Limit{RPS: 1, Burst: 1}, time.Now())
+	require.EqualError(t, err, "resp: WRONGPASS invalid password")
+
+	_, err = NewRESPStore(srv.ln.Addr().String(), "", time.Second).Take(ctx, "ip:192.0.2.1", Limit{RPS: 1, Burst: 1}, time.Now())
+	require.EqualError(t, err, "resp: NOAUTH Authentication required.")
+
+	unreachable := newRESPStandIn(t, "")
+	unreachable.ln.Close()
+	_, err = NewRESPStore(unreachable.ln.Addr().String(), "", 100*time.Millisecond).Take(ctx, "ip:192.0.2.1", Limit{RPS: 1, Burst: 1}, time.Now())
+	require.Error(t, err)
+}
+
+func TestRESPStore_Close(t *testing.T) {
+	srv := newRESPStandIn(t, "")
+	ctx := context.Background()
+	store := NewRESPStore(srv.ln.Addr().String(), "", time.Second)
+
+	_, err := store.Take(ctx, "ip:192.0.2.1", Limit{RPS: 1, Burst: 1}, time.Now())
+	require.NoError(t, err)
+	require.Len(t, store.idle, 1)
+
+	// A connection in use while the store closes is closed once handed back.
+	inUse, err := store.conn(ctx)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+	require.Empty(t, store.idle)
+	store.release(inUse)
+	require.Empty(t, store.idle)
+	_, err = inUse.Write([]byte("PING\r\n"))
+	require.ErrorIs(t, err, net.ErrClosed)
+
+	_, err = store.Take(ctx, "ip:192.0.2.1", Limit{RPS: 1, Burst: 1}, time.Now())
+	require.EqualError(t, err, ErrorStoreClosed)
+}
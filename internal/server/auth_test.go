@@ -0,0 +1,360 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestServer_RequireScope(t *testing.T) {
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.Keys = []auth.APIKey{
+		{ID: "storefront", Hash: auth.HashKey("storefront-secret"), Scopes: []string{auth.ScopeSizesRead, auth.ScopePacketsCalculate}},
+		{ID: "ops", Hash: auth.HashKey("ops-secret"), Scopes: []string{auth.ScopeAdmin}},
+	}
+	server := NewServer(cfg, nil, nil)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	testCases := []struct {
+		name     string
+		scope    string
+		key      string
+		wantCode int
+	}{
+		{
+			name:     "204 on granted scope",
+			scope:    auth.ScopeSizesRead,
+			key:      "storefront-secret",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "204 on admin",
+			scope:    auth.ScopeSizesWrite,
+			key:      "ops-secret",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "403 on missing scope",
+			scope:    auth.ScopeSizesWrite,
+			key:      "storefront-secret",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "401 on unknown key",
+			scope:    auth.ScopeSizesRead,
+			key:      "guess",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "401 on no key",
+			scope:    auth.ScopeSizesRead,
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/sizes", nil)
+			if tc.key != "" {
+				req.Header.Set(apiKeyHeader, tc.key)
+			}
+
+			rr := httptest.NewRecorder()
+			server.authenticate(server.requireScope(tc.scope, ok)).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+			if tc.wantCode == http.StatusUnauthorized {
+				require.NotEmpty(t, rr.Header().Get("WWW-Authenticate"))
+				require.Contains(t, rr.Body.String(), `"error"`)
+			}
+		})
+	}
+
+	t.Run("204 when authentication is disabled", func(t *testing.T) {
+		server := NewServer(config.Default(), nil, nil)
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/sizes", nil)
+
+		rr := httptest.NewRecorder()
+		server.authenticate(server.requireScope(auth.ScopeAdmin, ok)).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusNoContent, rr.Code)
+	})
+}
+
+// signRS256 returns a compact token of the claims signed with the key.
+func signRS256(t *testing.T, key *rsa.PrivateKey, claims map[string]any) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": "test", "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestServer_Authenticate_Bearer(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "test",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0o600))
+
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.JWT.JWKS = jwksFile
+	cfg.Auth.JWT.Issuer = "https://id.example"
+	cfg.Auth.JWT.Audience = "packer"
+	cfg.Auth.JWT.Scopes = map[string][]string{"catalog.manage": {auth.ScopeSizesWrite}}
+	tokens, err := newVerifier(context.Background(), cfg)
+	require.NoError(t, err)
+	server := NewServer(config.Default(), nil, nil)
+	server.setConfig(cfg, tokens)
+
+	var actor auth.Principal
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor, _ = auth.PrincipalFrom(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+	claims := func(scope string, exp time.Time) map[string]any {
+		return map[string]any{
+			"iss":   "https://id.example",
+			"aud":   "packer",
+			"sub":   "jane",
+			"exp":   exp.Unix(),
+			"scope": scope,
+		}
+	}
+	later := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		name     string
+		scheme   string
+		token    string
+		wantCode int
+	}{
+		{
+			name:     "204 on mapped scope",
+			token:    signRS256(t, key, claims("catalog.manage", later)),
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "204 on lower case scheme",
+			scheme:   "bearer",
+			token:    signRS256(t, key, claims("catalog.manage", later)),
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "401 on upper case scheme and malformed token",
+			scheme:   "BEARER",
+			token:    "opaque",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "403 on missing scope",
+			token:    signRS256(t, key, claims(auth.ScopeSizesRead, later)),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "401 on expired token",
+			token:    signRS256(t, key, claims("catalog.manage", time.Now().Add(-time.Hour))),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "401 on malformed token",
+			token:    "opaque",
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actor = auth.Principal{}
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/sizes", nil)
+			scheme := tc.scheme
+			if scheme == "" {
+				scheme = "Bearer"
+			}
+			req.Header.Set("Authorization", scheme+" "+tc.token)
+
+			rr := httptest.NewRecorder()
+			server.authenticate(server.requireScope(auth.ScopeSizesWrite, ok)).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+			switch tc.wantCode {
+			case http.StatusNoContent:
+				require.Equal(t, auth.Principal{ID: "jane", Method: auth.MethodJWT, Scopes: []string{auth.ScopeSizesWrite}}, actor)
+			case http.StatusUnauthorized:
+				require.True(t, strings.HasPrefix(rr.Header().Get("WWW-Authenticate"), `Bearer error="invalid_token"`))
+			}
+		})
+	}
+
+	t.Run("401 when no JWKS is configured", func(t *testing.T) {
+		server := NewServer(cfg, nil, nil)
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/sizes", nil)
+		req.Header.Set("Authorization", "Bearer "+signRS256(t, key, claims("catalog.manage", later)))
+
+		rr := httptest.NewRecorder()
+		server.authenticate(server.requireScope(auth.ScopeSizesWrite, ok)).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestServer_VerifySignature(t *testing.T) {
+	const secret = "0123456789abcdef0123456789abcdef"
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.HMAC.Clients = []auth.HMACClient{{ID: "erp", Secret: secret, Scopes: []string{auth.ScopePacketsCalculate}}}
+	server := NewServer(cfg, nil, nil)
+
+	var actor auth.Principal
+	var read string
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor, _ = auth.PrincipalFrom(r.Context())
+		body, _ := io.ReadAll(r.Body)
+		read = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	body := `{"items": 250}`
+
+	testCases := []struct {
+		name      string
+		timestamp string
+		signature string
+		wantCode  int
+	}{
+		{
+			name:      "204 on valid signature",
+			timestamp: now,
+			signature: auth.Sign(secret, http.MethodPost, "/api/v1/packets", now, []byte(body)),
+			wantCode:  http.StatusNoContent,
+		},
+		{
+			name:      "401 on signature of another body",
+			timestamp: now,
+			signature: auth.Sign(secret, http.MethodPost, "/api/v1/packets", now, []byte(`{"items": 1}`)),
+			wantCode:  http.StatusUnauthorized,
+		},
+		{
+			name:      "401 on a replayed signature",
+			timestamp: now,
+			signature: auth.Sign(secret, http.MethodPost, "/api/v1/packets", now, []byte(body)),
+			wantCode:  http.StatusUnauthorized,
+		},
+		{
+			name:      "401 on stale timestamp",
+			timestamp: "1700000000",
+			signature: auth.Sign(secret, http.MethodPost, "/api/v1/packets", "1700000000", []byte(body)),
+			wantCode:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actor, read = auth.Principal{}, ""
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/packets", strings.NewReader(body))
+			req.Header.Set(signatureClientHeader, "erp")
+			req.Header.Set(signatureTimestampHeader, tc.timestamp)
+			req.Header.Set(signatureHeader, tc.signature)
+
+			rr := httptest.NewRecorder()
+			server.verifySignature(server.authenticate(server.requireScope(auth.ScopePacketsCalculate, ok))).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+			if tc.wantCode == http.StatusNoContent {
+				require.Equal(t, "erp", actor.ID)
+				require.Equal(t, auth.MethodHMAC, actor.Method)
+				require.Equal(t, body, read, "the body is handed on")
+			} else {
+				require.True(t, strings.HasPrefix(rr.Header().Get("WWW-Authenticate"), "HMAC-SHA256"))
+			}
+		})
+	}
+
+	t.Run("401 on a replayed signature after a reload", func(t *testing.T) {
+		server := NewServer(cfg, nil, nil)
+		signedAt := strconv.FormatInt(time.Now().Unix(), 10)
+		send := func() int {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/packets", strings.NewReader(body))
+			req.Header.Set(signatureClientHeader, "erp")
+			req.Header.Set(signatureTimestampHeader, signedAt)
+			req.Header.Set(signatureHeader, auth.Sign(secret, http.MethodPost, "/api/v1/packets", signedAt, []byte(body)))
+
+			rr := httptest.NewRecorder()
+			server.verifySignature(server.authenticate(server.requireScope(auth.ScopePacketsCalculate, ok))).ServeHTTP(rr, req)
+			return rr.Code
+		}
+
+		require.Equal(t, http.StatusNoContent, send())
+		server.setConfig(cfg, nil)
+		require.Equal(t, http.StatusUnauthorized, send())
+	})
+
+	t.Run("401 when no clients are configured", func(t *testing.T) {
+		unsigned := cfg
+		unsigned.Auth.HMAC.Clients = nil
+		server := NewServer(unsigned, nil, nil)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/packets", strings.NewReader(body))
+		req.Header.Set(signatureClientHeader, "erp")
+		req.Header.Set(signatureTimestampHeader, now)
+		req.Header.Set(signatureHeader, auth.Sign(secret, http.MethodPost, "/api/v1/packets", now, []byte(body)))
+
+		rr := httptest.NewRecorder()
+		server.verifySignature(server.authenticate(server.requireScope(auth.ScopePacketsCalculate, ok))).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestWarnIfOpen(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	cfg := config.Default()
+	warnIfOpen(context.Background(), cfg)
+	require.Contains(t, buf.String(), "level=WARN msg=\"authentication is disabled")
+
+	buf.Reset()
+	cfg.Auth.Enabled = true
+	warnIfOpen(context.Background(), cfg)
+	require.Empty(t, buf.String())
+}
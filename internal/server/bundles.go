@@ -0,0 +1,90 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+)
+
+// Bundles are shared by every catalog, so the bundle handlers require their
+// permission on every catalog.
+func (s *Server) listBundlesHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, auth.ScopeSizesRead, auth.AllCatalogs) {
+		return
+	}
+
+	err := s.writeJSON(w, http.StatusOK, envelope{"bundles": s.PackerSrvc.Bundles.ListBundles()}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) putBundlesHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, auth.ScopeSizesWrite, auth.AllCatalogs) {
+		return
+	}
+
+	var input struct {
+		Bundles []packer.BundlePack `json:"bundles"`
+	}
+
+	err := s.readJSON(w, r, &input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	s.validateBundlesOnValue(v, input.Bundles)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	bundles, err := s.PackerSrvc.Bundles.PutBundles(r.Context(), input.Bundles)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"bundles": bundles}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) getBundlePacksHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, auth.ScopePacketsCalculate, auth.AllCatalogs) {
+		return
+	}
+
+	var input struct {
+		Demand map[string]int `json:"demand"`
+	}
+
+	err := s.readJSON(w, r, &input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	s.validateDemandOnValue(v, input.Demand)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	plan, err := s.PackerSrvc.GetBundlePackets(r.Context(), input.Demand)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"plan": plan}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundlesHandlers(t *testing.T) {
+	newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
+	newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+	server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+
+	testCases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		method   string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "200 on PUT",
+			handler:  server.putBundlesHandler,
+			method:   http.MethodPut,
+			body:     `{"bundles": [{"id": "kit", "contents": {"shirt": 100, "shorts": 100}}, {"id": "shirt-50", "contents": {"shirt": 50}}]}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "422 on PUT - invalid contents",
+			handler:  server.putBundlesHandler,
+			method:   http.MethodPut,
+			body:     `{"bundles": [{"id": "kit", "contents": {"shirt": -1}}]}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "200 on GET",
+			handler:  server.listBundlesHandler,
+			method:   http.MethodGet,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "200 on POST packets",
+			handler:  server.getBundlePacksHandler,
+			method:   http.MethodPost,
+			body:     `{"demand": {"shirt": 150, "shorts": 100}}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "400 on POST packets - uncoverable",
+			handler:  server.getBundlePacksHandler,
+			method:   http.MethodPost,
+			body:     `{"demand": {"hat": 1}}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "422 on POST packets - empty demand",
+			handler:  server.getBundlePacksHandler,
+			method:   http.MethodPost,
+			body:     `{"demand": {}}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 on POST packets - too many items",
+			handler:  server.getBundlePacksHandler,
+			method:   http.MethodPost,
+			body:     `{"demand": {"shirt": 1000000001}}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req, err := http.NewRequest(tc.method, "/api/v1/bundles", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			tc.handler(recorder, req)
+			require.Equal(t, tc.wantCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+)
+
+func (s *Server) listCatalogsHandler(w http.ResponseWriter, r *http.Request) {
+	catalogs := []string{}
+	for _, id := range s.PackerSrvc.Catalogs.ListCatalogs() {
+		if s.permitted(r, auth.ScopeSizesRead, id) {
+			catalogs = append(catalogs, id)
+		}
+	}
+
+	err := s.writeJSON(w, http.StatusOK, envelope{"catalogs": catalogs}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) createCatalogHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		ID    string `json:"id"`
+		Sizes []int  `json:"sizes"`
+	}
+
+	err := s.readJSON(w, r, &input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(validator.Matches(input.ID, validator.IDRX), "id", "id must be 1-64 letters, digits, dashes or underscores")
+	v.Check(len(input.Sizes) > 0, "sizes", "sizes must be more than 0 in quantity")
+	for _, size := range input.Sizes {
+		s.validateSizeOnValue(v, size)
+	}
+	v.Check(validator.Unique(input.Sizes), "sizes", "sizes must not contain duplicates")
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if !s.authorize(w, r, auth.ScopeSizesWrite, input.ID) {
+		return
+	}
+
+	catalog := packer.NewSizerService(input.Sizes)
+	err = s.PackerSrvc.Catalogs.AddCatalog(r.Context(), input.ID, catalog)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{
+		"catalog":      input.ID,
+		"sorted_sizes": catalog.ListSizes(),
+	}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
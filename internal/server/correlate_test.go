@@ -0,0 +1,103 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/logging"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestServer_Correlate(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(logging.NewContextHandler(slog.NewTextHandler(&buf, nil))))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	cfg := config.Default()
+	cfg.Limiter.TrustedProxies = []string{"10.0.0.0/8"}
+	server := NewServer(cfg, nil, nil)
+	handler := server.correlate(routed("/api/v1/sizes/:size", func(w http.ResponseWriter, r *http.Request) {
+		server.serverErrorResponse(w, r, errors.New("boom"))
+	}))
+
+	testCases := []struct {
+		name   string
+		header string
+		wantID string
+	}{
+		{name: "id of the client", header: "checkout-7f3a.1", wantID: "checkout-7f3a.1"},
+		{name: "no id", header: ""},
+		{name: "malformed id", header: "bad id\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/sizes/250", nil)
+			req.RemoteAddr = "10.1.2.3:5123"
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			if tc.header != "" {
+				req.Header.Set(requestIDHeader, tc.header)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			id := rr.Header().Get(requestIDHeader)
+			if tc.wantID != "" {
+				require.Equal(t, tc.wantID, id)
+			} else {
+				require.Regexp(t, `^[0-9a-f]{32}$`, id)
+			}
+
+			var body struct {
+				RequestID string `json:"request_id"`
+			}
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+			require.Equal(t, id, body.RequestID)
+
+			require.Contains(t, buf.String(), "request_id="+id+" method=DELETE route=/api/v1/sizes/:size client=203.0.113.7")
+		})
+	}
+
+	t.Run("proxies of a reloaded configuration", func(t *testing.T) {
+		buf.Reset()
+		server.setConfig(config.Default(), nil)
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/sizes/250", nil)
+		req.RemoteAddr = "10.1.2.3:5123"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		require.Contains(t, buf.String(), "client=10.1.2.3")
+	})
+}
+
+func TestServer_Correlate_ImportReport(t *testing.T) {
+	sizer := packer.NewSizerService([]int{250, 500, 1000})
+	server := NewServer(config.Default(), sizer, packer.NewPacketsService(packer.NewCatalogService(sizer), packer.NewProfileService()))
+	handler := server.correlate(http.HandlerFunc(server.importSizesHandler))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sizes/import?format=json", bytes.NewBufferString(`{"sizes": [100, -1]}`))
+	req.Header.Set(requestIDHeader, "import-1")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	var body struct {
+		RequestID string          `json:"request_id"`
+		Report    json.RawMessage `json:"report"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Equal(t, "import-1", body.RequestID)
+	require.NotEmpty(t, body.Report)
+}
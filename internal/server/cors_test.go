@@ -0,0 +1,136 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_EnableCORS(t *testing.T) {
+	restricted := config.Default()
+	restricted.CORS.AllowedOrigins = []string{"https://shop.example", "https://*.gymshark.example"}
+	restricted.CORS.AllowCredentials = true
+	restricted.CORS.MaxAge = time.Hour
+	require.NoError(t, restricted.Validate())
+
+	testCases := []struct {
+		name        string
+		cfg         config.Config
+		method      string
+		headers     map[string]string
+		wantCode    int
+		wantHeaders map[string]string
+		wantVary    []string
+	}{
+		{
+			name:        "any origin",
+			cfg:         config.Default(),
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "https://elsewhere.example"},
+			wantCode:    http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "*", "Access-Control-Allow-Credentials": ""},
+		},
+		{
+			name:   "preflight of any origin",
+			cfg:    config.Default(),
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://elsewhere.example",
+				"Access-Control-Request-Method":  http.MethodPut,
+				"Access-Control-Request-Headers": "content-type, x-api-key",
+			},
+			wantCode: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "*",
+				"Access-Control-Allow-Methods": "GET, POST, PUT, DELETE",
+				"Access-Control-Allow-Headers": "Content-Type, Authorization, X-API-Key, X-Client-ID, X-Signature-Timestamp, X-Signature, X-Request-ID",
+				"Access-Control-Max-Age":       "600",
+			},
+			wantVary: []string{"Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+		{
+			name:        "exact origin with credentials",
+			cfg:         restricted,
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "https://shop.example"},
+			wantCode:    http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "https://shop.example", "Access-Control-Allow-Credentials": "true"},
+			wantVary:    []string{"Origin"},
+		},
+		{
+			name:   "preflight of a wildcard origin",
+			cfg:    restricted,
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://uk.gymshark.example",
+				"Access-Control-Request-Method": http.MethodDelete,
+			},
+			wantCode: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://uk.gymshark.example",
+				"Access-Control-Allow-Methods": "GET, POST, PUT, DELETE",
+				"Access-Control-Max-Age":       "3600",
+			},
+			wantVary: []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		},
+		{
+			name:        "origin not allowed",
+			cfg:         restricted,
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "https://gymshark.example.evil"},
+			wantCode:    http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": ""},
+			wantVary:    []string{"Origin"},
+		},
+		{
+			name:   "preflight of a method not allowed",
+			cfg:    restricted,
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://shop.example",
+				"Access-Control-Request-Method": http.MethodPatch,
+			},
+			wantCode:    http.StatusNoContent,
+			wantHeaders: map[string]string{"Access-Control-Allow-Methods": ""},
+		},
+		{
+			name:   "preflight of a header not allowed",
+			cfg:    restricted,
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://shop.example",
+				"Access-Control-Request-Method":  http.MethodPut,
+				"Access-Control-Request-Headers": "X-Debug",
+			},
+			wantCode:    http.StatusNoContent,
+			wantHeaders: map[string]string{"Access-Control-Allow-Headers": ""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(tc.cfg, nil, nil)
+			req := httptest.NewRequest(tc.method, "/api/v1/sizes", nil)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+
+			rr := httptest.NewRecorder()
+			server.enableCORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+			for key, value := range tc.wantHeaders {
+				require.Equal(t, value, rr.Header().Get(key), key)
+			}
+			for _, vary := range tc.wantVary {
+				require.Contains(t, rr.Header().Values("Vary"), vary)
+			}
+		})
+	}
+}
@@ -1,20 +1,32 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+
+	"github.com/SkNuwanTissera/gymshark/internal/logging"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
 	"golang.org/x/exp/slog"
 )
 
 func (s *Server) logError(r *http.Request, err error) {
-	slog.Error(err.Error(),
+	slog.ErrorContext(r.Context(), err.Error(),
 		slog.Any("request_method", r.Method),
 		slog.Any("request_url", r.URL.String()),
 	)
 }
 
 func (s *Server) errorResponse(w http.ResponseWriter, r *http.Request, status int, message any) {
-	env := envelope{"error": message}
+	s.errorEnvelopeResponse(w, r, status, envelope{"error": message})
+}
+
+// errorEnvelopeResponse writes an error envelope holding more than the error,
+// tagged with the request ID like every other error.
+func (s *Server) errorEnvelopeResponse(w http.ResponseWriter, r *http.Request, status int, env envelope) {
+	if req, ok := logging.RequestFrom(r.Context()); ok {
+		env["request_id"] = req.ID
+	}
 	err := s.writeJSON(w, status, env, nil)
 	if err != nil {
 		s.logError(r, err)
@@ -50,3 +62,98 @@ func (s *Server) methodNotAllowedResponse(w http.ResponseWriter, r *http.Request
 	message := fmt.Sprintf("the %s method is not supported for this resource", r.Method)
 	s.errorResponse(w, r, http.StatusMethodNotAllowed, message)
 }
+
+func (s *Server) invalidAPIKeyResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", `APIKey header="X-API-Key"`)
+	message := "invalid or unknown API key"
+	s.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (s *Server) invalidTokenResponse(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, err.Error()))
+	message := "invalid or expired bearer token"
+	s.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (s *Server) invalidSignatureResponse(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`HMAC-SHA256 error=%q`, err.Error()))
+	message := "invalid or expired request signature"
+	s.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (s *Server) authenticationRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("WWW-Authenticate", `APIKey header="X-API-Key"`)
+	current := s.current.Load()
+	if current.tokens != nil {
+		w.Header().Add("WWW-Authenticate", "Bearer")
+	}
+	if current.signatures != nil {
+		w.Header().Add("WWW-Authenticate", `HMAC-SHA256 headers="X-Client-ID X-Signature-Timestamp X-Signature"`)
+	}
+	message := "you must be authenticated to access this resource"
+	s.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (s *Server) notPermittedResponse(w http.ResponseWriter, r *http.Request, scope string) {
+	message := fmt.Sprintf("your credentials lack the %s scope required by this resource", scope)
+	s.errorResponse(w, r, http.StatusForbidden, message)
+}
+
+func (s *Server) catalogNotPermittedResponse(w http.ResponseWriter, r *http.Request, permission, catalog string) {
+	message := fmt.Sprintf("you are not permitted %s on the %s catalog", permission, catalog)
+	s.errorResponse(w, r, http.StatusForbidden, message)
+}
+
+func (s *Server) conflictResponse(w http.ResponseWriter, r *http.Request, err error) {
+	s.errorResponse(w, r, http.StatusConflict, err.Error())
+}
+
+func (s *Server) packingErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var infeasibleErr *packer.InfeasibleError
+	if errors.As(err, &infeasibleErr) {
+		s.errorResponse(w, r, http.StatusUnprocessableEntity, envelope{
+			"constraint": infeasibleErr.Constraint,
+			"reason":     infeasibleErr.Reason,
+		})
+		return
+	}
+	var overshootErr *packer.OvershootError
+	if errors.As(err, &overshootErr) {
+		s.errorResponse(w, r, http.StatusUnprocessableEntity, envelope{
+			"message":       overshootErr.Error(),
+			"catalog":       overshootErr.CatalogID,
+			"items":         overshootErr.Items,
+			"total":         overshootErr.Total,
+			"overshoot":     overshootErr.Overshoot,
+			"max_overshoot": overshootErr.MaxOvershoot,
+		})
+		return
+	}
+	switch err.Error() {
+	case packer.ErrorProfileNotFound:
+		s.failedValidationResponse(w, r, map[string]string{"profile_id": err.Error()})
+		return
+	case packer.ErrorCatalogNotFound:
+		s.failedValidationResponse(w, r, map[string]string{"catalog": err.Error()})
+		return
+	case packer.ErrorPricingNotFound, packer.ErrorSizeNotPriced, packer.ErrorPriceOverflow:
+		s.failedValidationResponse(w, r, map[string]string{"pricing": err.Error()})
+		return
+	case packer.ErrorNoCatalogHistory:
+		s.failedValidationResponse(w, r, map[string]string{"as_of": err.Error()})
+		return
+	case packer.ErrorUnfulfillable:
+		s.failedValidationResponse(w, r, map[string]string{"warehouses": err.Error()})
+		return
+	case packer.ErrorInsufficientStock, packer.ErrorReservationNotHeld:
+		s.conflictResponse(w, r, err)
+		return
+	case packer.ErrorReservationNotFound:
+		s.notFoundResponse(w, r)
+		return
+	case packer.ErrorUnitNotFound, packer.ErrorNonIntegralConversion:
+		s.failedValidationResponse(w, r, map[string]string{"quantity": err.Error()})
+		return
+	}
+	s.badRequestResponse(w, r, err)
+}
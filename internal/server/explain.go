@@ -0,0 +1,106 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// rejectionReasons holds human-readable texts of packer rejection reasons.
+var rejectionReasons = map[string]string{
+	packer.RejectedBelowOrder:       "it does not cover the order",
+	packer.RejectedAboveOrder:       "it ships more items than ordered",
+	packer.RejectedMoreOvershoot:    "it ships more items than the chosen packing",
+	packer.RejectedFewerItems:       "it ships fewer items than the chosen packing",
+	packer.RejectedFurtherFromOrder: "it is further from the order than the chosen packing",
+	packer.RejectedMaxTotalPacks:    "it needs more packs than the maximum allowed",
+}
+
+type explainedPackets struct {
+	packer.Explanation
+	Derivation []string `json:"derivation"`
+}
+
+func (s *Server) explainPacks(w http.ResponseWriter, r *http.Request, items int, opts []packer.PacketsOption) {
+	explanation, err := s.PackerSrvc.ExplainPackets(r.Context(), items, opts...)
+	if err != nil {
+		s.packingErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{
+		"packets": explanation.Packets,
+		"explanation": explainedPackets{
+			Explanation: explanation,
+			Derivation:  derivation(explanation),
+		},
+	}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+// derivation renders the explanation as sentences support staff can read out.
+func derivation(e packer.Explanation) []string {
+	lines := []string{
+		fmt.Sprintf("Catalog %q version %d offers pack sizes %s.", e.CatalogID, e.CatalogVersion, formatSizes(e.CatalogSizes)),
+	}
+	if e.ProfileID != "" {
+		lines = append(lines, fmt.Sprintf("Customer profile %q was applied.", e.ProfileID))
+	}
+	if !slices.Equal(e.CatalogSizes, e.AllowedSizes) {
+		lines = append(lines, fmt.Sprintf("Constraints allow pack sizes %s only.", formatSizes(e.AllowedSizes)))
+	}
+	lines = append(lines, fmt.Sprintf("The objective for %d items is to %s.", e.Items, e.Objective))
+
+	var chosen packer.Candidate
+	for _, candidate := range e.Candidates {
+		if candidate.Chosen {
+			chosen = candidate
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s makes %d items in %d packs and lost because %s.",
+			formatPackets(candidate.Packets), candidate.Total, candidate.Packs, rejectionReasons[candidate.Rejected]))
+	}
+	lines = append(lines, fmt.Sprintf("%s was chosen: %d items in %d packs, %s.",
+		formatPackets(chosen.Packets), chosen.Total, chosen.Packs, formatOvershoot(chosen.Overshoot)))
+
+	return lines
+}
+
+// formatPackets renders packets as "2×5000 + 1×2000", largest packs first.
+func formatPackets(packets map[int]int) string {
+	sizes := maps.Keys(packets)
+	slices.Sort(sizes)
+	slices.Reverse(sizes)
+
+	parts := make([]string, 0, len(sizes))
+	for _, size := range sizes {
+		if packets[size] > 0 {
+			parts = append(parts, fmt.Sprintf("%d×%d", packets[size], size))
+		}
+	}
+	return strings.Join(parts, " + ")
+}
+
+func formatSizes(sizes []int) string {
+	parts := make([]string, 0, len(sizes))
+	for _, size := range sizes {
+		parts = append(parts, fmt.Sprint(size))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatOvershoot(overshoot int) string {
+	switch {
+	case overshoot > 0:
+		return fmt.Sprintf("%d over the order", overshoot)
+	case overshoot < 0:
+		return fmt.Sprintf("%d under the order", -overshoot)
+	}
+	return "exactly the order"
+}
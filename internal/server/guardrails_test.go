@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardrailsHandlers(t *testing.T) {
+	newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
+	newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+	server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+
+	newRequest := func(method, id, body string) *http.Request {
+		req, err := http.NewRequest(method, "/api/v1/catalogs/"+id+"/guardrail", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		params := httprouter.Params{{Key: "id", Value: id}}
+		return req.WithContext(context.WithValue(req.Context(), httprouter.ParamsKey, params))
+	}
+
+	testCases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		request  *http.Request
+		wantCode int
+	}{
+		{
+			name:     "200 on PUT",
+			handler:  server.putGuardrailHandler,
+			request:  newRequest(http.MethodPut, packer.DefaultCatalogID, `{"max_overshoot_percent": 50}`),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "422 on PUT - negative",
+			handler:  server.putGuardrailHandler,
+			request:  newRequest(http.MethodPut, packer.DefaultCatalogID, `{"max_overshoot": -1}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "404 on PUT - unknown catalog",
+			handler:  server.putGuardrailHandler,
+			request:  newRequest(http.MethodPut, "unknown", `{"max_overshoot": 1}`),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "200 on GET",
+			handler:  server.showGuardrailHandler,
+			request:  newRequest(http.MethodGet, packer.DefaultCatalogID, ""),
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tc.handler(recorder, tc.request)
+			require.Equal(t, tc.wantCode, recorder.Code)
+		})
+	}
+
+	t.Run("422 on POST packets - overshoot", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/api/v1/packets", bytes.NewBufferString(`{"items": 1}`))
+		require.NoError(t, err)
+
+		server.getPacksHandler(recorder, req)
+		require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+		var response struct {
+			Error struct {
+				Overshoot    int `json:"overshoot"`
+				MaxOvershoot int `json:"max_overshoot"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		require.Equal(t, 249, response.Error.Overshoot)
+		require.Equal(t, 0, response.Error.MaxOvershoot)
+	})
+}
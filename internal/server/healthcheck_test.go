@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/SkNuwanTissera/gymshark/internal/config"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
@@ -59,7 +60,7 @@ func TestHandlers_HealthcheckHandler(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			server := NewServer(nil, nil)
+			server := NewServer(config.Default(), nil, nil)
 			recorder := httptest.NewRecorder()
 
 			url := "/v1/healthcheck"
@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -85,3 +88,38 @@ func (s *Server) readSizeParam(r *http.Request) (int, error) {
 	}
 	return int(size), nil
 }
+
+func (s *Server) readIDParam(r *http.Request) (string, error) {
+	params := httprouter.ParamsFromContext(r.Context())
+	id := params.ByName("id")
+	if !validator.Matches(id, validator.IDRX) {
+		return "", errors.New("invalid id parameter")
+	}
+	return id, nil
+}
+
+func (s *Server) readBool(qs url.Values, key string, defaultValue bool, v *validator.Validator) bool {
+	value := qs.Get(key)
+	if value == "" {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		v.AddError(key, "must be a boolean value")
+		return defaultValue
+	}
+	return b
+}
+
+func (s *Server) readTime(qs url.Values, key string, v *validator.Validator) time.Time {
+	value := qs.Get(key)
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		v.AddError(key, "must be an RFC 3339 timestamp")
+		return time.Time{}
+	}
+	return t
+}
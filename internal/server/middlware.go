@@ -1,16 +1,24 @@
 package server
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"expvar"
 	"fmt"
-	"net"
+	"io"
+	"math"
 	"net/http"
+	"regexp"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
 
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/logging"
 	"github.com/felixge/httpsnoop"
-	"golang.org/x/time/rate"
+	"golang.org/x/exp/slices"
 )
 
 func (s *Server) recoverPanic(next http.Handler) http.Handler {
@@ -25,38 +33,151 @@ func (s *Server) recoverPanic(next http.Handler) http.Handler {
 	})
 }
 
+// requestIDHeader is the request and response header carrying the request ID.
+const requestIDHeader = "X-Request-ID"
+
+// requestIDRX matches the request IDs of clients that are taken as they are.
+var requestIDRX = regexp.MustCompile(`^[a-zA-Z0-9._:-]{1,128}$`)
+
+// correlate puts the request scoped log values in the context of the request,
+// so that every entry logged while serving it can be tied to it. The request
+// ID of the client is kept when well formed, otherwise a new one is made. The
+// ID is echoed in the response.
+func (s *Server) correlate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !requestIDRX.MatchString(id) {
+			b := make([]byte, 16)
+			if _, err := rand.Read(b); err != nil {
+				s.serverErrorResponse(w, r, err)
+				return
+			}
+			id = hex.EncodeToString(b)
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		req := &logging.Request{
+			ID:     id,
+			Method: r.Method,
+			Client: clientAddr(r, s.current.Load().proxies),
+		}
+		next.ServeHTTP(w, r.WithContext(logging.WithRequest(r.Context(), req)))
+	})
+}
+
+// routed records the route of the handler in the request scoped log values.
+func routed(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if req, ok := logging.RequestFrom(r.Context()); ok {
+			req.Route = route
+		}
+		next(w, r)
+	}
+}
+
+// enableCORS applies the cross-origin policy. Preflight requests of allowed
+// origins are answered here, ahead of the rate limit and authentication, as
+// browsers send them without credentials.
 func (s *Server) enableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		next.ServeHTTP(w, r)
+		cors := s.Config().CORS
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && origin != "" && r.Header.Get("Access-Control-Request-Method") != ""
+
+		// The response depends on the origin unless any origin gets the same
+		// answer, so caches must tell them apart.
+		anyOrigin := cors.AnyOrigin() && !cors.AllowCredentials
+		if !anyOrigin {
+			w.Header().Add("Vary", "Origin")
+		}
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if origin == "" || !(cors.AnyOrigin() || cors.AllowsOrigin(origin)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if anyOrigin {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if cors.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			w.Header().Set("Access-Control-Expose-Headers", requestIDHeader)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		method := r.Header.Get("Access-Control-Request-Method")
+		var headers []string
+		for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				headers = append(headers, header)
+			}
+		}
+		if !slices.Contains(cors.AllowedMethods, method) || !cors.AllowsHeaders(headers) {
+			// Without the allow headers the browser turns the request down.
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(cors.AllowedMethods, ", "))
+		if slices.Contains(cors.AllowedHeaders, "*") {
+			if len(headers) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+			}
+		} else {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(cors.AllowedHeaders, ", "))
+		}
+		if cors.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(cors.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
 	})
 }
 
+// rateLimit takes a token from the limiter of the client for every request
+// and turns the request away when none is left. Responses tell the client its
+// limit and the tokens it has left.
 func (s *Server) rateLimit(next http.Handler) http.Handler {
-	// Hold the clients' IP addresses and rate limiters.
-	var (
-		mu      sync.Mutex
-		clients = make(map[string]*rate.Limiter)
-	)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		current := s.current.Load()
+		cfg := current.config
+		if !cfg.Limiter.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Known API keys have limits of their own; unknown ones count against
+		// the IP address so that made up keys cannot dodge the limit.
+		var keyID string
+		if key := r.Header.Get(apiKeyHeader); cfg.Auth.Enabled && key != "" {
+			if principal, ok := current.keys.Authenticate(key); ok {
+				keyID = principal.ID
+			}
+		}
+
+		decision, err := s.limits.take(r.Context(), cfg.Limiter, r, current.proxies, keyID, time.Now())
 		if err != nil {
 			s.serverErrorResponse(w, r, err)
 			return
 		}
-		// Lock the mutex to prevent this code from being executed concurrently.
-		mu.Lock()
-		if _, found := clients[ip]; !found {
-			clients[ip] = rate.NewLimiter(10, 10)
-		}
 
-		if !clients[ip].Allow() {
-			mu.Unlock()
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		if !decision.Allowed {
+			retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
 			s.rateLimitExceededResponse(w, r)
 			return
 		}
 
-		mu.Unlock()
 		next.ServeHTTP(w, r)
 	})
 }
@@ -81,3 +202,147 @@ func (s *Server) metrics(next http.Handler) http.Handler {
 		totalResponsesSentByStatus.Add(strconv.Itoa(metrics.Code), 1)
 	})
 }
+
+// apiKeyHeader is the request header carrying the API key.
+const apiKeyHeader = "X-API-Key"
+
+// Request headers of signed requests.
+const (
+	signatureClientHeader    = "X-Client-ID"
+	signatureTimestampHeader = "X-Signature-Timestamp"
+	signatureHeader          = "X-Signature"
+)
+
+// maxSignedBodyBytes caps the body of a signed request, which is read in full
+// to check its digest.
+const maxSignedBodyBytes = 1_048_576
+
+// verifySignature puts the principal of a signed request in its context. The
+// body is read to check its digest and handed on unchanged. Unsigned requests
+// carry on to authenticate.
+func (s *Server) verifySignature(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature := r.Header.Get(signatureHeader)
+		current := s.current.Load()
+		if !current.config.Auth.Enabled || signature == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		signatures := current.signatures
+		if signatures == nil {
+			s.invalidSignatureResponse(w, r, errors.New("signed requests are not accepted"))
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedBodyBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				err = fmt.Errorf("body must not be larger than %d bytes", maxSignedBodyBytes)
+			}
+			s.badRequestResponse(w, r, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		principal, err := signatures.Verify(
+			r.Header.Get(signatureClientHeader),
+			r.Header.Get(signatureTimestampHeader),
+			signature,
+			r.Method,
+			r.URL.RequestURI(),
+			body,
+		)
+		if err != nil {
+			s.invalidSignatureResponse(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// authenticate puts the principal of the request's API key or bearer token in
+// its context, unless verifySignature already did. Requests without
+// credentials carry on anonymously and are turned away by requireScope on
+// every route but the public ones.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := s.current.Load()
+		if !current.config.Auth.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if _, signed := auth.PrincipalFrom(r.Context()); signed {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", apiKeyHeader)
+		w.Header().Add("Vary", "Authorization")
+
+		var principal auth.Principal
+		key := r.Header.Get(apiKeyHeader)
+		token, bearer := bearerToken(r.Header.Get("Authorization"))
+		switch {
+		case key != "":
+			var ok bool
+			principal, ok = current.keys.Authenticate(key)
+			if !ok {
+				s.invalidAPIKeyResponse(w, r)
+				return
+			}
+		case bearer:
+			tokens := current.tokens
+			if tokens == nil {
+				s.invalidTokenResponse(w, r, errors.New("bearer tokens are not accepted"))
+				return
+			}
+			var err error
+			principal, err = tokens.Verify(token)
+			if err != nil {
+				s.invalidTokenResponse(w, r, err)
+				return
+			}
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// bearerToken returns the token of an Authorization header of the Bearer
+// scheme, whose name is case-insensitive as of RFC 6750.
+func bearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// requireScope lets the request through only when its principal is granted
+// the scope, or when authentication is disabled.
+func (s *Server) requireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.Config().Auth.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, ok := auth.PrincipalFrom(r.Context())
+		if !ok {
+			s.authenticationRequiredResponse(w, r)
+			return
+		}
+		if !principal.HasScope(scope) {
+			s.notPermittedResponse(w, r, scope)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+)
+
+const maxOrderLines = 1000
+
+func (s *Server) getOrderPacksHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		ProfileID string             `json:"profile_id"`
+		Lines     []packer.OrderLine `json:"lines"`
+	}
+
+	err := s.readJSON(w, r, &input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	s.validateOrderLinesOnValue(v, input.Lines)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	authorized := make(map[string]bool)
+	for _, line := range input.Lines {
+		catalog := line.Catalog
+		if catalog == "" {
+			catalog = packer.DefaultCatalogID
+		}
+		if authorized[catalog] {
+			continue
+		}
+		if !s.authorize(w, r, auth.ScopePacketsCalculate, catalog) {
+			return
+		}
+		authorized[catalog] = true
+	}
+
+	var opts []packer.PacketsOption
+	if input.ProfileID != "" {
+		opts = append(opts, packer.WithProfile(input.ProfileID))
+	}
+
+	plan, err := s.PackerSrvc.GetOrderPackets(r.Context(), input.Lines, opts...)
+	if err != nil {
+		s.packingErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{
+		"order": plan,
+	}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrdersHandler_getOrderPacks(t *testing.T) {
+	newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
+	newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+	server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/catalogs", bytes.NewBufferString(`{"id": "kits", "sizes": [20, 10]}`))
+	require.NoError(t, err)
+	server.createCatalogHandler(recorder, req)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	testCases := []struct {
+		name          string
+		body          string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "200 on POST",
+			body: `{"lines": [{"id": "a", "items": 6000}, {"id": "b", "catalog": "kits", "items": 15}, {"id": "c", "items": 6001}]}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var response struct {
+					Order packer.OrderPlan `json:"order"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Len(t, response.Order.Catalogs, 2)
+				require.Len(t, response.Order.Lines, 3)
+				require.Equal(t, 249, response.Order.Lines[2].Overshoot)
+			},
+		},
+		{
+			name: "422 on POST - duplicated line ids",
+			body: `{"lines": [{"id": "a", "items": 1}, {"id": "a", "items": 1}]}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "422 on POST - unknown catalog",
+			body: `{"lines": [{"id": "a", "catalog": "unknown", "items": 1}]}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "422 on POST - no lines",
+			body: `{"lines": []}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/orders/packets", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			server.getOrderPacksHandler(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -2,13 +2,21 @@ package server
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
 	"github.com/SkNuwanTissera/gymshark/internal/validator"
 )
 
 func (s *Server) getPacksHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Items int `json:"items"`
+		Items       int                 `json:"items"`
+		Quantity    *packer.Quantity    `json:"quantity"`
+		Catalog     string              `json:"catalog"`
+		ProfileID   string              `json:"profile_id"`
+		Constraints *packer.Constraints `json:"constraints"`
+		AsOf        *time.Time          `json:"as_of"`
 	}
 
 	err := s.readJSON(w, r, &input)
@@ -18,15 +26,64 @@ func (s *Server) getPacksHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	v := validator.New()
-	s.validateItemsOnValue(v, input.Items)
+	explain := s.readBool(r.URL.Query(), "explain", false, v)
+	backorder := s.readBool(r.URL.Query(), "backorder", false, v)
+	v.Check(!explain || !backorder, "backorder", "backorder must not be combined with explain")
+	if input.Catalog == "" {
+		input.Catalog = packer.DefaultCatalogID
+	}
+	if input.Quantity != nil {
+		v.Check(input.Items == 0, "items", "items must not be combined with quantity")
+		s.validateQuantityOnValue(v, input.Catalog, *input.Quantity)
+	} else {
+		s.validateItemsOnValue(v, input.Items)
+	}
+	opts := []packer.PacketsOption{packer.WithCatalog(input.Catalog)}
+	if input.Constraints != nil {
+		s.validateConstraintsOnValue(v, *input.Constraints)
+		opts = append(opts, packer.WithConstraints(*input.Constraints))
+	}
+	if input.ProfileID != "" {
+		opts = append(opts, packer.WithProfile(input.ProfileID))
+	}
+	if input.AsOf != nil {
+		opts = append(opts, packer.WithAsOf(*input.AsOf))
+	}
 	if !v.Valid() {
 		s.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	packets, err := s.PackerSrvc.GetPackets(r.Context(), input.Items)
+	if !s.authorize(w, r, auth.ScopePacketsCalculate, input.Catalog) {
+		return
+	}
+
+	if input.Quantity != nil && !explain && !backorder {
+		s.getUnitPacks(w, r, *input.Quantity, opts)
+		return
+	}
+
+	items := input.Items
+	if input.Quantity != nil {
+		items, err = s.PackerSrvc.Units.Convert(input.Catalog, *input.Quantity)
+		if err != nil {
+			s.packingErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	switch {
+	case explain:
+		s.explainPacks(w, r, items, opts)
+		return
+	case backorder:
+		s.getBackorderPacks(w, r, items, opts)
+		return
+	}
+
+	packets, err := s.PackerSrvc.GetPackets(r.Context(), items, opts...)
 	if err != nil {
-		s.badRequestResponse(w, r, err)
+		s.packingErrorResponse(w, r, err)
 		return
 	}
 
@@ -37,3 +94,85 @@ func (s *Server) getPacksHandler(w http.ResponseWriter, r *http.Request) {
 		s.serverErrorResponse(w, r, err)
 	}
 }
+
+func (s *Server) getUnitPacks(w http.ResponseWriter, r *http.Request, quantity packer.Quantity, opts []packer.PacketsOption) {
+	result, err := s.PackerSrvc.GetUnitPackets(r.Context(), quantity, opts...)
+	if err != nil {
+		s.packingErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{
+		"packets":       result.Packets,
+		"items":         result.Items,
+		"total":         result.Total,
+		"unit":          result.Unit,
+		"factor":        result.Factor,
+		"quantity":      result.Quantity,
+		"total_in_unit": result.TotalInUnit,
+	}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) getBackorderPacks(w http.ResponseWriter, r *http.Request, items int, opts []packer.PacketsOption) {
+	plan, err := s.PackerSrvc.GetBackorderPackets(r.Context(), items, opts...)
+	if err != nil {
+		s.packingErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"plan": plan}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) getContainerPlanHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Items     int                  `json:"items"`
+		Catalog   string               `json:"catalog"`
+		ProfileID string               `json:"profile_id"`
+		Spec      packer.HierarchySpec `json:"spec"`
+	}
+
+	err := s.readJSON(w, r, &input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	s.validateItemsOnValue(v, input.Items)
+	s.validateHierarchyOnValue(v, input.Spec)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if input.Catalog == "" {
+		input.Catalog = packer.DefaultCatalogID
+	}
+	if !s.authorize(w, r, auth.ScopePacketsCalculate, input.Catalog) {
+		return
+	}
+
+	opts := []packer.PacketsOption{packer.WithCatalog(input.Catalog)}
+	if input.ProfileID != "" {
+		opts = append(opts, packer.WithProfile(input.ProfileID))
+	}
+
+	plan, err := s.PackerSrvc.GetContainerPlan(r.Context(), input.Items, input.Spec, opts...)
+	if err != nil {
+		s.packingErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{
+		"plan": plan,
+	}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/SkNuwanTissera/gymshark/internal/config"
 	"github.com/SkNuwanTissera/gymshark/internal/mock"
 	"github.com/SkNuwanTissera/gymshark/internal/packer"
 	"github.com/golang/mock/gomock"
@@ -47,6 +48,17 @@ func TestPacketsHandler_getPacks(t *testing.T) {
 				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
 			},
 		},
+		{
+			name:   "422 on POST - too many items",
+			method: http.MethodGet,
+			items:  packer.MaxItems + 1,
+			buildStubs: func(sizer *mock.MockPacker) {
+				sizer.EXPECT().GetPackets(context.Background(), 1).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
 		{
 			name:   "422 on POST - (-1)",
 			method: http.MethodGet,
@@ -66,9 +78,9 @@ func TestPacketsHandler_getPacks(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			newPackerSrvc := packer.NewPacketsService()
 			newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
-			server := NewServer(newSizerSrvc, newPackerSrvc)
+			newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+			server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
 			recorder := httptest.NewRecorder()
 
 			url := "/v1/packets"
@@ -83,3 +95,230 @@ func TestPacketsHandler_getPacks(t *testing.T) {
 		})
 	}
 }
+
+func TestPacketsHandler_getContainerPlan(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "200 on POST - packs capacity",
+			body: `{"items": 12001, "spec": {"carton": {"capacity": {"max_units": 2}}, "pallet": {"capacity": {"max_units": 1}}}}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				var response struct {
+					Plan packer.ContainerPlan `json:"plan"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, 2, response.Plan.TotalPallets)
+			},
+		},
+		{
+			name: "422 on POST - empty capacity",
+			body: `{"items": 12001, "spec": {"carton": {"capacity": {}}, "pallet": {"capacity": {"max_units": 1}}}}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "422 on POST - negative carton weight",
+			body: `{"items": 10, "spec": {"carton": {"capacity": {"max_units": 2}, "weight": -1}, "pallet": {"capacity": {"max_units": 1}}}}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "422 on POST - zero pack volume",
+			body: `{"items": 10, "spec": {"carton": {"capacity": {"max_volume": 10}}, "pallet": {"capacity": {"max_units": 1}}, "packs": {"250": {"volume": 0, "weight": 1}}}}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "422 on POST - negative pack weight",
+			body: `{"items": 10, "spec": {"carton": {"capacity": {"max_weight": 10}}, "pallet": {"capacity": {"max_units": 1}}, "packs": {"250": {"volume": 1, "weight": -2}}}}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "400 on POST - pack does not fit",
+			body: `{"items": 10, "spec": {"carton": {"capacity": {"max_weight": 1}}, "pallet": {"capacity": {"max_units": 1}}, "packs": {"250": {"volume": 1, "weight": 5}}}}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
+			newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+			server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/packets/plan", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			server.getContainerPlanHandler(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPacketsHandler_getPacksWithConstraints(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "200 on POST - max total packs",
+			body: `{"items": 12001, "constraints": {"max_total_packs": 3}}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"packets": {"5000": 3}}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "422 on POST - negative constraint",
+			body: `{"items": 12001, "constraints": {"max_per_size": {"250": -1}}}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "422 on POST - infeasible constraint",
+			body: `{"items": 12001, "constraints": {"max_total_packs": 2, "max_per_size": {"5000": 2}}}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				var response struct {
+					Error struct {
+						Constraint string `json:"constraint"`
+					} `json:"error"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, packer.ConstraintMaxTotalPacks, response.Error.Constraint)
+			},
+		},
+		{
+			name: "422 on POST - unknown profile",
+			body: `{"items": 12001, "profile_id": "unknown"}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
+			newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+			server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/packets", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			server.getPacksHandler(recorder, req)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPacketsHandler_getPacksExplain(t *testing.T) {
+	newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
+	newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+	server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/packets?explain=true", bytes.NewBufferString(`{"items": 12001}`))
+	require.NoError(t, err)
+
+	server.getPacksHandler(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response struct {
+		Packets     map[int]int `json:"packets"`
+		Explanation struct {
+			CatalogVersion int      `json:"catalog_version"`
+			Derivation     []string `json:"derivation"`
+		} `json:"explanation"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Equal(t, map[int]int{5000: 2, 2000: 1, 250: 1}, response.Packets)
+	require.Equal(t, 1, response.Explanation.CatalogVersion)
+	require.Contains(t, response.Explanation.Derivation,
+		"2×5000 + 1×2000 + 1×250 was chosen: 12250 items in 4 packs, 249 over the order.")
+
+	recorder = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodPost, "/api/v1/packets?explain=maybe", bytes.NewBufferString(`{"items": 12001}`))
+	require.NoError(t, err)
+
+	server.getPacksHandler(recorder, req)
+	require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+}
+
+func TestGetPacksHandlerBackorder(t *testing.T) {
+	newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
+	newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+	server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+	require.NoError(t, newPackerSrvc.Stock.SetStock(context.Background(), packer.DefaultCatalogID, map[int]int{250: 1}))
+
+	testCases := []struct {
+		name     string
+		target   string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "200 - split",
+			target:   "/api/v1/packets?backorder=true",
+			body:     `{"items": 501}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "422 - combined with explain",
+			target:   "/api/v1/packets?backorder=true&explain=true",
+			body:     `{"items": 501}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 - invalid flag",
+			target:   "/api/v1/packets?backorder=maybe",
+			body:     `{"items": 501}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodPost, tc.target, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			server.getPacksHandler(recorder, req)
+			require.Equal(t, tc.wantCode, recorder.Code)
+		})
+	}
+
+	t.Run("200 - both plans returned", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/api/v1/packets?backorder=true", bytes.NewBufferString(`{"items": 501}`))
+		require.NoError(t, err)
+
+		server.getPacksHandler(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var response struct {
+			Plan packer.BackorderPlan `json:"plan"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		require.Equal(t, packer.Shipment{Items: 250, Total: 250, Packets: map[int]int{250: 1}}, response.Plan.ShipNow)
+		require.Equal(t, packer.Shipment{Items: 251, Total: 500, Packets: map[int]int{500: 1}}, response.Plan.Backorder)
+	})
+}
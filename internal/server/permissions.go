@@ -0,0 +1,69 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+)
+
+// authorize reports whether the principal of the request may use the
+// permission on the catalog under the RBAC policy, and responds with 403 when
+// it may not. Every request is authorized when authentication is disabled.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, permission, catalog string) bool {
+	cfg := s.Config()
+	if !cfg.Auth.Enabled {
+		return true
+	}
+
+	principal, ok := auth.PrincipalFrom(r.Context())
+	if !ok {
+		s.authenticationRequiredResponse(w, r)
+		return false
+	}
+	if !cfg.Auth.Policy.Allowed(principal, permission, catalog) {
+		s.catalogNotPermittedResponse(w, r, permission, catalog)
+		return false
+	}
+
+	return true
+}
+
+// permitted reports whether the principal of the request may use the
+// permission on the catalog, without responding.
+func (s *Server) permitted(r *http.Request, permission, catalog string) bool {
+	cfg := s.Config()
+	if !cfg.Auth.Enabled {
+		return true
+	}
+
+	principal, ok := auth.PrincipalFrom(r.Context())
+	return ok && cfg.Auth.Policy.Allowed(principal, permission, catalog)
+}
+
+func (s *Server) showPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	cfg := s.Config()
+	if !cfg.Auth.Enabled {
+		err := s.writeJSON(w, http.StatusOK, envelope{
+			"principal":   nil,
+			"permissions": []auth.Grant{{Catalog: auth.AllCatalogs, Permissions: auth.Scopes}},
+		}, nil)
+		if err != nil {
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	principal, ok := auth.PrincipalFrom(r.Context())
+	if !ok {
+		s.authenticationRequiredResponse(w, r)
+		return
+	}
+
+	err := s.writeJSON(w, http.StatusOK, envelope{
+		"principal":   principal,
+		"permissions": cfg.Auth.Policy.Grants(principal),
+	}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,254 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+)
+
+// newPolicyServer returns a server whose keys are bound by a policy: the
+// merchandiser may edit the eu catalog only and the picker may only calculate.
+func newPolicyServer(t *testing.T) *Server {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Auth.Enabled = true
+	cfg.Auth.Keys = []auth.APIKey{
+		{ID: "merchandiser", Hash: auth.HashKey("merchandiser-secret"), Scopes: []string{auth.ScopeSizesRead, auth.ScopeSizesWrite, auth.ScopePacketsCalculate}},
+		{ID: "picker", Hash: auth.HashKey("picker-secret"), Scopes: []string{auth.ScopeSizesRead, auth.ScopePacketsCalculate}},
+	}
+	cfg.Auth.Policy = &auth.Policy{
+		Roles: map[string]auth.Role{
+			"merchandiser": {Permissions: []string{auth.ScopeSizesRead, auth.ScopeSizesWrite}},
+			"warehouse":    {Permissions: []string{auth.ScopePacketsCalculate}},
+		},
+		Bindings: []auth.Binding{
+			{Role: "merchandiser", Subjects: []string{"api_key:merchandiser"}, Catalogs: []string{"eu"}},
+			{Role: "warehouse", Subjects: []string{"api_key:picker"}},
+		},
+	}
+	require.NoError(t, cfg.Auth.Policy.Validate())
+
+	sizer := packer.NewSizerService(packer.SortedSizes)
+	catalogs := packer.NewCatalogService(sizer)
+	return NewServer(cfg, sizer, packer.NewPacketsService(catalogs, packer.NewProfileService()))
+}
+
+func TestServer_Authorize(t *testing.T) {
+	server := newPolicyServer(t)
+
+	testCases := []struct {
+		name     string
+		key      string
+		method   string
+		body     string
+		handler  http.HandlerFunc
+		wantCode int
+	}{
+		{
+			name:     "201 on creating a bound catalog",
+			key:      "merchandiser-secret",
+			method:   http.MethodPost,
+			body:     `{"id": "eu", "sizes": [10, 20]}`,
+			handler:  server.createCatalogHandler,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "403 on creating another catalog",
+			key:      "merchandiser-secret",
+			method:   http.MethodPost,
+			body:     `{"id": "uk", "sizes": [10, 20]}`,
+			handler:  server.createCatalogHandler,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "403 on editing the default catalog",
+			key:      "merchandiser-secret",
+			method:   http.MethodPut,
+			body:     `{"sizes": [10, 20]}`,
+			handler:  server.putSizesHandler,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "403 on calculating without a role",
+			key:      "merchandiser-secret",
+			method:   http.MethodPost,
+			body:     `{"items": 10}`,
+			handler:  server.getPacksHandler,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "200 on calculating with a role",
+			key:      "picker-secret",
+			method:   http.MethodPost,
+			body:     `{"items": 10}`,
+			handler:  server.getPacksHandler,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "403 on reading sizes without a role",
+			key:      "picker-secret",
+			method:   http.MethodGet,
+			handler:  server.listSizesHandler,
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/api/v1/test", bytes.NewBufferString(tc.body))
+			req.Header.Set(apiKeyHeader, tc.key)
+
+			rr := httptest.NewRecorder()
+			server.authenticate(tc.handler).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code, rr.Body.String())
+			if tc.wantCode == http.StatusForbidden {
+				require.Contains(t, rr.Body.String(), "you are not permitted")
+			}
+		})
+	}
+}
+
+func TestServer_AuthorizeCatalogRoutes(t *testing.T) {
+	server := newPolicyServer(t)
+	ctx := context.Background()
+
+	require.NoError(t, server.PackerSrvc.Catalogs.AddCatalog(ctx, "eu", packer.NewSizerService([]int{10, 20})))
+	require.NoError(t, server.PackerSrvc.Stock.SetStock(ctx, packer.DefaultCatalogID, map[int]int{250: 4}))
+	reservation, err := server.PackerSrvc.Stock.Reserve(ctx, packer.DefaultCatalogID, map[int]int{250: 1}, packer.DefaultReservationTTL)
+	require.NoError(t, err)
+	server.PackerSrvc.Pricing.SetPricing(packer.DefaultCatalogID, packer.Pricing{Currency: "EUR", PackPrices: map[int]int64{250: 500, 500: 900, 1000: 1600, 2000: 3000, 5000: 7000}})
+	quote, err := server.PackerSrvc.CreateQuote(ctx, 10)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		key      string
+		method   string
+		id       string
+		body     string
+		handler  http.HandlerFunc
+		wantCode int
+	}{
+		{name: "403 on reading the guardrail", key: "picker-secret", method: http.MethodGet, id: packer.DefaultCatalogID, handler: server.showGuardrailHandler, wantCode: http.StatusForbidden},
+		{name: "403 on editing the guardrail", key: "merchandiser-secret", method: http.MethodPut, id: "eu", body: `{}`, handler: server.putGuardrailHandler, wantCode: http.StatusForbidden},
+		{name: "403 on reading the pricing", key: "picker-secret", method: http.MethodGet, id: packer.DefaultCatalogID, handler: server.showPricingHandler, wantCode: http.StatusForbidden},
+		{name: "403 on editing the pricing", key: "merchandiser-secret", method: http.MethodPut, id: "eu", body: `{}`, handler: server.putPricingHandler, wantCode: http.StatusForbidden},
+		{name: "403 on reading the stock", key: "picker-secret", method: http.MethodGet, id: packer.DefaultCatalogID, handler: server.showStockHandler, wantCode: http.StatusForbidden},
+		{name: "403 on editing the stock", key: "merchandiser-secret", method: http.MethodPut, id: "eu", body: `{"stock": {}}`, handler: server.putStockHandler, wantCode: http.StatusForbidden},
+		{name: "403 on reading the units", key: "picker-secret", method: http.MethodGet, id: packer.DefaultCatalogID, handler: server.showUnitsHandler, wantCode: http.StatusForbidden},
+		{name: "403 on creating a quote", key: "merchandiser-secret", method: http.MethodPost, body: `{"items": 10}`, handler: server.createQuoteHandler, wantCode: http.StatusForbidden},
+		{name: "403 on reading a quote", key: "merchandiser-secret", method: http.MethodGet, id: quote.ID, handler: server.showQuoteHandler, wantCode: http.StatusForbidden},
+		{name: "200 on reading a quote with a role", key: "picker-secret", method: http.MethodGet, id: quote.ID, handler: server.showQuoteHandler, wantCode: http.StatusOK},
+		{name: "403 on creating a reservation", key: "merchandiser-secret", method: http.MethodPost, body: `{"packets": {"250": 1}}`, handler: server.createReservationHandler, wantCode: http.StatusForbidden},
+		{name: "201 on creating a reservation with a role", key: "picker-secret", method: http.MethodPost, body: `{"packets": {"250": 1}}`, handler: server.createReservationHandler, wantCode: http.StatusCreated},
+		{name: "403 on reading a reservation", key: "merchandiser-secret", method: http.MethodGet, id: reservation.ID, handler: server.showReservationHandler, wantCode: http.StatusForbidden},
+		{name: "403 on confirming a reservation", key: "merchandiser-secret", method: http.MethodPost, id: reservation.ID, handler: server.confirmReservationHandler, wantCode: http.StatusForbidden},
+		{name: "403 on cancelling a reservation", key: "merchandiser-secret", method: http.MethodPost, id: reservation.ID, handler: server.cancelReservationHandler, wantCode: http.StatusForbidden},
+		{name: "403 on packing an order", key: "merchandiser-secret", method: http.MethodPost, body: `{"lines": [{"id": "a", "items": 10}]}`, handler: server.getOrderPacksHandler, wantCode: http.StatusForbidden},
+		{name: "403 on planning a fulfilment", key: "merchandiser-secret", method: http.MethodPost, body: `{"items": 10, "warehouses": [{"id": "w1", "stock": {"250": 1}}]}`, handler: server.getFulfilmentPlanHandler, wantCode: http.StatusForbidden},
+		{name: "403 on reading the bundles", key: "picker-secret", method: http.MethodGet, handler: server.listBundlesHandler, wantCode: http.StatusForbidden},
+		{name: "403 on editing the bundles from one catalog", key: "merchandiser-secret", method: http.MethodPut, body: `{"bundles": []}`, handler: server.putBundlesHandler, wantCode: http.StatusForbidden},
+		{name: "403 on packing bundles", key: "merchandiser-secret", method: http.MethodPost, body: `{"demand": {"a": 1}}`, handler: server.getBundlePacksHandler, wantCode: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/api/v1/test", bytes.NewBufferString(tc.body))
+			req.Header.Set(apiKeyHeader, tc.key)
+			params := httprouter.Params{{Key: "id", Value: tc.id}}
+			req = req.WithContext(context.WithValue(req.Context(), httprouter.ParamsKey, params))
+
+			rr := httptest.NewRecorder()
+			server.authenticate(tc.handler).ServeHTTP(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code, rr.Body.String())
+			if tc.wantCode == http.StatusForbidden {
+				require.Contains(t, rr.Body.String(), "you are not permitted")
+			}
+		})
+	}
+
+	got, err := server.PackerSrvc.Stock.GetReservation(reservation.ID)
+	require.NoError(t, err)
+	require.Equal(t, reservation.Status, got.Status, "a denied request must not settle the reservation")
+}
+
+func TestServer_listCatalogsHandler(t *testing.T) {
+	server := newPolicyServer(t)
+	require.NoError(t, server.PackerSrvc.Catalogs.AddCatalog(context.Background(), "eu", packer.NewSizerService([]int{10, 20})))
+
+	testCases := []struct {
+		name string
+		key  string
+		want []string
+	}{
+		{name: "catalogs bound to the caller", key: "merchandiser-secret", want: []string{"eu"}},
+		{name: "no catalogs without a role", key: "picker-secret", want: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/catalogs", nil)
+			req.Header.Set(apiKeyHeader, tc.key)
+
+			rr := httptest.NewRecorder()
+			server.authenticate(http.HandlerFunc(server.listCatalogsHandler)).ServeHTTP(rr, req)
+			require.Equal(t, http.StatusOK, rr.Code)
+
+			var resp struct {
+				Catalogs []string `json:"catalogs"`
+			}
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+			require.Equal(t, tc.want, resp.Catalogs)
+		})
+	}
+}
+
+func TestServer_showPermissionsHandler(t *testing.T) {
+	server := newPolicyServer(t)
+
+	t.Run("200 with the grants of the caller", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me/permissions", nil)
+		req.Header.Set(apiKeyHeader, "merchandiser-secret")
+
+		rr := httptest.NewRecorder()
+		server.authenticate(http.HandlerFunc(server.showPermissionsHandler)).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp struct {
+			Principal   auth.Principal `json:"principal"`
+			Permissions []auth.Grant   `json:"permissions"`
+		}
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+		require.Equal(t, "merchandiser", resp.Principal.ID)
+		require.Equal(t, []auth.Grant{{Catalog: "eu", Permissions: []string{auth.ScopeSizesRead, auth.ScopeSizesWrite}}}, resp.Permissions)
+	})
+
+	t.Run("401 without credentials", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me/permissions", nil)
+
+		rr := httptest.NewRecorder()
+		server.authenticate(http.HandlerFunc(server.showPermissionsHandler)).ServeHTTP(rr, req)
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("200 with every permission when authentication is disabled", func(t *testing.T) {
+		server := NewServer(config.Default(), nil, nil)
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me/permissions", nil)
+
+		rr := httptest.NewRecorder()
+		server.showPermissionsHandler(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Contains(t, rr.Body.String(), `"catalog": "*"`)
+	})
+}
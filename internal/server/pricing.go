@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+)
+
+func (s *Server) showPricingHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	if !s.authorize(w, r, auth.ScopeSizesRead, id) {
+		return
+	}
+
+	pricing, err := s.PackerSrvc.Pricing.GetPricing(id)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"pricing": pricing}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) putPricingHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	if !s.authorize(w, r, auth.ScopeAdmin, id) {
+		return
+	}
+
+	_, err = s.PackerSrvc.Catalogs.GetCatalog(id)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	var input packer.Pricing
+
+	err = s.readJSON(w, r, &input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	s.validatePricingOnValue(v, input)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	s.PackerSrvc.Pricing.SetPricing(id, input)
+	pricing, err := s.PackerSrvc.Pricing.GetPricing(id)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"pricing": pricing}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
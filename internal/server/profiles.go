@@ -0,0 +1,126 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+)
+
+func (s *Server) listProfilesHandler(w http.ResponseWriter, r *http.Request) {
+	err := s.writeJSON(w, http.StatusOK, envelope{"profiles": s.PackerSrvc.Profiles.ListProfiles()}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) showProfileHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	profile, err := s.PackerSrvc.Profiles.GetProfile(id)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"profile": profile}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) createProfileHandler(w http.ResponseWriter, r *http.Request) {
+	var input packer.Profile
+
+	err := s.readJSON(w, r, &input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	s.validateProfileOnValue(v, input)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	profile, err := s.PackerSrvc.Profiles.CreateProfile(r.Context(), input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"profile": profile}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) updateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		AllowedSizes        []int   `json:"allowed_sizes"`
+		Rounding            string  `json:"rounding"`
+		MaxOvershootPercent float64 `json:"max_overshoot_percent"`
+	}
+
+	err = s.readJSON(w, r, &input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	profile := packer.Profile{
+		ID:                  id,
+		AllowedSizes:        input.AllowedSizes,
+		Rounding:            input.Rounding,
+		MaxOvershootPercent: input.MaxOvershootPercent,
+	}
+
+	v := validator.New()
+	s.validateProfileOnValue(v, profile)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	profile, err = s.PackerSrvc.Profiles.UpdateProfile(r.Context(), profile)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"profile": profile}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) deleteProfileHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	err = s.PackerSrvc.Profiles.DeleteProfile(r.Context(), id)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"message": "profile successfully deleted"}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+)
+
+func newProfileRequest(t *testing.T, method, id, body string) *http.Request {
+	req, err := http.NewRequest(method, "/api/v1/profiles/"+id, bytes.NewBufferString(body))
+	require.NoError(t, err)
+	params := httprouter.Params{{Key: "id", Value: id}}
+	return req.WithContext(context.WithValue(req.Context(), httprouter.ParamsKey, params))
+}
+
+func TestProfilesHandlers(t *testing.T) {
+	newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
+	newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+	server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+
+	testCases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		request  *http.Request
+		wantCode int
+	}{
+		{
+			name:     "201 on POST",
+			handler:  server.createProfileHandler,
+			request:  newProfileRequest(t, http.MethodPost, "", `{"id": "acme", "allowed_sizes": [500, 1000], "rounding": "up"}`),
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "400 on POST - duplicated",
+			handler:  server.createProfileHandler,
+			request:  newProfileRequest(t, http.MethodPost, "", `{"id": "acme"}`),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "422 on POST - invalid profile",
+			handler:  server.createProfileHandler,
+			request:  newProfileRequest(t, http.MethodPost, "", `{"id": "a b", "allowed_sizes": [500, 500], "rounding": "sideways"}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "200 on GET",
+			handler:  server.showProfileHandler,
+			request:  newProfileRequest(t, http.MethodGet, "acme", ""),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "200 on PUT",
+			handler:  server.updateProfileHandler,
+			request:  newProfileRequest(t, http.MethodPut, "acme", `{"rounding": "down"}`),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "404 on PUT - unknown",
+			handler:  server.updateProfileHandler,
+			request:  newProfileRequest(t, http.MethodPut, "globex", `{"rounding": "down"}`),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "200 on GET - list",
+			handler:  server.listProfilesHandler,
+			request:  newProfileRequest(t, http.MethodGet, "", ""),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "200 on DELETE",
+			handler:  server.deleteProfileHandler,
+			request:  newProfileRequest(t, http.MethodDelete, "acme", ""),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "404 on GET - deleted",
+			handler:  server.showProfileHandler,
+			request:  newProfileRequest(t, http.MethodGet, "acme", ""),
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tc.handler(recorder, tc.request)
+			require.Equal(t, tc.wantCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+)
+
+func (s *Server) createQuoteHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Items     int    `json:"items"`
+		Catalog   string `json:"catalog"`
+		ProfileID string `json:"profile_id"`
+	}
+
+	err := s.readJSON(w, r, &input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	s.validateItemsOnValue(v, input.Items)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if input.Catalog == "" {
+		input.Catalog = packer.DefaultCatalogID
+	}
+	if !s.authorize(w, r, auth.ScopePacketsCalculate, input.Catalog) {
+		return
+	}
+
+	opts := []packer.PacketsOption{packer.WithCatalog(input.Catalog)}
+	if input.ProfileID != "" {
+		opts = append(opts, packer.WithProfile(input.ProfileID))
+	}
+
+	quote, err := s.PackerSrvc.CreateQuote(r.Context(), input.Items, opts...)
+	if err != nil {
+		s.packingErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/api/v1/quotes/%s", quote.ID))
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"quote": quote}, headers)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) showQuoteHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	quote, err := s.PackerSrvc.Quotes.GetQuote(id)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+	if !s.authorize(w, r, auth.ScopePacketsCalculate, quote.CatalogID) {
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{
+		"quote":   quote,
+		"expired": quote.Expired(s.PackerSrvc.Quotes.Now()),
+	}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
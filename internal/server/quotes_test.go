@@ -0,0 +1,128 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotesHandlers(t *testing.T) {
+	newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
+	newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+	server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+
+	newRequest := func(method, path, id, body string) *http.Request {
+		req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		params := httprouter.Params{{Key: "id", Value: id}}
+		return req.WithContext(context.WithValue(req.Context(), httprouter.ParamsKey, params))
+	}
+
+	pricing := `{"currency": "EUR", "pack_prices": {"250": 500, "500": 900, "1000": 1600, "2000": 3000, "5000": 7000}, "tiers": [{"min_items": 1000, "discount_percent": 5}]}`
+
+	testCases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		request  *http.Request
+		wantCode int
+	}{
+		{
+			name:     "404 on GET pricing - not set",
+			handler:  server.showPricingHandler,
+			request:  newRequest(http.MethodGet, "/api/v1/catalogs/default/pricing", packer.DefaultCatalogID, ""),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "422 on POST quote - no pricing",
+			handler:  server.createQuoteHandler,
+			request:  newRequest(http.MethodPost, "/api/v1/quotes", "", `{"items": 501}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "200 on PUT pricing",
+			handler:  server.putPricingHandler,
+			request:  newRequest(http.MethodPut, "/api/v1/catalogs/default/pricing", packer.DefaultCatalogID, pricing),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "422 on PUT pricing - price too large",
+			handler:  server.putPricingHandler,
+			request:  newRequest(http.MethodPut, "/api/v1/catalogs/default/pricing", packer.DefaultCatalogID, `{"currency": "EUR", "pack_prices": {"250": 1000000001}}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 on PUT pricing - invalid currency",
+			handler:  server.putPricingHandler,
+			request:  newRequest(http.MethodPut, "/api/v1/catalogs/default/pricing", packer.DefaultCatalogID, `{"currency": "euro", "pack_prices": {"250": 500}}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "404 on PUT pricing - unknown catalog",
+			handler:  server.putPricingHandler,
+			request:  newRequest(http.MethodPut, "/api/v1/catalogs/unknown/pricing", "unknown", pricing),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "200 on GET pricing",
+			handler:  server.showPricingHandler,
+			request:  newRequest(http.MethodGet, "/api/v1/catalogs/default/pricing", packer.DefaultCatalogID, ""),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "422 on POST quote - invalid items",
+			handler:  server.createQuoteHandler,
+			request:  newRequest(http.MethodPost, "/api/v1/quotes", "", `{"items": 0}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "404 on GET quote - unknown",
+			handler:  server.showQuoteHandler,
+			request:  newRequest(http.MethodGet, "/api/v1/quotes/unknown", "unknown", ""),
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tc.handler(recorder, tc.request)
+			require.Equal(t, tc.wantCode, recorder.Code)
+		})
+	}
+
+	t.Run("201 on POST quote and 200 on GET quote", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		server.createQuoteHandler(recorder, newRequest(http.MethodPost, "/api/v1/quotes", "", `{"items": 1200}`))
+		require.Equal(t, http.StatusCreated, recorder.Code)
+
+		var created struct {
+			Quote packer.Quote `json:"quote"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &created))
+		require.Equal(t, "/api/v1/quotes/"+created.Quote.ID, recorder.Header().Get("Location"))
+		require.Equal(t, "EUR", created.Quote.Currency)
+		require.Equal(t, int64(1995), created.Quote.Total)
+
+		recorder = httptest.NewRecorder()
+		server.showQuoteHandler(recorder, newRequest(http.MethodGet, "/api/v1/quotes/"+created.Quote.ID, created.Quote.ID, ""))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var shown struct {
+			Quote   packer.Quote `json:"quote"`
+			Expired bool         `json:"expired"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &shown))
+		require.Equal(t, created.Quote.ID, shown.Quote.ID)
+		require.Equal(t, created.Quote.Total, shown.Quote.Total)
+		require.False(t, shown.Expired)
+	})
+}
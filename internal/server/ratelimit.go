@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/ratelimit"
+	"golang.org/x/exp/slog"
+)
+
+// rateLimits holds the store of the rate limits along with the limits it was
+// created with, so that reloaded limits start afresh.
+type rateLimits struct {
+	mu     sync.Mutex
+	config config.Limiter
+	store  ratelimit.Store
+}
+
+// newRateLimits is a constructor of the rateLimits.
+func newRateLimits() *rateLimits {
+	return &rateLimits{}
+}
+
+// take takes the request from the limit of its client: the API key when keyID
+// is set, the client IP seen through the trusted proxies otherwise.
+func (l *rateLimits) take(ctx context.Context, cfg config.Limiter, r *http.Request, proxies []netip.Prefix, keyID string, now time.Time) (ratelimit.Decision, error) {
+	l.mu.Lock()
+	l.configure(cfg)
+	store := l.store
+	l.mu.Unlock()
+
+	limit := cfg.Rate
+	client := "key:" + keyID
+	if keyID != "" {
+		if keyRate, found := cfg.Keys[keyID]; found {
+			limit = keyRate
+		}
+	} else {
+		ip, err := clientIP(r, proxies)
+		if err != nil {
+			return ratelimit.Decision{}, err
+		}
+		client = "ip:" + ip.String()
+	}
+
+	// Limited routes have limits of their own, apart from the rest of the API.
+	for _, route := range cfg.Routes {
+		if route.Match(r.Method, r.URL.Path) {
+			limit = route.Rate
+			client += " " + route.Method + " " + route.Path
+			break
+		}
+	}
+
+	return store.Take(ctx, client, ratelimit.Limit(limit), now)
+}
+
+// configure starts the limits afresh in a new store when they changed. The
+// caller must hold the lock.
+func (l *rateLimits) configure(cfg config.Limiter) {
+	if l.store != nil && reflect.DeepEqual(cfg, l.config) {
+		return
+	}
+
+	if l.store != nil {
+		l.store.Close()
+	}
+	l.config, l.store = cfg, newLimiterStore(cfg.Backend)
+}
+
+// parseProxies returns the prefixes of the trusted proxies.
+func parseProxies(proxies []string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, proxy := range proxies {
+		// Validated along with the configuration.
+		if prefix, err := config.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// clientAddr returns the IP address of the client of the request, or its
+// remote address when that is not one.
+func clientAddr(r *http.Request, proxies []netip.Prefix) string {
+	ip, err := clientIP(r, proxies)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip.String()
+}
+
+// clientIP returns the IP address of the client of the request. When the
+// request comes through trusted proxies, it is the last address of the
+// X-Forwarded-For header that is not a trusted proxy.
+func clientIP(r *http.Request, proxies []netip.Prefix) (netip.Addr, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	ip = ip.Unmap()
+	if !trusted(ip, proxies) {
+		return ip, nil
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			// Addresses left of a malformed one cannot be trusted either.
+			return ip, nil
+		}
+		ip = hop.Unmap()
+		if !trusted(ip, proxies) {
+			return ip, nil
+		}
+	}
+
+	return ip, nil
+}
+
+// trusted reports whether the address is of a trusted proxy.
+func trusted(ip netip.Addr, proxies []netip.Prefix) bool {
+	for _, prefix := range proxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// newLimiterStore returns the store of the backend. The resp store falls
+// back on memory while its server fails.
+func newLimiterStore(backend config.LimiterBackend) ratelimit.Store {
+	if backend.Kind == config.BackendRESP {
+		shared := ratelimit.NewRESPStore(backend.Address, backend.Password, backend.Timeout)
+		return ratelimit.NewFallbackStore(shared, ratelimit.NewMemoryStore(), limiterBackendCooldown)
+	}
+	return ratelimit.NewMemoryStore()
+}
+
+// evictIdle drops the limits of the store unused for longer than the idle
+// timeout and returns how many it dropped. Stores that expire limits on their
+// own are left alone.
+func (l *rateLimits) evictIdle(now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	evicter, ok := l.store.(interface {
+		EvictIdle(now time.Time, idle time.Duration) int
+	})
+	if !ok {
+		return 0
+	}
+
+	return evicter.EvictIdle(now, l.config.IdleTimeout)
+}
+
+// evictIdleClients drops the rate limiters of idle clients every interval
+// until the context is done.
+func (s *Server) evictIdleClients(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if evicted := s.limits.evictIdle(now); evicted > 0 {
+				slog.DebugContext(ctx, "evicted idle rate limiters",
+					slog.Int("evicted", evicted),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/ratelimit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_RateLimit(t *testing.T) {
+	cfg := config.Default()
+	cfg.Limiter.Rate = config.Rate{RPS: 0.001, Burst: 2}
+	cfg.Limiter.TrustedProxies = []string{"10.0.0.0/8"}
+	cfg.Limiter.Routes = []config.RouteLimit{{Method: http.MethodPost, Path: "/api/v1/packets*", Rate: config.Rate{RPS: 0.001, Burst: 1}}}
+	cfg.Limiter.Keys = map[string]config.Rate{"erp": {RPS: 0.001, Burst: 3}}
+	cfg.Auth.Enabled = true
+	cfg.Auth.Keys = []auth.APIKey{{ID: "erp", Hash: auth.HashKey("erp-secret"), Scopes: []string{auth.ScopeAdmin}}}
+	require.NoError(t, cfg.Validate())
+	server := NewServer(cfg, nil, nil)
+
+	handler := server.rateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	do := func(method, path, remoteAddr, forwardedFor, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		if key != "" {
+			req.Header.Set(apiKeyHeader, key)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("headers and retry after", func(t *testing.T) {
+		rr := do(http.MethodGet, "/api/v1/sizes", "192.0.2.1:4000", "", "")
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		require.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+		require.Equal(t, "1", rr.Header().Get("RateLimit-Remaining"))
+
+		rr = do(http.MethodGet, "/api/v1/sizes", "192.0.2.1:4001", "", "")
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		require.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+
+		rr = do(http.MethodGet, "/api/v1/sizes", "192.0.2.1:4002", "", "")
+		require.Equal(t, http.StatusTooManyRequests, rr.Code)
+		require.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+		require.NotEmpty(t, rr.Header().Get("Retry-After"))
+		require.NotEqual(t, "0", rr.Header().Get("Retry-After"))
+	})
+
+	t.Run("forwarded for is trusted from proxies only", func(t *testing.T) {
+		// Behind the proxy, the spoofed leftmost address is skipped for the
+		// address the proxy saw.
+		require.Equal(t, http.StatusNoContent, do(http.MethodGet, "/api/v1/sizes", "10.0.0.5:80", "203.0.113.9, 198.51.100.7", "").Code)
+		require.Equal(t, http.StatusNoContent, do(http.MethodGet, "/api/v1/sizes", "10.0.0.6:80", "203.0.113.10, 198.51.100.7, 10.0.0.9", "").Code)
+		require.Equal(t, http.StatusTooManyRequests, do(http.MethodGet, "/api/v1/sizes", "10.0.0.5:80", "198.51.100.7", "").Code)
+
+		// Not from a proxy, so the header is ignored.
+		require.Equal(t, http.StatusNoContent, do(http.MethodGet, "/api/v1/sizes", "198.51.100.8:80", "192.0.2.200", "").Code)
+	})
+
+	t.Run("route limits have buckets of their own", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, do(http.MethodPost, "/api/v1/packets/plan", "192.0.2.50:80", "", "").Code)
+		rr := do(http.MethodPost, "/api/v1/packets", "192.0.2.50:80", "", "")
+		require.Equal(t, http.StatusTooManyRequests, rr.Code)
+		require.Equal(t, "1", rr.Header().Get("RateLimit-Limit"))
+		require.Equal(t, http.StatusNoContent, do(http.MethodGet, "/api/v1/sizes", "192.0.2.50:80", "", "").Code)
+	})
+
+	t.Run("known keys are limited apart from their address", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			rr := do(http.MethodGet, "/api/v1/sizes", "192.0.2.1:80", "", "erp-secret")
+			require.Equal(t, http.StatusNoContent, rr.Code)
+			require.Equal(t, "3", rr.Header().Get("RateLimit-Limit"))
+		}
+		require.Equal(t, http.StatusTooManyRequests, do(http.MethodGet, "/api/v1/sizes", "192.0.2.99:80", "", "erp-secret").Code)
+
+		// Unknown keys count against the address, which is spent.
+		require.Equal(t, http.StatusTooManyRequests, do(http.MethodGet, "/api/v1/sizes", "192.0.2.1:80", "", "made-up").Code)
+	})
+}
+
+func TestServer_RateLimit_UnreachableBackend(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ln.Close()
+
+	cfg := config.Default()
+	cfg.Limiter.Rate = config.Rate{RPS: 0.001, Burst: 1}
+	cfg.Limiter.Backend = config.LimiterBackend{Kind: config.BackendRESP, Address: ln.Addr().String(), Timeout: 50 * time.Millisecond}
+	require.NoError(t, cfg.Validate())
+	server := NewServer(cfg, nil, nil)
+
+	handler := server.rateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	// The replica limits on its own until the backend is back.
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/sizes", nil))
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/sizes", nil))
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+}
+
+func TestRateLimits_EvictIdle(t *testing.T) {
+	limits := newRateLimits()
+	cfg := config.Default().Limiter
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, addr := range []string{"192.0.2.1:80", "192.0.2.2:80"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/sizes", nil)
+		req.RemoteAddr = addr
+		_, err := limits.take(ctx, cfg, req, nil, "", now)
+		require.NoError(t, err)
+	}
+	_, err := limits.take(ctx, cfg, httptest.NewRequest(http.MethodGet, "/api/v1/sizes", nil), nil, "ops", now.Add(cfg.IdleTimeout))
+	require.NoError(t, err)
+
+	require.Equal(t, 0, limits.evictIdle(now.Add(cfg.IdleTimeout)))
+	require.Equal(t, 2, limits.evictIdle(now.Add(cfg.IdleTimeout+time.Second)))
+	require.Equal(t, 1, limits.store.(*ratelimit.MemoryStore).Len())
+
+	// Reloaded limits start afresh.
+	cfg.Burst++
+	_, err = limits.take(ctx, cfg, httptest.NewRequest(http.MethodGet, "/api/v1/sizes", nil), nil, "", now)
+	require.NoError(t, err)
+	require.Equal(t, 1, limits.store.(*ratelimit.MemoryStore).Len())
+}
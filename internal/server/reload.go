@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"expvar"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"golang.org/x/exp/slog"
+)
+
+// Outcomes of a configuration reload.
+const (
+	reloadSucceeded = "succeeded"
+	reloadFailed    = "failed"
+)
+
+// reloadMetrics counts the configuration reloads by outcome and keeps the
+// outcome, error and time of the last one.
+var reloadMetrics = expvar.NewMap("config_reloads")
+
+// reloadOnHangup reloads the configuration on every SIGHUP until the context is done.
+func (s *Server) reloadOnHangup(ctx context.Context) {
+	if s.LoadConfig == nil {
+		return
+	}
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			slog.InfoContext(ctx, "reloading configuration")
+			// Failures are logged and counted by Reload; the server carries on with
+			// the previous configuration.
+			_ = s.Reload(ctx)
+		}
+	}
+}
+
+// Reload loads the configuration, the catalog seed file and the JWKS again and
+// applies them when all are valid; otherwise the previous ones stay in effect.
+// The port and HTTP timeouts are bound to the listener and apply on restart only.
+func (s *Server) Reload(ctx context.Context) error {
+	cfg, err := s.LoadConfig()
+	var seed map[string][]int
+	if err == nil && cfg.CatalogFile != "" {
+		seed, err = packer.LoadSeed(cfg.CatalogFile)
+	}
+	var tokens *auth.Verifier
+	if err == nil {
+		tokens, err = newVerifier(ctx, cfg)
+	}
+	if err == nil && seed != nil {
+		err = s.PackerSrvc.Catalogs.Reseed(ctx, seed)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "configuration reload failed, keeping the previous configuration",
+			slog.String("error", err.Error()),
+		)
+		recordReload(reloadFailed, err)
+		return err
+	}
+
+	previous := s.Config()
+	if cfg.Port != previous.Port || cfg.HTTP != previous.HTTP {
+		slog.WarnContext(ctx, "port and http timeouts apply on restart only",
+			slog.Int("port", previous.Port),
+		)
+		cfg.Port, cfg.HTTP = previous.Port, previous.HTTP
+	}
+	s.setConfig(cfg, tokens)
+	warnIfOpen(ctx, cfg)
+	if s.LogLevel != nil {
+		level, _ := cfg.Level()
+		s.LogLevel.Set(level)
+	}
+
+	slog.InfoContext(ctx, "reloaded configuration",
+		slog.String("log_level", cfg.LogLevel),
+		slog.Int("catalogs", len(seed)),
+	)
+	recordReload(reloadSucceeded, nil)
+
+	return nil
+}
+
+// recordReload publishes the outcome of a reload in the metrics.
+func recordReload(outcome string, err error) {
+	reloadMetrics.Add(outcome, 1)
+
+	status, message, at := new(expvar.String), new(expvar.String), new(expvar.Int)
+	status.Set(outcome)
+	if err != nil {
+		message.Set(err.Error())
+	}
+	at.Set(time.Now().Unix())
+	reloadMetrics.Set("last_outcome", status)
+	reloadMetrics.Set("last_error", message)
+	reloadMetrics.Set("last_unix", at)
+}
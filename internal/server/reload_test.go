@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+func TestServer_Reload(t *testing.T) {
+	ctx := context.Background()
+	seedFile := filepath.Join(t.TempDir(), "catalogs.yaml")
+	require.NoError(t, os.WriteFile(seedFile, []byte("catalogs:\n  default: [100, 200]\n"), 0o600))
+
+	newSizerSrvc := packer.NewSizerService([]int{250, 500, 1000})
+	newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+	server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+	server.LogLevel = new(slog.LevelVar)
+
+	next := config.Default()
+	next.Port = 9999
+	next.LogLevel = "debug"
+	next.CatalogFile = seedFile
+	next.CORS.AllowedOrigins = []string{"https://shop.example"}
+	var loadErr error
+	server.LoadConfig = func() (config.Config, error) { return next, loadErr }
+
+	require.NoError(t, server.Reload(ctx))
+	require.Equal(t, []int{100, 200}, newSizerSrvc.ListSizes())
+	require.Equal(t, slog.LevelDebug, server.LogLevel.Level())
+	require.Equal(t, config.Default().Port, server.Config().Port, "the port applies on restart only")
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sizes", nil)
+	req.Header.Set("Origin", "https://shop.example")
+	server.enableCORS(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).ServeHTTP(rr, req)
+	require.Equal(t, "https://shop.example", rr.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "Origin", rr.Header().Get("Vary"))
+
+	t.Run("invalid seed keeps the previous configuration", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(seedFile, []byte("catalogs:\n  default: [0]\n"), 0o600))
+		next.LogLevel = "error"
+
+		require.Error(t, server.Reload(ctx))
+		require.Equal(t, []int{100, 200}, newSizerSrvc.ListSizes())
+		require.Equal(t, "debug", server.Config().LogLevel)
+		require.Equal(t, `"failed"`, reloadMetrics.Get("last_outcome").String())
+	})
+
+	t.Run("invalid config keeps the previous configuration", func(t *testing.T) {
+		loadErr = errors.New("port: must be between 1 and 65535")
+
+		require.Error(t, server.Reload(ctx))
+		require.Equal(t, slog.LevelDebug, server.LogLevel.Level())
+	})
+}
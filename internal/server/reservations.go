@@ -0,0 +1,221 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+)
+
+// maxReservationTTL caps how long a reservation may hold stock.
+const maxReservationTTL = 24 * time.Hour
+
+func (s *Server) createReservationHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Catalog    string      `json:"catalog"`
+		Packets    map[int]int `json:"packets"`
+		TTLSeconds int         `json:"ttl_seconds"`
+	}
+
+	err := s.readJSON(w, r, &input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+	if input.Catalog == "" {
+		input.Catalog = packer.DefaultCatalogID
+	}
+	if !s.authorize(w, r, auth.ScopePacketsCalculate, input.Catalog) {
+		return
+	}
+
+	catalog, err := s.PackerSrvc.Catalogs.GetCatalog(input.Catalog)
+	if err != nil {
+		s.packingErrorResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	v.Check(len(input.Packets) > 0, "packets", "packets must hold at least one pack size")
+	for size, count := range input.Packets {
+		v.Check(catalog.Exists(size), "packets", "pack sizes must exist in the catalog")
+		v.Check(count > 0, "packets", "pack counts must be positive numbers")
+	}
+	v.Check(input.TTLSeconds >= 0, "ttl_seconds", "ttl must not be negative")
+	v.Check(input.TTLSeconds <= int(maxReservationTTL/time.Second), "ttl_seconds", "ttl must not be more than 24 hours")
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	ttl := packer.DefaultReservationTTL
+	if input.TTLSeconds > 0 {
+		ttl = time.Duration(input.TTLSeconds) * time.Second
+	}
+
+	reservation, err := s.PackerSrvc.Stock.Reserve(r.Context(), input.Catalog, input.Packets, ttl)
+	if err != nil {
+		s.packingErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusCreated, envelope{"reservation": reservation}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) showReservationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	reservation, err := s.PackerSrvc.Stock.GetReservation(id)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+	if !s.authorize(w, r, auth.ScopePacketsCalculate, reservation.CatalogID) {
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"reservation": reservation}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) confirmReservationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	if !s.authorizeReservation(w, r, id) {
+		return
+	}
+
+	reservation, err := s.PackerSrvc.Stock.ConfirmReservation(r.Context(), id)
+	if err != nil {
+		s.packingErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"reservation": reservation}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) cancelReservationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	if !s.authorizeReservation(w, r, id) {
+		return
+	}
+
+	reservation, err := s.PackerSrvc.Stock.CancelReservation(r.Context(), id)
+	if err != nil {
+		s.packingErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"reservation": reservation}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+// authorizeReservation reports whether the principal of the request may act
+// on the reservation, and responds with 404 when the reservation does not
+// exist or with 403 when its catalog is not permitted.
+func (s *Server) authorizeReservation(w http.ResponseWriter, r *http.Request, id string) bool {
+	reservation, err := s.PackerSrvc.Stock.GetReservation(id)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return false
+	}
+
+	return s.authorize(w, r, auth.ScopePacketsCalculate, reservation.CatalogID)
+}
+
+func (s *Server) showStockHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	if !s.authorize(w, r, auth.ScopeSizesRead, id) {
+		return
+	}
+
+	_, err = s.PackerSrvc.Catalogs.GetCatalog(id)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"stock": s.PackerSrvc.Stock.GetStock(id)}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) putStockHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	if !s.authorize(w, r, auth.ScopeAdmin, id) {
+		return
+	}
+
+	catalog, err := s.PackerSrvc.Catalogs.GetCatalog(id)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Stock map[int]int `json:"stock"`
+	}
+
+	err = s.readJSON(w, r, &input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	for size, count := range input.Stock {
+		v.Check(catalog.Exists(size), "stock", "pack sizes must exist in the catalog")
+		v.Check(count >= 0, "stock", "pack counts must not be negative")
+	}
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = s.PackerSrvc.Stock.SetStock(r.Context(), id, input.Stock)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"stock": s.PackerSrvc.Stock.GetStock(id)}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
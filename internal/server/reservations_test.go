@@ -0,0 +1,125 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReservationsHandlers(t *testing.T) {
+	newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
+	newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+	server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+
+	newRequest := func(method, id, body string) *http.Request {
+		req, err := http.NewRequest(method, "/api/v1/reservations", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		params := httprouter.Params{{Key: "id", Value: id}}
+		return req.WithContext(context.WithValue(req.Context(), httprouter.ParamsKey, params))
+	}
+	do := func(handler http.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler(recorder, req)
+		return recorder
+	}
+
+	recorder := do(server.putStockHandler, newRequest(http.MethodPut, packer.DefaultCatalogID, `{"stock": {"250": 1, "500": 1}}`))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	testCases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		request  *http.Request
+		wantCode int
+	}{
+		{
+			name:     "422 on PUT stock - unknown size",
+			handler:  server.putStockHandler,
+			request:  newRequest(http.MethodPut, packer.DefaultCatalogID, `{"stock": {"300": 1}}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "404 on GET stock - unknown catalog",
+			handler:  server.showStockHandler,
+			request:  newRequest(http.MethodGet, "unknown", ""),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "422 on POST - empty packets",
+			handler:  server.createReservationHandler,
+			request:  newRequest(http.MethodPost, "", `{"packets": {}}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 on POST - ttl too long",
+			handler:  server.createReservationHandler,
+			request:  newRequest(http.MethodPost, "", `{"packets": {"250": 1}, "ttl_seconds": 100000}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 on POST - ttl overflowing a duration",
+			handler:  server.createReservationHandler,
+			request:  newRequest(http.MethodPost, "", `{"packets": {"250": 1}, "ttl_seconds": 9223372036854775807}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 on POST - unknown catalog",
+			handler:  server.createReservationHandler,
+			request:  newRequest(http.MethodPost, "", `{"catalog": "unknown", "packets": {"250": 1}}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "409 on POST - insufficient stock",
+			handler:  server.createReservationHandler,
+			request:  newRequest(http.MethodPost, "", `{"packets": {"250": 2}}`),
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "404 on confirm - unknown",
+			handler:  server.confirmReservationHandler,
+			request:  newRequest(http.MethodPost, "unknown", ""),
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := do(tc.handler, tc.request)
+			require.Equal(t, tc.wantCode, recorder.Code)
+		})
+	}
+
+	t.Run("201 on POST, 200 on confirm and 409 on cancel", func(t *testing.T) {
+		recorder := do(server.createReservationHandler, newRequest(http.MethodPost, "", `{"packets": {"250": 1, "500": 1}, "ttl_seconds": 60}`))
+		require.Equal(t, http.StatusCreated, recorder.Code)
+
+		var response struct {
+			Reservation packer.Reservation `json:"reservation"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		id := response.Reservation.ID
+		require.Equal(t, packer.ReservationHeld, response.Reservation.Status)
+
+		recorder = do(server.showReservationHandler, newRequest(http.MethodGet, id, ""))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = do(server.confirmReservationHandler, newRequest(http.MethodPost, id, ""))
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		require.Equal(t, packer.ReservationConfirmed, response.Reservation.Status)
+
+		recorder = do(server.cancelReservationHandler, newRequest(http.MethodPost, id, ""))
+		require.Equal(t, http.StatusConflict, recorder.Code)
+
+		require.Equal(t, map[int]int{}, newPackerSrvc.Stock.Available(packer.DefaultCatalogID))
+	})
+}
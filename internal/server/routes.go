@@ -1,8 +1,10 @@
 package server
 
 import (
+	"expvar"
 	"net/http"
 
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -12,14 +14,61 @@ func (s *Server) routes() http.Handler {
 	router.NotFound = http.HandlerFunc(s.notFoundResponse)
 	router.MethodNotAllowed = http.HandlerFunc(s.methodNotAllowedResponse)
 
-	router.HandlerFunc(http.MethodGet, "/api/v1/healthcheck", s.healthcheckHandler)
+	handle := func(method, route string, handler http.HandlerFunc) {
+		router.HandlerFunc(method, route, routed(route, handler))
+	}
 
-	router.HandlerFunc(http.MethodGet, "/api/v1/sizes", s.listSizesHandler)
-	router.HandlerFunc(http.MethodPost, "/api/v1/sizes", s.addSizeHandler)
-	router.HandlerFunc(http.MethodPut, "/api/v1/sizes", s.putSizesHandler)
-	router.HandlerFunc(http.MethodDelete, "/api/v1/sizes/:size", s.deleteSizeHandler)
+	handle(http.MethodGet, "/api/v1/healthcheck", s.healthcheckHandler)
 
-	router.HandlerFunc(http.MethodGet, "/api/v1/docs", s.docsHandler)
+	handle(http.MethodGet, "/api/v1/sizes", s.requireScope(auth.ScopeSizesRead, s.listSizesHandler))
+	handle(http.MethodPost, "/api/v1/sizes", s.requireScope(auth.ScopeSizesWrite, s.addSizeHandler))
+	handle(http.MethodPut, "/api/v1/sizes", s.requireScope(auth.ScopeSizesWrite, s.putSizesHandler))
+	handle(http.MethodDelete, "/api/v1/sizes/:size", s.requireScope(auth.ScopeSizesWrite, s.deleteSizeHandler))
+	handle(http.MethodGet, "/api/v1/sizes/export", s.requireScope(auth.ScopeSizesRead, s.exportSizesHandler))
+	handle(http.MethodPost, "/api/v1/sizes/import", s.requireScope(auth.ScopeSizesWrite, s.importSizesHandler))
+	handle(http.MethodGet, "/api/v1/scheduled-changes", s.requireScope(auth.ScopeSizesRead, s.listScheduledChangesHandler))
+	handle(http.MethodDelete, "/api/v1/scheduled-changes/:id", s.requireScope(auth.ScopeSizesWrite, s.cancelScheduledChangeHandler))
 
-	return s.metrics(s.recoverPanic(s.enableCORS(s.rateLimit(router))))
+	handle(http.MethodPost, "/api/v1/packets", s.requireScope(auth.ScopePacketsCalculate, s.getPacksHandler))
+	handle(http.MethodPost, "/api/v1/packets/plan", s.requireScope(auth.ScopePacketsCalculate, s.getContainerPlanHandler))
+
+	handle(http.MethodGet, "/api/v1/profiles", s.requireScope(auth.ScopeSizesRead, s.listProfilesHandler))
+	handle(http.MethodPost, "/api/v1/profiles", s.requireScope(auth.ScopeAdmin, s.createProfileHandler))
+	handle(http.MethodGet, "/api/v1/profiles/:id", s.requireScope(auth.ScopeSizesRead, s.showProfileHandler))
+	handle(http.MethodPut, "/api/v1/profiles/:id", s.requireScope(auth.ScopeAdmin, s.updateProfileHandler))
+	handle(http.MethodDelete, "/api/v1/profiles/:id", s.requireScope(auth.ScopeAdmin, s.deleteProfileHandler))
+
+	handle(http.MethodPost, "/api/v1/orders/packets", s.requireScope(auth.ScopePacketsCalculate, s.getOrderPacksHandler))
+	handle(http.MethodPost, "/api/v1/warehouses/packets", s.requireScope(auth.ScopePacketsCalculate, s.getFulfilmentPlanHandler))
+
+	handle(http.MethodGet, "/api/v1/bundles", s.requireScope(auth.ScopeSizesRead, s.listBundlesHandler))
+	handle(http.MethodPut, "/api/v1/bundles", s.requireScope(auth.ScopeSizesWrite, s.putBundlesHandler))
+	handle(http.MethodPost, "/api/v1/bundles/packets", s.requireScope(auth.ScopePacketsCalculate, s.getBundlePacksHandler))
+
+	handle(http.MethodGet, "/api/v1/catalogs", s.requireScope(auth.ScopeSizesRead, s.listCatalogsHandler))
+	handle(http.MethodPost, "/api/v1/catalogs", s.requireScope(auth.ScopeSizesWrite, s.createCatalogHandler))
+	handle(http.MethodGet, "/api/v1/catalogs/:id/guardrail", s.requireScope(auth.ScopeSizesRead, s.showGuardrailHandler))
+	handle(http.MethodPut, "/api/v1/catalogs/:id/guardrail", s.requireScope(auth.ScopeAdmin, s.putGuardrailHandler))
+	handle(http.MethodGet, "/api/v1/catalogs/:id/units", s.requireScope(auth.ScopeSizesRead, s.showUnitsHandler))
+	handle(http.MethodPut, "/api/v1/catalogs/:id/units", s.requireScope(auth.ScopeSizesWrite, s.putUnitsHandler))
+	handle(http.MethodGet, "/api/v1/catalogs/:id/pricing", s.requireScope(auth.ScopeSizesRead, s.showPricingHandler))
+	handle(http.MethodPut, "/api/v1/catalogs/:id/pricing", s.requireScope(auth.ScopeAdmin, s.putPricingHandler))
+	handle(http.MethodGet, "/api/v1/catalogs/:id/stock", s.requireScope(auth.ScopeSizesRead, s.showStockHandler))
+	handle(http.MethodPut, "/api/v1/catalogs/:id/stock", s.requireScope(auth.ScopeAdmin, s.putStockHandler))
+
+	handle(http.MethodPost, "/api/v1/quotes", s.requireScope(auth.ScopePacketsCalculate, s.createQuoteHandler))
+	handle(http.MethodGet, "/api/v1/quotes/:id", s.requireScope(auth.ScopePacketsCalculate, s.showQuoteHandler))
+
+	handle(http.MethodPost, "/api/v1/reservations", s.requireScope(auth.ScopePacketsCalculate, s.createReservationHandler))
+	handle(http.MethodGet, "/api/v1/reservations/:id", s.requireScope(auth.ScopePacketsCalculate, s.showReservationHandler))
+	handle(http.MethodPost, "/api/v1/reservations/:id/confirm", s.requireScope(auth.ScopePacketsCalculate, s.confirmReservationHandler))
+	handle(http.MethodPost, "/api/v1/reservations/:id/cancel", s.requireScope(auth.ScopePacketsCalculate, s.cancelReservationHandler))
+
+	handle(http.MethodGet, "/api/v1/me/permissions", s.showPermissionsHandler)
+
+	handle(http.MethodGet, "/api/v1/docs", s.docsHandler)
+
+	handle(http.MethodGet, "/debug/vars", s.requireScope(auth.ScopeAdmin, expvar.Handler().ServeHTTP))
+
+	return s.metrics(s.correlate(s.recoverPanic(s.enableCORS(s.rateLimit(s.verifySignature(s.authenticate(router)))))))
 }
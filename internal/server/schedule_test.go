@@ -0,0 +1,134 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledChangesHandlers(t *testing.T) {
+	newSizerSrvc := packer.NewSizerService([]int{250, 500, 1000})
+	newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+	server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+
+	effectiveAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	at := url.QueryEscape(effectiveAt.Format(time.RFC3339))
+
+	newRequest := func(method, target string, params httprouter.Params, body string) *http.Request {
+		req, err := http.NewRequest(method, target, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		return req.WithContext(context.WithValue(req.Context(), httprouter.ParamsKey, params))
+	}
+
+	testCases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		request  *http.Request
+		wantCode int
+	}{
+		{
+			name:     "202 on DELETE size - scheduled",
+			handler:  server.deleteSizeHandler,
+			request:  newRequest(http.MethodDelete, "/api/v1/sizes/250?effective_at="+at, httprouter.Params{{Key: "size", Value: "250"}}, ""),
+			wantCode: http.StatusAccepted,
+		},
+		{
+			name:     "202 on POST size - scheduled",
+			handler:  server.addSizeHandler,
+			request:  newRequest(http.MethodPost, "/api/v1/sizes", nil, fmt.Sprintf(`{"size": 2000, "effective_at": %q}`, effectiveAt.Format(time.RFC3339))),
+			wantCode: http.StatusAccepted,
+		},
+		{
+			name:     "400 on PUT sizes - duplicated sizes scheduled",
+			handler:  server.putSizesHandler,
+			request:  newRequest(http.MethodPut, "/api/v1/sizes", nil, fmt.Sprintf(`{"sizes": [1, 1], "effective_at": %q}`, effectiveAt.Format(time.RFC3339))),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "422 on DELETE size - invalid effective_at",
+			handler:  server.deleteSizeHandler,
+			request:  newRequest(http.MethodDelete, "/api/v1/sizes/250?effective_at=tomorrow", httprouter.Params{{Key: "size", Value: "250"}}, ""),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "200 on GET scheduled",
+			handler:  server.listScheduledChangesHandler,
+			request:  newRequest(http.MethodGet, "/api/v1/scheduled-changes", nil, ""),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "404 on DELETE scheduled - unknown",
+			handler:  server.cancelScheduledChangeHandler,
+			request:  newRequest(http.MethodDelete, "/api/v1/scheduled-changes/unknown", httprouter.Params{{Key: "id", Value: "unknown"}}, ""),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "422 on GET sizes - before history",
+			handler:  server.listSizesHandler,
+			request:  newRequest(http.MethodGet, "/api/v1/sizes?at=2000-01-01T00:00:00Z", nil, ""),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tc.handler(recorder, tc.request)
+			require.Equal(t, tc.wantCode, recorder.Code)
+		})
+	}
+
+	t.Run("200 on GET sizes - as of the effective time", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		server.listSizesHandler(recorder, newRequest(http.MethodGet, "/api/v1/sizes?at="+at, nil, ""))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var response struct {
+			Sizes []int `json:"sizes"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		require.Equal(t, []int{500, 1000, 2000}, response.Sizes)
+		require.Equal(t, []int{250, 500, 1000}, newSizerSrvc.ListSizes())
+	})
+
+	t.Run("200 on POST packets - pinned as of the effective time", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		body := fmt.Sprintf(`{"items": 1, "as_of": %q}`, effectiveAt.Format(time.RFC3339))
+		server.getPacksHandler(recorder, newRequest(http.MethodPost, "/api/v1/packets", nil, body))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var response struct {
+			Packets map[int]int `json:"packets"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		require.Equal(t, map[int]int{500: 1}, response.Packets)
+	})
+
+	t.Run("422 on PUT sizes - too many scheduled", func(t *testing.T) {
+		for {
+			_, err := newSizerSrvc.Schedule(context.Background(), packer.ScheduledChange{Op: packer.ChangePutSizes, Sizes: []int{100}, EffectiveAt: effectiveAt})
+			if err != nil {
+				require.EqualError(t, err, packer.ErrorTooManyScheduled)
+				break
+			}
+		}
+
+		recorder := httptest.NewRecorder()
+		body := fmt.Sprintf(`{"sizes": [100], "effective_at": %q}`, effectiveAt.Format(time.RFC3339))
+		server.putSizesHandler(recorder, newRequest(http.MethodPut, "/api/v1/sizes", nil, body))
+		require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+		require.Contains(t, recorder.Body.String(), packer.ErrorTooManyScheduled)
+	})
+}
@@ -5,11 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/config"
 	"github.com/SkNuwanTissera/gymshark/internal/packer"
 	"golang.org/x/exp/slog"
 )
@@ -17,39 +22,145 @@ import (
 const (
 	version = "1.0.0"
 
-	idleTimeout  = 1 * time.Minute
-	readTimeout  = 10 * time.Second
-	writeTimeout = 30 * time.Second
-
-	gracefulShutdownTimeout = 5 * time.Second
+	sweepInterval           = 30 * time.Second
+	catalogScheduleInterval = 1 * time.Second
+	limiterEvictionInterval = 1 * time.Minute
+	limiterBackendCooldown  = 5 * time.Second
 )
 
 // Server holds params for REST API server configuration.
 type Server struct {
 	SizerSrvc  *packer.SizerService
 	PackerSrvc *packer.PacketsService
+
+	// LoadConfig loads the configuration again on SIGHUP; nil disables reloads.
+	LoadConfig func() (config.Config, error)
+	// LogLevel is the level of the default logger, updated on reloads when set.
+	LogLevel *slog.LevelVar
+
+	current atomic.Pointer[state]
+	limits  *rateLimits
+	// signed holds the signatures accepted across reloads.
+	signed *auth.ReplayCache
+}
+
+// state is the configuration in effect together with the credentials built
+// from it. It is swapped as a whole, so that a request never sees a mix of
+// old and new.
+type state struct {
+	config     config.Config
+	keys       *auth.KeyStore
+	tokens     *auth.Verifier
+	signatures *auth.SignatureVerifier
+	// proxies are the parsed trusted proxies of the limiter.
+	proxies []netip.Prefix
 }
 
 // NewServer constructs Server instance.
-func NewServer(sizerSrvc *packer.SizerService, packerSrvc *packer.PacketsService) *Server {
-	return &Server{
+func NewServer(cfg config.Config, sizerSrvc *packer.SizerService, packerSrvc *packer.PacketsService) *Server {
+	s := &Server{
 		SizerSrvc:  sizerSrvc,
 		PackerSrvc: packerSrvc,
+		limits:     newRateLimits(),
+		signed:     auth.NewReplayCache(),
+	}
+	s.setConfig(cfg, nil)
+
+	return s
+}
+
+// Config returns the configuration in effect.
+func (s *Server) Config() config.Config {
+	return s.current.Load().config
+}
+
+// setConfig puts the configuration, the API keys, signing clients and trusted
+// proxies it holds and the bearer token verifier in effect. A nil verifier
+// turns bearer tokens away, as no signing clients turn signed requests away.
+func (s *Server) setConfig(cfg config.Config, tokens *auth.Verifier) {
+	next := &state{
+		config:  cfg,
+		keys:    auth.NewKeyStore(cfg.Auth.APIKeys()),
+		tokens:  tokens,
+		proxies: parseProxies(cfg.Limiter.TrustedProxies),
+	}
+	if len(cfg.Auth.HMAC.Clients) > 0 {
+		next.signatures = auth.NewSignatureVerifier(cfg.Auth.HMAC.Clients, cfg.Auth.HMAC.Window, s.signed)
+	}
+	s.current.Store(next)
+}
+
+// warnIfOpen warns that every route is open when authentication is disabled.
+func warnIfOpen(ctx context.Context, cfg config.Config) {
+	if !cfg.Auth.Enabled {
+		slog.WarnContext(ctx, "authentication is disabled, anyone can change the catalogs; set AUTH_ENABLED=true to require credentials")
+	}
+}
+
+// newVerifier loads the JWKS of the configuration into a bearer token
+// verifier; it returns nil when no JWKS is configured.
+func newVerifier(ctx context.Context, cfg config.Config) (*auth.Verifier, error) {
+	jwt := cfg.Auth.JWT
+	if jwt.JWKS == "" {
+		return nil, nil
 	}
+
+	keys, err := auth.LoadJWKS(ctx, jwt.JWKS)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.NewVerifier(keys, jwt.Issuer, jwt.Audience, jwt.Leeway, jwt.Scopes), nil
 }
 
 // Serve runs REST API server.
-func (s *Server) Serve(port string) error {
+func (s *Server) Serve() error {
+	cfg := s.Config()
+	tokens, err := newVerifier(context.Background(), cfg)
+	if err != nil {
+		return err
+	}
+	s.setConfig(cfg, tokens)
+	warnIfOpen(context.Background(), cfg)
+
 	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%s", port),
+		Addr:         fmt.Sprintf(":%d", cfg.Port),
 		Handler:      s.routes(),
-		IdleTimeout:  idleTimeout,
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writeTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
 	}
 
 	shutdownError := make(chan error)
 
+	// Release expired stock reservations, prune settled ones and expired
+	// quotes, apply scheduled catalog changes, evict idle rate limiters and
+	// reload the configuration on SIGHUP in the background for as long as the
+	// server runs; the workers are stopped and waited for on every return path.
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+	background.Add(4)
+	go func() {
+		defer background.Done()
+		s.PackerSrvc.Sweep(backgroundCtx, sweepInterval)
+	}()
+	go func() {
+		defer background.Done()
+		s.PackerSrvc.Catalogs.RunScheduler(backgroundCtx, catalogScheduleInterval)
+	}()
+	go func() {
+		defer background.Done()
+		s.evictIdleClients(backgroundCtx, limiterEvictionInterval)
+	}()
+	go func() {
+		defer background.Done()
+		s.reloadOnHangup(backgroundCtx)
+	}()
+	defer func() {
+		stopBackground()
+		background.Wait()
+	}()
+
 	go func() {
 		quit := make(chan os.Signal, 1)
 		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -58,7 +169,7 @@ func (s *Server) Serve(port string) error {
 			slog.Any("signal", qs.String()),
 		)
 
-		ctx, cancel := context.WithTimeout(context.Background(), gracefulShutdownTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), s.Config().HTTP.ShutdownTimeout)
 		defer cancel()
 		shutdownError <- srv.Shutdown(ctx)
 	}()
@@ -71,7 +182,7 @@ func (s *Server) Serve(port string) error {
 	// return a http.ErrServerClosed error. So if we see this error, it is actually a
 	// good thing and an indication that the graceful shutdown has started. So we check
 	// specifically for this, only returning the error if it is NOT http.ErrServerClosed.
-	err := srv.ListenAndServe()
+	err = srv.ListenAndServe()
 	if !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
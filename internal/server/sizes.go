@@ -2,20 +2,57 @@ package server
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
 	"github.com/SkNuwanTissera/gymshark/internal/validator"
 )
 
 func (s *Server) listSizesHandler(w http.ResponseWriter, r *http.Request) {
-	err := s.writeJSON(w, http.StatusOK, envelope{"sizes": s.SizerSrvc.ListSizes()}, nil)
+	if !s.authorize(w, r, auth.ScopeSizesRead, packer.DefaultCatalogID) {
+		return
+	}
+
+	v := validator.New()
+	at := s.readTime(r.URL.Query(), "at", v)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if at.IsZero() {
+		err := s.writeJSON(w, http.StatusOK, envelope{"sizes": s.SizerSrvc.ListSizes()}, nil)
+		if err != nil {
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	sizes, version, err := s.SizerSrvc.CatalogAt(at)
+	if err != nil {
+		s.failedValidationResponse(w, r, map[string]string{"at": err.Error()})
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{
+		"sizes":   sizes,
+		"version": version,
+		"at":      at,
+	}, nil)
 	if err != nil {
 		s.serverErrorResponse(w, r, err)
 	}
 }
 
 func (s *Server) addSizeHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, auth.ScopeSizesWrite, packer.DefaultCatalogID) {
+		return
+	}
+
 	var input struct {
-		Size int `json:"size"`
+		Size        int        `json:"size"`
+		EffectiveAt *time.Time `json:"effective_at"`
 	}
 
 	err := s.readJSON(w, r, &input)
@@ -31,6 +68,15 @@ func (s *Server) addSizeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if input.EffectiveAt != nil && input.EffectiveAt.After(time.Now()) {
+		s.scheduleChange(w, r, packer.ScheduledChange{
+			Op:          packer.ChangeAddSize,
+			Size:        input.Size,
+			EffectiveAt: *input.EffectiveAt,
+		})
+		return
+	}
+
 	sizes, err := s.SizerSrvc.AddSize(r.Context(), input.Size)
 	if err != nil {
 		s.badRequestResponse(w, r, err)
@@ -46,8 +92,13 @@ func (s *Server) addSizeHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) putSizesHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, auth.ScopeSizesWrite, packer.DefaultCatalogID) {
+		return
+	}
+
 	var input struct {
-		Sizes []int `json:"sizes"`
+		Sizes       []int      `json:"sizes"`
+		EffectiveAt *time.Time `json:"effective_at"`
 	}
 
 	err := s.readJSON(w, r, &input)
@@ -65,6 +116,15 @@ func (s *Server) putSizesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if input.EffectiveAt != nil && input.EffectiveAt.After(time.Now()) {
+		s.scheduleChange(w, r, packer.ScheduledChange{
+			Op:          packer.ChangePutSizes,
+			Sizes:       input.Sizes,
+			EffectiveAt: *input.EffectiveAt,
+		})
+		return
+	}
+
 	sizes, err := s.SizerSrvc.PutSizes(r.Context(), input.Sizes)
 	if err != nil {
 		s.badRequestResponse(w, r, err)
@@ -80,6 +140,10 @@ func (s *Server) putSizesHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) deleteSizeHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, auth.ScopeSizesWrite, packer.DefaultCatalogID) {
+		return
+	}
+
 	size, err := s.readSizeParam(r)
 	if err != nil {
 		s.notFoundResponse(w, r)
@@ -88,11 +152,21 @@ func (s *Server) deleteSizeHandler(w http.ResponseWriter, r *http.Request) {
 
 	v := validator.New()
 	s.validateSizeOnValue(v, size)
+	effectiveAt := s.readTime(r.URL.Query(), "effective_at", v)
 	if !v.Valid() {
 		s.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
+	if effectiveAt.After(time.Now()) {
+		s.scheduleChange(w, r, packer.ScheduledChange{
+			Op:          packer.ChangeDeleteSize,
+			Size:        size,
+			EffectiveAt: effectiveAt,
+		})
+		return
+	}
+
 	sizes, err := s.SizerSrvc.DeleteSize(r.Context(), size)
 	if err != nil {
 		s.badRequestResponse(w, r, err)
@@ -106,3 +180,54 @@ func (s *Server) deleteSizeHandler(w http.ResponseWriter, r *http.Request) {
 		s.serverErrorResponse(w, r, err)
 	}
 }
+
+func (s *Server) scheduleChange(w http.ResponseWriter, r *http.Request, change packer.ScheduledChange) {
+	scheduled, err := s.SizerSrvc.Schedule(r.Context(), change)
+	if err != nil {
+		if err.Error() == packer.ErrorTooManyScheduled {
+			s.failedValidationResponse(w, r, map[string]string{"effective_at": err.Error()})
+			return
+		}
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusAccepted, envelope{"scheduled": scheduled}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) listScheduledChangesHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, auth.ScopeSizesRead, packer.DefaultCatalogID) {
+		return
+	}
+
+	err := s.writeJSON(w, http.StatusOK, envelope{"scheduled": s.SizerSrvc.ListScheduled()}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) cancelScheduledChangeHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, auth.ScopeSizesWrite, packer.DefaultCatalogID) {
+		return
+	}
+
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	err = s.SizerSrvc.CancelScheduled(r.Context(), id)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"message": "scheduled change successfully cancelled"}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
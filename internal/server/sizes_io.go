@@ -0,0 +1,120 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+)
+
+// maxImportBytes caps the size of an imported catalog file.
+const maxImportBytes = 1_048_576
+
+// formatContentTypes maps the catalog file formats to their media types.
+var formatContentTypes = map[string]string{
+	packer.FormatCSV:  "text/csv",
+	packer.FormatJSON: "application/json",
+	packer.FormatYAML: "application/yaml",
+}
+
+func (s *Server) exportSizesHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, auth.ScopeSizesRead, packer.DefaultCatalogID) {
+		return
+	}
+
+	v := validator.New()
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = packer.FormatJSON
+	}
+	s.validateFormatOnValue(v, format)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	data, err := packer.ExportSizes(s.SizerSrvc.ListSizes(), format)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", formatContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="sizes.%s"`, format))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(data); err != nil {
+		s.logError(r, err)
+	}
+}
+
+func (s *Server) importSizesHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(w, r, auth.ScopeSizesWrite, packer.DefaultCatalogID) {
+		return
+	}
+
+	qs := r.URL.Query()
+	format := qs.Get("format")
+	if format == "" {
+		format = formatOfContentType(r.Header.Get("Content-Type"))
+	}
+	mode := qs.Get("mode")
+	if mode == "" {
+		mode = packer.ImportMerge
+	}
+
+	v := validator.New()
+	s.validateFormatOnValue(v, format)
+	v.Check(validator.PermittedValue(mode, packer.ImportReplace, packer.ImportMerge), "mode", packer.ErrorUnknownImportMode)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		s.badRequestResponse(w, r, fmt.Errorf("body must not be larger than %d bytes", maxImportBytes))
+		return
+	}
+
+	report, err := s.SizerSrvc.ImportSizes(r.Context(), data, format, mode)
+	if err != nil {
+		if err.Error() != packer.ErrorInvalidRows {
+			s.badRequestResponse(w, r, err)
+			return
+		}
+		s.errorEnvelopeResponse(w, r, http.StatusUnprocessableEntity, envelope{
+			"error":  err.Error(),
+			"report": report,
+		})
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"report": report}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+// formatOfContentType returns the catalog file format of the media type, or an
+// empty string when it is none of them.
+func formatOfContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+
+	switch mediaType {
+	case "text/csv":
+		return packer.FormatCSV
+	case "application/json":
+		return packer.FormatJSON
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return packer.FormatYAML
+	}
+
+	return ""
+}
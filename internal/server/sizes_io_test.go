@@ -0,0 +1,145 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportSizesHandler(t *testing.T) {
+	newSizerSrvc := packer.NewSizerService([]int{250, 500, 1000})
+	newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+	server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+
+	testCases := []struct {
+		name            string
+		target          string
+		wantCode        int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "200 on csv",
+			target:          "/api/v1/sizes/export?format=csv",
+			wantCode:        http.StatusOK,
+			wantContentType: "text/csv",
+			wantBody:        "size\n250\n500\n1000\n",
+		},
+		{
+			name:            "200 on yaml",
+			target:          "/api/v1/sizes/export?format=yaml",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/yaml",
+			wantBody:        "sizes:\n    - 250\n    - 500\n    - 1000\n",
+		},
+		{
+			name:            "200 on json by default",
+			target:          "/api/v1/sizes/export",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/json",
+		},
+		{
+			name:     "422 on unknown format",
+			target:   "/api/v1/sizes/export?format=xml",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tc.target, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			server.exportSizesHandler(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code)
+			if tc.wantContentType != "" {
+				require.Equal(t, tc.wantContentType, rr.Header().Get("Content-Type"))
+			}
+			if tc.wantBody != "" {
+				require.Equal(t, tc.wantBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestImportSizesHandler(t *testing.T) {
+	testCases := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		wantCode    int
+		wantSizes   []int
+	}{
+		{
+			name:      "200 on csv merge",
+			target:    "/api/v1/sizes/import?format=csv",
+			body:      "size\n500\n2000\n",
+			wantCode:  http.StatusOK,
+			wantSizes: []int{250, 500, 1000, 2000},
+		},
+		{
+			name:        "200 on yaml replace by content type",
+			target:      "/api/v1/sizes/import?mode=replace",
+			contentType: "application/x-yaml",
+			body:        "sizes:\n  - 100\n  - 200\n",
+			wantCode:    http.StatusOK,
+			wantSizes:   []int{100, 200},
+		},
+		{
+			name:      "422 on invalid rows",
+			target:    "/api/v1/sizes/import?format=json&mode=replace",
+			body:      `{"sizes": [100, -1]}`,
+			wantCode:  http.StatusUnprocessableEntity,
+			wantSizes: []int{250, 500, 1000},
+		},
+		{
+			name:      "422 on unknown format",
+			target:    "/api/v1/sizes/import",
+			body:      "250",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantSizes: []int{250, 500, 1000},
+		},
+		{
+			name:      "422 on unknown mode",
+			target:    "/api/v1/sizes/import?format=csv&mode=append",
+			body:      "250",
+			wantCode:  http.StatusUnprocessableEntity,
+			wantSizes: []int{250, 500, 1000},
+		},
+		{
+			name:      "400 on malformed file",
+			target:    "/api/v1/sizes/import?format=json",
+			body:      `{"sizes": [`,
+			wantCode:  http.StatusBadRequest,
+			wantSizes: []int{250, 500, 1000},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			newSizerSrvc := packer.NewSizerService([]int{250, 500, 1000})
+			newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+			server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+
+			req, err := http.NewRequest(http.MethodPost, tc.target, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+
+			rr := httptest.NewRecorder()
+			server.importSizesHandler(rr, req)
+
+			require.Equal(t, tc.wantCode, rr.Code, rr.Body.String())
+			require.Equal(t, tc.wantSizes, newSizerSrvc.ListSizes())
+		})
+	}
+}
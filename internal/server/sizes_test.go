@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/SkNuwanTissera/gymshark/internal/config"
 	"github.com/SkNuwanTissera/gymshark/internal/mock"
 	"github.com/SkNuwanTissera/gymshark/internal/packer"
 	"github.com/golang/mock/gomock"
@@ -42,9 +43,9 @@ func TestSizesHandler_listSizes(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			newPackerSrvc := packer.NewPacketsService()
 			newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
-			server := NewServer(newSizerSrvc, newPackerSrvc)
+			newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+			server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
 			recorder := httptest.NewRecorder()
 
 			url := "/v1/tables"
@@ -109,9 +110,9 @@ func TestSizesHandler_addSize(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			newPackerSrvc := packer.NewPacketsService()
 			newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
-			server := NewServer(newSizerSrvc, newPackerSrvc)
+			newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+			server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
 			recorder := httptest.NewRecorder()
 
 			url := "/v1/sizes"
@@ -190,9 +191,9 @@ func TestSizesHandler_putSizeHandler(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			newPackerSrvc := packer.NewPacketsService()
 			newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
-			server := NewServer(newSizerSrvc, newPackerSrvc)
+			newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+			server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
 			recorder := httptest.NewRecorder()
 
 			url := "/v1/sizes"
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+)
+
+func (s *Server) showUnitsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	if !s.authorize(w, r, auth.ScopeSizesRead, id) {
+		return
+	}
+
+	_, err = s.PackerSrvc.Catalogs.GetCatalog(id)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{
+		"units": s.PackerSrvc.Units.GetUnits(id),
+	}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) putUnitsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	if !s.authorize(w, r, auth.ScopeSizesWrite, id) {
+		return
+	}
+
+	_, err = s.PackerSrvc.Catalogs.GetCatalog(id)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Units map[string]float64 `json:"units"`
+	}
+
+	err = s.readJSON(w, r, &input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	s.validateUnitsOnValue(v, input.Units)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	s.PackerSrvc.Units.SetUnits(id, input.Units)
+
+	err = s.writeJSON(w, http.StatusOK, envelope{
+		"units": s.PackerSrvc.Units.GetUnits(id),
+	}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitsHandlers(t *testing.T) {
+	newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
+	newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+	server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+
+	newRequest := func(method, id, body string) *http.Request {
+		req, err := http.NewRequest(method, "/api/v1/catalogs/"+id+"/units", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		params := httprouter.Params{{Key: "id", Value: id}}
+		return req.WithContext(context.WithValue(req.Context(), httprouter.ParamsKey, params))
+	}
+
+	testCases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		request  *http.Request
+		wantCode int
+	}{
+		{
+			name:     "200 on PUT",
+			handler:  server.putUnitsHandler,
+			request:  newRequest(http.MethodPut, packer.DefaultCatalogID, `{"units": {"dozen": 12, "kg": 2.5}}`),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "422 on PUT - zero factor",
+			handler:  server.putUnitsHandler,
+			request:  newRequest(http.MethodPut, packer.DefaultCatalogID, `{"units": {"dozen": 0}}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 on PUT - tiny factor",
+			handler:  server.putUnitsHandler,
+			request:  newRequest(http.MethodPut, packer.DefaultCatalogID, `{"units": {"dust": 1e-300}}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 on PUT - huge factor",
+			handler:  server.putUnitsHandler,
+			request:  newRequest(http.MethodPut, packer.DefaultCatalogID, `{"units": {"planet": 1e300}}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 on PUT - base unit factor",
+			handler:  server.putUnitsHandler,
+			request:  newRequest(http.MethodPut, packer.DefaultCatalogID, `{"units": {"item": 2}}`),
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "404 on PUT - unknown catalog",
+			handler:  server.putUnitsHandler,
+			request:  newRequest(http.MethodPut, "unknown", `{"units": {"dozen": 12}}`),
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "200 on GET",
+			handler:  server.showUnitsHandler,
+			request:  newRequest(http.MethodGet, packer.DefaultCatalogID, ""),
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			tc.handler(recorder, tc.request)
+			require.Equal(t, tc.wantCode, recorder.Code)
+		})
+	}
+
+	packetsTestCases := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "200 on POST packets - dozens",
+			body:     `{"quantity": {"quantity": 21, "unit": "dozen"}}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "422 on POST packets - non integral",
+			body:     `{"quantity": {"quantity": 0.3, "unit": "kg"}}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 on POST packets - too many items",
+			body:     `{"quantity": {"quantity": 1e30, "unit": "dozen"}}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 on POST packets - unknown unit",
+			body:     `{"quantity": {"quantity": 1, "unit": "case"}}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 on POST packets - items and quantity",
+			body:     `{"items": 10, "quantity": {"quantity": 1, "unit": "dozen"}}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for i := range packetsTestCases {
+		tc := packetsTestCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/packets", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			server.getPacksHandler(recorder, req)
+			require.Equal(t, tc.wantCode, recorder.Code)
+		})
+	}
+
+	t.Run("200 on POST packets - both units reported", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/api/v1/packets", bytes.NewBufferString(`{"quantity": {"quantity": 21, "unit": "dozen"}}`))
+		require.NoError(t, err)
+
+		server.getPacksHandler(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var response struct {
+			Packets     map[int]int `json:"packets"`
+			Items       int         `json:"items"`
+			Total       int         `json:"total"`
+			TotalInUnit float64     `json:"total_in_unit"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		require.Equal(t, map[int]int{500: 1}, response.Packets)
+		require.Equal(t, 252, response.Items)
+		require.Equal(t, 500, response.Total)
+	})
+}
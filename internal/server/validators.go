@@ -1,6 +1,11 @@
 package server
 
-import "github.com/SkNuwanTissera/gymshark/internal/validator"
+import (
+	"fmt"
+
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+)
 
 func (s *Server) validateSizeOnValue(v *validator.Validator, size int) {
 	v.Check(size > 0, "size", "size must be positive number")
@@ -8,4 +13,160 @@ func (s *Server) validateSizeOnValue(v *validator.Validator, size int) {
 
 func (s *Server) validateItemsOnValue(v *validator.Validator, size int) {
 	v.Check(size > 0, "items", "items must be positive number")
+	v.Check(size <= packer.MaxItems, "items", fmt.Sprintf("items must not be more than %d", packer.MaxItems))
+}
+
+func (s *Server) validateQuantityOnValue(v *validator.Validator, catalogID string, quantity packer.Quantity) {
+	if quantity.Quantity <= 0 {
+		v.AddError("quantity", "quantity must be positive number")
+		return
+	}
+	factor, err := s.PackerSrvc.Units.Factor(catalogID, quantity.Unit)
+	if err != nil {
+		v.AddError("quantity", "unit does not exist in the catalog")
+		return
+	}
+	if quantity.Quantity*factor > packer.MaxItems {
+		v.AddError("quantity", fmt.Sprintf("%g %s converts to more than %d items", quantity.Quantity, quantity.Unit, packer.MaxItems))
+		return
+	}
+	v.Check(validator.Integral(quantity.Quantity*factor), "quantity",
+		fmt.Sprintf("%g %s does not convert to a whole number of items", quantity.Quantity, quantity.Unit))
+}
+
+func (s *Server) validateUnitsOnValue(v *validator.Validator, units map[string]float64) {
+	for unit, factor := range units {
+		v.Check(validator.Matches(unit, validator.IDRX), "units", "units must be 1-64 letters, digits, dashes or underscores")
+		v.Check(factor >= packer.MinUnitFactor && factor <= packer.MaxUnitFactor, "units",
+			fmt.Sprintf("factors must be between %g and %g", packer.MinUnitFactor, float64(packer.MaxUnitFactor)))
+		v.Check(unit != packer.BaseUnit || factor == 1, "units", fmt.Sprintf("factor of the %s unit must be 1", packer.BaseUnit))
+	}
+}
+
+func (s *Server) validateCapacityOnValue(v *validator.Validator, key string, capacity packer.Capacity) {
+	v.Check(capacity.MaxUnits >= 0 && capacity.MaxVolume >= 0 && capacity.MaxWeight >= 0,
+		key, "capacity must not be negative")
+	v.Check(capacity.MaxUnits > 0 || capacity.MaxVolume > 0 || capacity.MaxWeight > 0,
+		key, "capacity must limit units, volume or weight")
+}
+
+func (s *Server) validateHierarchyOnValue(v *validator.Validator, spec packer.HierarchySpec) {
+	for key, container := range map[string]packer.ContainerSpec{"carton": spec.Carton, "pallet": spec.Pallet} {
+		s.validateCapacityOnValue(v, key, container.Capacity)
+		// A zero volume stands for the volume of the contents.
+		v.Check(container.Volume >= 0 && container.Weight >= 0, key, "volume and weight must not be negative")
+	}
+	for size, pack := range spec.Packs {
+		v.Check(size > 0, "packs", "sizes must be positive numbers")
+		v.Check(pack.Volume > 0 && pack.Weight > 0, "packs", "pack volume and weight must be positive numbers")
+	}
+}
+
+func (s *Server) validateConstraintsOnValue(v *validator.Validator, constraints packer.Constraints) {
+	v.Check(constraints.MaxTotalPacks >= 0, "max_total_packs", "max total packs must not be negative")
+	for size, quantity := range constraints.MaxPerSize {
+		v.Check(size > 0, "max_per_size", "sizes must be positive numbers")
+		v.Check(quantity >= 0, "max_per_size", "quantities must not be negative")
+	}
+	for size, quantity := range constraints.MinPerSize {
+		v.Check(size > 0, "min_per_size", "sizes must be positive numbers")
+		v.Check(quantity >= 0, "min_per_size", "quantities must not be negative")
+	}
+	for _, size := range constraints.ExcludedSizes {
+		v.Check(size > 0, "excluded_sizes", "sizes must be positive numbers")
+	}
+}
+
+func (s *Server) validateProfileOnValue(v *validator.Validator, profile packer.Profile) {
+	v.Check(validator.Matches(profile.ID, validator.IDRX), "id", "id must be 1-64 letters, digits, dashes or underscores")
+	for _, size := range profile.AllowedSizes {
+		v.Check(size > 0, "allowed_sizes", "sizes must be positive numbers")
+	}
+	v.Check(validator.Unique(profile.AllowedSizes), "allowed_sizes", "sizes must not contain duplicates")
+	v.Check(profile.Rounding == "" || validator.PermittedValue(profile.Rounding, packer.RoundingUp, packer.RoundingDown, packer.RoundingNearest),
+		"rounding", "rounding must be one of up, down or nearest")
+	v.Check(profile.MaxOvershootPercent >= 0, "max_overshoot_percent", "max overshoot percent must not be negative")
+}
+
+func (s *Server) validateGuardrailOnValue(v *validator.Validator, guardrail packer.Guardrail) {
+	v.Check(guardrail.MaxOvershoot >= 0, "max_overshoot", "max overshoot must not be negative")
+	v.Check(guardrail.MaxOvershootPercent >= 0, "max_overshoot_percent", "max overshoot percent must not be negative")
+}
+
+func (s *Server) validatePricingOnValue(v *validator.Validator, pricing packer.Pricing) {
+	v.Check(validator.Matches(pricing.Currency, validator.CurrencyRX), "currency", "currency must be a 3-letter ISO 4217 code")
+	v.Check(len(pricing.PackPrices) > 0, "pack_prices", "pack prices must be more than 0 in quantity")
+	for size, price := range pricing.PackPrices {
+		v.Check(size > 0, "pack_prices", "sizes must be positive numbers")
+		v.Check(price >= 0, "pack_prices", "prices must not be negative")
+		v.Check(price <= packer.MaxPackPrice, "pack_prices", fmt.Sprintf("prices must not be more than %d", packer.MaxPackPrice))
+	}
+	minItems := make([]int, 0, len(pricing.Tiers))
+	for i, tier := range pricing.Tiers {
+		key := fmt.Sprintf("tiers[%d]", i)
+		v.Check(tier.MinItems >= 0, key, "min items must not be negative")
+		v.Check(tier.DiscountPercent >= 0 && tier.DiscountPercent <= 100, key, "discount percent must be between 0 and 100")
+		minItems = append(minItems, tier.MinItems)
+	}
+	v.Check(validator.Unique(minItems), "tiers", "tiers must not repeat min items")
+}
+
+func (s *Server) validateOrderLinesOnValue(v *validator.Validator, lines []packer.OrderLine) {
+	v.Check(len(lines) > 0, "lines", "order must contain at least one line")
+	v.Check(len(lines) <= maxOrderLines, "lines", fmt.Sprintf("order must not contain more than %d lines", maxOrderLines))
+	ids := make([]string, 0, len(lines))
+	for i, line := range lines {
+		key := fmt.Sprintf("lines[%d]", i)
+		v.Check(validator.Matches(line.ID, validator.IDRX), key, "id must be 1-64 letters, digits, dashes or underscores")
+		v.Check(line.Catalog == "" || validator.Matches(line.Catalog, validator.IDRX), key, "catalog must be 1-64 letters, digits, dashes or underscores")
+		v.Check(line.Items > 0, key, "items must be positive number")
+		v.Check(line.Items <= packer.MaxItems, key, fmt.Sprintf("items must not be more than %d", packer.MaxItems))
+		ids = append(ids, line.ID)
+	}
+	v.Check(validator.Unique(ids), "lines", "line ids must be unique")
+}
+
+func (s *Server) validateWarehousesOnValue(v *validator.Validator, warehouses []packer.Warehouse) {
+	v.Check(len(warehouses) > 0, "warehouses", "warehouses must be more than 0 in quantity")
+	v.Check(len(warehouses) <= packer.MaxWarehouses, "warehouses", fmt.Sprintf("warehouses must not be more than %d in quantity", packer.MaxWarehouses))
+	ids := make([]string, 0, len(warehouses))
+	for i, warehouse := range warehouses {
+		key := fmt.Sprintf("warehouses[%d]", i)
+		v.Check(validator.Matches(warehouse.ID, validator.IDRX), key, "id must be 1-64 letters, digits, dashes or underscores")
+		for size, count := range warehouse.Stock {
+			v.Check(size > 0, key, "sizes must be positive numbers")
+			v.Check(count >= 0, key, "stock must not be negative")
+		}
+		ids = append(ids, warehouse.ID)
+	}
+	v.Check(validator.Unique(ids), "warehouses", "warehouse ids must be unique")
+}
+
+func (s *Server) validateBundlesOnValue(v *validator.Validator, bundles []packer.BundlePack) {
+	v.Check(len(bundles) > 0, "bundles", "bundles must be more than 0 in quantity")
+	ids := make([]string, 0, len(bundles))
+	for i, bundle := range bundles {
+		key := fmt.Sprintf("bundles[%d]", i)
+		v.Check(validator.Matches(bundle.ID, validator.IDRX), key, "id must be 1-64 letters, digits, dashes or underscores")
+		v.Check(len(bundle.Contents) > 0, key, "contents must hold at least one sku")
+		for sku, quantity := range bundle.Contents {
+			v.Check(validator.Matches(sku, validator.IDRX), key, "skus must be 1-64 letters, digits, dashes or underscores")
+			v.Check(quantity > 0, key, "quantities must be positive numbers")
+		}
+		ids = append(ids, bundle.ID)
+	}
+	v.Check(validator.Unique(ids), "bundles", "bundle ids must be unique")
+}
+
+func (s *Server) validateDemandOnValue(v *validator.Validator, demand map[string]int) {
+	v.Check(len(demand) > 0, "demand", "demand must hold at least one sku")
+	for sku, quantity := range demand {
+		v.Check(validator.Matches(sku, validator.IDRX), "demand", "skus must be 1-64 letters, digits, dashes or underscores")
+		v.Check(quantity >= 0, "demand", "quantities must not be negative")
+		v.Check(quantity <= packer.MaxItems, "demand", fmt.Sprintf("quantities must not be more than %d", packer.MaxItems))
+	}
+}
+
+func (s *Server) validateFormatOnValue(v *validator.Validator, format string) {
+	v.Check(validator.PermittedValue(format, packer.FormatCSV, packer.FormatJSON, packer.FormatYAML), "format", packer.ErrorUnknownFormat)
 }
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/SkNuwanTissera/gymshark/internal/auth"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/SkNuwanTissera/gymshark/internal/validator"
+)
+
+func (s *Server) getFulfilmentPlanHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Items       int                 `json:"items"`
+		Catalog     string              `json:"catalog"`
+		Constraints *packer.Constraints `json:"constraints"`
+		Warehouses  []packer.Warehouse  `json:"warehouses"`
+	}
+
+	err := s.readJSON(w, r, &input)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	s.validateItemsOnValue(v, input.Items)
+	s.validateWarehousesOnValue(v, input.Warehouses)
+	if input.Catalog == "" {
+		input.Catalog = packer.DefaultCatalogID
+	}
+	opts := []packer.PacketsOption{packer.WithCatalog(input.Catalog)}
+	if input.Constraints != nil {
+		s.validateConstraintsOnValue(v, *input.Constraints)
+		opts = append(opts, packer.WithConstraints(*input.Constraints))
+	}
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if !s.authorize(w, r, auth.ScopePacketsCalculate, input.Catalog) {
+		return
+	}
+
+	plan, err := s.PackerSrvc.GetFulfilmentPlan(r.Context(), input.Items, input.Warehouses, opts...)
+	if err != nil {
+		s.packingErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{
+		"plan": plan,
+	}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
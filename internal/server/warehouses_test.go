@@ -0,0 +1,62 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SkNuwanTissera/gymshark/internal/config"
+	"github.com/SkNuwanTissera/gymshark/internal/packer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFulfilmentPlanHandler(t *testing.T) {
+	newSizerSrvc := packer.NewSizerService(packer.SortedSizes)
+	newPackerSrvc := packer.NewPacketsService(packer.NewCatalogService(newSizerSrvc), packer.NewProfileService())
+	server := NewServer(config.Default(), newSizerSrvc, newPackerSrvc)
+
+	testCases := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "200",
+			body:     `{"items": 750, "warehouses": [{"id": "a", "stock": {"250": 1}}, {"id": "b", "stock": {"1000": 1}}]}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "422 - duplicated warehouses",
+			body:     `{"items": 750, "warehouses": [{"id": "a", "stock": {"250": 1}}, {"id": "a", "stock": {"1000": 1}}]}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 - negative stock",
+			body:     `{"items": 750, "warehouses": [{"id": "a", "stock": {"250": -1}}]}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 - unfulfillable",
+			body:     `{"items": 750, "warehouses": [{"id": "a", "stock": {"250": 1}}]}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "422 - no warehouses",
+			body:     `{"items": 750, "warehouses": []}`,
+			wantCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/warehouses/packets", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			server.getFulfilmentPlanHandler(recorder, req)
+			require.Equal(t, tc.wantCode, recorder.Code)
+		})
+	}
+}
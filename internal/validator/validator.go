@@ -1,5 +1,16 @@
 package validator
 
+import (
+	"math"
+	"regexp"
+)
+
+// IDRX is a regular expression for identifiers such as profile and catalog IDs.
+var IDRX = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// CurrencyRX is a regular expression for ISO 4217 currency codes.
+var CurrencyRX = regexp.MustCompile(`^[A-Z]{3}$`)
+
 // Validator Define a new Validator type which contains a map of validation errors.
 type Validator struct {
 	Errors map[string]string
@@ -36,3 +47,33 @@ func (v *Validator) ValidateSize(ok bool, key, message string) {
 		v.AddError(key, message)
 	}
 }
+
+// PermittedValue returns true if a specific value is in a list of permitted values.
+func PermittedValue[T comparable](value T, permittedValues ...T) bool {
+	for i := range permittedValues {
+		if value == permittedValues[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns true if a string value matches a specific regexp pattern.
+func Matches(value string, rx *regexp.Regexp) bool {
+	return rx.MatchString(value)
+}
+
+// Unique returns true if all values in a slice are unique.
+func Unique[T comparable](values []T) bool {
+	uniqueValues := make(map[T]bool)
+	for _, value := range values {
+		uniqueValues[value] = true
+	}
+	return len(values) == len(uniqueValues)
+}
+
+// Integral returns true if a float value is a whole number, tolerating floating
+// point noise of up to a millionth.
+func Integral(value float64) bool {
+	return math.Abs(value-math.Round(value)) <= 1e-6
+}